@@ -0,0 +1,391 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	cacheKeyFestivals         = "festivals:"
+	cacheKeyBreweries         = "breweries:"
+	cacheKeyFestival          = "festival:"
+	cacheKeyFestivalBreweries = "festival-breweries:"
+	cacheKeyBrewery           = "brewery:"
+	cacheKeyBreweryFestivals  = "brewery-festivals:"
+)
+
+type CacheStats struct {
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+func (s *CacheStats) Hits() int64 {
+	return s.hits.Load()
+}
+
+func (s *CacheStats) Misses() int64 {
+	return s.misses.Load()
+}
+
+var cacheStats = &CacheStats{}
+
+type cachedPage[T any] struct {
+	items []T
+	total int
+}
+
+type cacheEntry struct {
+	value     any
+	expiresAt time.Time
+}
+
+type CachedDatabase struct {
+	db         DatabaseInterface
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	entries    map[string]cacheEntry
+	sweptAt    time.Time
+}
+
+func NewCachedDatabase(db DatabaseInterface, ttl time.Duration) *CachedDatabase {
+	return &CachedDatabase{
+		db:         db,
+		ttl:        ttl,
+		maxEntries: CacheMaxEntries,
+		entries:    make(map[string]cacheEntry),
+		sweptAt:    time.Now(),
+	}
+}
+
+func (c *CachedDatabase) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+func (c *CachedDatabase) set(key string, value any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(c.sweptAt) >= c.ttl {
+		c.sweep(now)
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		c.sweep(now)
+		c.evictOldest()
+	}
+	c.entries[key] = cacheEntry{value: value, expiresAt: now.Add(c.ttl)}
+}
+
+func (c *CachedDatabase) sweep(now time.Time) {
+	for key, entry := range c.entries {
+		if now.After(entry.expiresAt) {
+			delete(c.entries, key)
+		}
+	}
+	c.sweptAt = now
+}
+
+func (c *CachedDatabase) evictOldest() {
+	if len(c.entries) < c.maxEntries {
+		return
+	}
+
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range c.entries {
+		if oldestKey == "" || entry.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, entry.expiresAt
+		}
+	}
+	delete(c.entries, oldestKey)
+}
+
+func (c *CachedDatabase) invalidate(keys ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, key := range keys {
+		if strings.HasSuffix(key, ":") {
+			for existing := range c.entries {
+				if strings.HasPrefix(existing, key) {
+					delete(c.entries, existing)
+				}
+			}
+			continue
+		}
+		delete(c.entries, key)
+	}
+}
+
+func festivalQueryKey(query FestivalQuery, now time.Time) string {
+	values := url.Values{}
+	setIf := func(key, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	setIf("region", query.Region)
+	setIf("city", query.City)
+	setIf("from", query.From)
+	setIf("to", query.To)
+	if query.Upcoming || query.Past {
+		values.Set("upcoming", strconv.FormatBool(query.Upcoming))
+		values.Set("past", strconv.FormatBool(query.Past))
+		values.Set("today", now.UTC().Format(DefaultTimeFormat))
+	}
+	if query.Bounds != (BoundingBox{}) {
+		values.Set("bbox", fmt.Sprintf("%g,%g,%g,%g", query.Bounds.West, query.Bounds.South, query.Bounds.East, query.Bounds.North))
+	}
+	setIf("sort", query.Sort)
+	values.Set("desc", strconv.FormatBool(query.Desc))
+	values.Set("limit", strconv.Itoa(query.Limit))
+	values.Set("offset", strconv.Itoa(query.Offset))
+	return values.Encode()
+}
+
+func breweryQueryKey(query BreweryQuery) string {
+	values := url.Values{}
+	if query.City != "" {
+		values.Set("city", query.City)
+	}
+	if query.Sort != "" {
+		values.Set("sort", query.Sort)
+	}
+	values.Set("desc", strconv.FormatBool(query.Desc))
+	values.Set("limit", strconv.Itoa(query.Limit))
+	values.Set("offset", strconv.Itoa(query.Offset))
+	return values.Encode()
+}
+
+func cachedList[T any](ctx context.Context, c *CachedDatabase, key string, fetch func(context.Context) ([]T, error)) ([]T, error) {
+	if value, ok := c.get(key); ok {
+		cacheStats.hits.Add(1)
+		return append([]T(nil), value.([]T)...), nil
+	}
+
+	cacheStats.misses.Add(1)
+	result, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(key, append([]T(nil), result...))
+	return result, nil
+}
+
+func cachedItem[T any](ctx context.Context, c *CachedDatabase, key string, fetch func(context.Context) (*T, error)) (*T, error) {
+	if value, ok := c.get(key); ok {
+		cacheStats.hits.Add(1)
+		item := value.(T)
+		return &item, nil
+	}
+
+	cacheStats.misses.Add(1)
+	result, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.set(key, *result)
+	return result, nil
+}
+
+func cachedListPage[T any](ctx context.Context, c *CachedDatabase, key string, fetch func(context.Context) ([]T, int, error)) ([]T, int, error) {
+	if value, ok := c.get(key); ok {
+		cacheStats.hits.Add(1)
+		page := value.(cachedPage[T])
+		return append([]T(nil), page.items...), page.total, nil
+	}
+
+	cacheStats.misses.Add(1)
+	items, total, err := fetch(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	c.set(key, cachedPage[T]{items: append([]T(nil), items...), total: total})
+	return items, total, nil
+}
+
+func (c *CachedDatabase) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
+	return c.db.Login(ctx, email, password)
+}
+
+func (c *CachedDatabase) VerifyToken(ctx context.Context, token string) (*User, error) {
+	return c.db.VerifyToken(ctx, token)
+}
+
+func (c *CachedDatabase) RefreshSession(ctx context.Context, refreshToken string) (*LoginResponse, error) {
+	return c.db.RefreshSession(ctx, refreshToken)
+}
+
+func (c *CachedDatabase) Logout(ctx context.Context, token string) error {
+	return c.db.Logout(ctx, token)
+}
+
+func (c *CachedDatabase) GetFestivals(ctx context.Context) ([]Festival, error) {
+	return cachedList(ctx, c, cacheKeyFestivals+"all", c.db.GetFestivals)
+}
+
+func (c *CachedDatabase) ListFestivals(ctx context.Context, query FestivalQuery) ([]Festival, int, error) {
+	return cachedListPage(ctx, c, cacheKeyFestivals+festivalQueryKey(query, time.Now()), func(ctx context.Context) ([]Festival, int, error) {
+		return c.db.ListFestivals(ctx, query)
+	})
+}
+
+func (c *CachedDatabase) GetFestival(ctx context.Context, id int64) (*Festival, error) {
+	return cachedItem(ctx, c, cacheKeyFestival+strconv.FormatInt(id, 10), func(ctx context.Context) (*Festival, error) {
+		return c.db.GetFestival(ctx, id)
+	})
+}
+
+func (c *CachedDatabase) GetBreweries(ctx context.Context) ([]Brewery, error) {
+	return cachedList(ctx, c, cacheKeyBreweries+"all", c.db.GetBreweries)
+}
+
+func (c *CachedDatabase) ListBreweries(ctx context.Context, query BreweryQuery) ([]Brewery, int, error) {
+	return cachedListPage(ctx, c, cacheKeyBreweries+breweryQueryKey(query), func(ctx context.Context) ([]Brewery, int, error) {
+		return c.db.ListBreweries(ctx, query)
+	})
+}
+
+func (c *CachedDatabase) GetBreweriesByFestival(ctx context.Context, festivalID int64) ([]Brewery, error) {
+	return cachedList(ctx, c, cacheKeyFestivalBreweries+strconv.FormatInt(festivalID, 10), func(ctx context.Context) ([]Brewery, error) {
+		return c.db.GetBreweriesByFestival(ctx, festivalID)
+	})
+}
+
+func (c *CachedDatabase) GetBrewery(ctx context.Context, id int64) (*Brewery, error) {
+	return cachedItem(ctx, c, cacheKeyBrewery+strconv.FormatInt(id, 10), func(ctx context.Context) (*Brewery, error) {
+		return c.db.GetBrewery(ctx, id)
+	})
+}
+
+func (c *CachedDatabase) GetFestivalsByBrewery(ctx context.Context, breweryID int64) ([]Festival, error) {
+	return cachedList(ctx, c, cacheKeyBreweryFestivals+strconv.FormatInt(breweryID, 10), func(ctx context.Context) ([]Festival, error) {
+		return c.db.GetFestivalsByBrewery(ctx, breweryID)
+	})
+}
+
+func (c *CachedDatabase) CreateFestival(ctx context.Context, festival *FestivalDB) (*FestivalDB, error) {
+	created, err := c.db.CreateFestival(ctx, festival)
+	if err != nil {
+		return nil, err
+	}
+
+	c.invalidate(cacheKeyFestivals)
+	return created, nil
+}
+
+func (c *CachedDatabase) UpdateFestival(ctx context.Context, id int64, festival *FestivalDB) (*FestivalDB, error) {
+	updated, err := c.db.UpdateFestival(ctx, id, festival)
+	if err != nil {
+		return nil, err
+	}
+
+	c.invalidate(cacheKeyFestivals, cacheKeyFestival+strconv.FormatInt(id, 10), cacheKeyBreweryFestivals)
+	return updated, nil
+}
+
+func (c *CachedDatabase) DeleteFestival(ctx context.Context, id int64) error {
+	if err := c.db.DeleteFestival(ctx, id); err != nil {
+		return err
+	}
+
+	c.invalidate(cacheKeyFestivals, cacheKeyFestival+strconv.FormatInt(id, 10), cacheKeyFestivalBreweries+strconv.FormatInt(id, 10),
+		cacheKeyBreweries, cacheKeyBrewery, cacheKeyBreweryFestivals)
+	return nil
+}
+
+func (c *CachedDatabase) CreateBrewery(ctx context.Context, brewery *BreweryDB) (*BreweryDB, error) {
+	created, err := c.db.CreateBrewery(ctx, brewery)
+	if err != nil {
+		return nil, err
+	}
+
+	c.invalidate(cacheKeyBreweries)
+	return created, nil
+}
+
+func (c *CachedDatabase) UpdateBrewery(ctx context.Context, id int64, brewery *BreweryDB) (*BreweryDB, error) {
+	updated, err := c.db.UpdateBrewery(ctx, id, brewery)
+	if err != nil {
+		return nil, err
+	}
+
+	c.invalidate(cacheKeyBreweries, cacheKeyBrewery+strconv.FormatInt(id, 10), cacheKeyFestivalBreweries)
+	return updated, nil
+}
+
+func (c *CachedDatabase) DeleteBrewery(ctx context.Context, id int64, cascade bool) error {
+	if err := c.db.DeleteBrewery(ctx, id, cascade); err != nil {
+		return err
+	}
+
+	c.invalidate(cacheKeyBreweries, cacheKeyBrewery+strconv.FormatInt(id, 10), cacheKeyBreweryFestivals+strconv.FormatInt(id, 10),
+		cacheKeyFestivalBreweries, cacheKeyFestivals, cacheKeyFestival)
+	return nil
+}
+
+func (c *CachedDatabase) AddBreweriesToFestival(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	if err := c.db.AddBreweriesToFestival(ctx, festivalID, breweryIDs); err != nil {
+		return err
+	}
+
+	c.invalidateLineup(festivalID)
+	return nil
+}
+
+func (c *CachedDatabase) RemoveBreweryFromFestival(ctx context.Context, festivalID, breweryID int64) error {
+	if err := c.db.RemoveBreweryFromFestival(ctx, festivalID, breweryID); err != nil {
+		return err
+	}
+
+	c.invalidateLineup(festivalID)
+	return nil
+}
+
+func (c *CachedDatabase) ReplaceFestivalBreweries(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	if err := c.db.ReplaceFestivalBreweries(ctx, festivalID, breweryIDs); err != nil {
+		return err
+	}
+
+	c.invalidateLineup(festivalID)
+	return nil
+}
+
+func (c *CachedDatabase) GetFestivalOwners(ctx context.Context, festivalID int64) ([]string, error) {
+	return c.db.GetFestivalOwners(ctx, festivalID)
+}
+
+func (c *CachedDatabase) GetFestivalsByOwner(ctx context.Context, userID string) ([]Festival, error) {
+	return c.db.GetFestivalsByOwner(ctx, userID)
+}
+
+func (c *CachedDatabase) AddFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	return c.db.AddFestivalOwners(ctx, festivalID, userIDs)
+}
+
+func (c *CachedDatabase) ReplaceFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	return c.db.ReplaceFestivalOwners(ctx, festivalID, userIDs)
+}
+
+func (c *CachedDatabase) invalidateLineup(festivalID int64) {
+	c.invalidate(cacheKeyFestivals, cacheKeyFestival+strconv.FormatInt(festivalID, 10), cacheKeyFestivalBreweries+strconv.FormatInt(festivalID, 10),
+		cacheKeyBreweries, cacheKeyBrewery, cacheKeyBreweryFestivals)
+}
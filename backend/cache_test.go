@@ -0,0 +1,401 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestCachedDatabase(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("serves repeated festival reads from cache", func(t *testing.T) {
+		calls := 0
+		mockDB := &MockDatabase{
+			getFestivalsFunc: func() ([]Festival, error) {
+				calls++
+				return []Festival{{ID: 1, Name: "Test Festival"}}, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+		hits := cacheStats.Hits()
+
+		cached.GetFestivals(ctx)
+		festivals, err := cached.GetFestivals(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if calls != 1 {
+			t.Errorf("Expected 1 database call, got %d", calls)
+		}
+
+		if len(festivals) != 1 || festivals[0].Name != "Test Festival" {
+			t.Errorf("Expected cached festival, got %v", festivals)
+		}
+
+		if cacheStats.Hits() != hits+1 {
+			t.Errorf("Expected cache hit counter to increase by 1, got %d", cacheStats.Hits()-hits)
+		}
+	})
+
+	t.Run("refetches after TTL expires", func(t *testing.T) {
+		calls := 0
+		mockDB := &MockDatabase{
+			getBreweriesFunc: func() ([]Brewery, error) {
+				calls++
+				return []Brewery{{ID: 1}}, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Millisecond)
+
+		cached.GetBreweries(ctx)
+		time.Sleep(5 * time.Millisecond)
+		cached.GetBreweries(ctx)
+
+		if calls != 2 {
+			t.Errorf("Expected 2 database calls, got %d", calls)
+		}
+	})
+
+	t.Run("caches breweries per festival", func(t *testing.T) {
+		calls := map[int64]int{}
+		mockDB := &MockDatabase{
+			getBreweriesByFestivalFunc: func(festivalID int64) ([]Brewery, error) {
+				calls[festivalID]++
+				return []Brewery{}, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		cached.GetBreweriesByFestival(ctx, 1)
+		cached.GetBreweriesByFestival(ctx, 1)
+		cached.GetBreweriesByFestival(ctx, 2)
+
+		if calls[1] != 1 || calls[2] != 1 {
+			t.Errorf("Expected one call per festival, got %v", calls)
+		}
+	})
+
+	t.Run("does not cache errors", func(t *testing.T) {
+		calls := 0
+		mockDB := &MockDatabase{
+			getFestivalsFunc: func() ([]Festival, error) {
+				calls++
+				return nil, &DatabaseError{Message: "database error"}
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		cached.GetFestivals(ctx)
+		_, err := cached.GetFestivals(ctx)
+
+		if err == nil {
+			t.Error("Expected error, got nil")
+		}
+
+		if calls != 2 {
+			t.Errorf("Expected 2 database calls, got %d", calls)
+		}
+	})
+
+	t.Run("invalidates festivals after successful creation", func(t *testing.T) {
+		calls := 0
+		mockDB := &MockDatabase{
+			getFestivalsFunc: func() ([]Festival, error) {
+				calls++
+				return []Festival{}, nil
+			},
+			createFestivalFunc: func(festival *FestivalDB) (*FestivalDB, error) {
+				festival.ID = 1
+				return festival, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		cached.GetFestivals(ctx)
+		if _, err := cached.CreateFestival(ctx, &FestivalDB{Name: "New Festival"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		cached.GetFestivals(ctx)
+
+		if calls != 2 {
+			t.Errorf("Expected festivals to be refetched after creation, got %d calls", calls)
+		}
+	})
+
+	t.Run("invalidates festival entries after update and delete", func(t *testing.T) {
+		listCalls, detailCalls := 0, 0
+		mockDB := &MockDatabase{
+			getFestivalsFunc: func() ([]Festival, error) {
+				listCalls++
+				return []Festival{}, nil
+			},
+			getFestivalFunc: func(id int64) (*Festival, error) {
+				detailCalls++
+				return &Festival{ID: id}, nil
+			},
+			updateFestivalFunc: func(id int64, festival *FestivalDB) (*FestivalDB, error) {
+				return festival, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		cached.GetFestivals(ctx)
+		cached.GetFestival(ctx, 1)
+		cached.UpdateFestival(ctx, 1, &FestivalDB{Name: "Renamed"})
+		cached.GetFestivals(ctx)
+		cached.GetFestival(ctx, 1)
+		cached.DeleteFestival(ctx, 1)
+		cached.GetFestivals(ctx)
+		cached.GetFestival(ctx, 1)
+
+		if listCalls != 3 || detailCalls != 3 {
+			t.Errorf("Expected 3 list and 3 detail calls, got %d and %d", listCalls, detailCalls)
+		}
+	})
+
+	t.Run("invalidates brewery entries after brewery writes", func(t *testing.T) {
+		listCalls, festivalCalls := 0, 0
+		mockDB := &MockDatabase{
+			getBreweriesFunc: func() ([]Brewery, error) {
+				listCalls++
+				return []Brewery{}, nil
+			},
+			getFestivalsFunc: func() ([]Festival, error) {
+				festivalCalls++
+				return []Festival{}, nil
+			},
+			createBreweryFunc: func(brewery *BreweryDB) (*BreweryDB, error) {
+				return brewery, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		cached.GetBreweries(ctx)
+		cached.GetFestivals(ctx)
+		cached.CreateBrewery(ctx, &BreweryDB{Name: "New Brewery"})
+		cached.GetBreweries(ctx)
+		cached.GetFestivals(ctx)
+		cached.DeleteBrewery(ctx, 1, true)
+		cached.GetBreweries(ctx)
+		cached.GetFestivals(ctx)
+
+		if listCalls != 3 || festivalCalls != 2 {
+			t.Errorf("Expected 3 brewery and 2 festival list calls, got %d and %d", listCalls, festivalCalls)
+		}
+	})
+
+	t.Run("invalidates counts after lineup changes", func(t *testing.T) {
+		festivalCalls, breweryCalls := 0, 0
+		mockDB := &MockDatabase{
+			getFestivalFunc: func(id int64) (*Festival, error) {
+				festivalCalls++
+				return &Festival{ID: id}, nil
+			},
+			getBreweryFunc: func(id int64) (*Brewery, error) {
+				breweryCalls++
+				return &Brewery{ID: id}, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		cached.GetFestival(ctx, 1)
+		cached.GetBrewery(ctx, 2)
+		cached.AddBreweriesToFestival(ctx, 1, []int64{2})
+		cached.GetFestival(ctx, 1)
+		cached.GetBrewery(ctx, 2)
+
+		if festivalCalls != 2 || breweryCalls != 2 {
+			t.Errorf("Expected 2 festival and 2 brewery calls, got %d and %d", festivalCalls, breweryCalls)
+		}
+	})
+
+	t.Run("caches list pages per query until festivals change", func(t *testing.T) {
+		calls := 0
+		mockDB := &MockDatabase{
+			listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+				calls++
+				return []Festival{{ID: 1}}, 5, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		cached.ListFestivals(ctx, FestivalQuery{City: "Lille"})
+		_, total, _ := cached.ListFestivals(ctx, FestivalQuery{City: "Lille"})
+		cached.ListFestivals(ctx, FestivalQuery{City: "Rennes"})
+		cached.CreateFestival(ctx, &FestivalDB{Name: "New"})
+		cached.ListFestivals(ctx, FestivalQuery{City: "Lille"})
+
+		if calls != 3 || total != 5 {
+			t.Errorf("Expected 3 calls and a cached total of 5, got %d and %d", calls, total)
+		}
+	})
+
+	t.Run("keeps cache when creation fails", func(t *testing.T) {
+		calls := 0
+		mockDB := &MockDatabase{
+			getFestivalsFunc: func() ([]Festival, error) {
+				calls++
+				return []Festival{}, nil
+			},
+			createFestivalFunc: func(festival *FestivalDB) (*FestivalDB, error) {
+				return nil, &DatabaseError{Message: "database error"}
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		cached.GetFestivals(ctx)
+		cached.CreateFestival(ctx, &FestivalDB{Name: "New Festival"})
+		cached.GetFestivals(ctx)
+
+		if calls != 1 {
+			t.Errorf("Expected cache to survive failed creation, got %d calls", calls)
+		}
+	})
+
+	t.Run("caches single festival lookups", func(t *testing.T) {
+		calls := 0
+		mockDB := &MockDatabase{
+			getFestivalFunc: func(id int64) (*Festival, error) {
+				calls++
+				return &Festival{ID: id}, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		cached.GetFestival(ctx, 1)
+		festival, err := cached.GetFestival(ctx, 1)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if calls != 1 || festival.ID != 1 {
+			t.Errorf("Expected one database call for festival 1, got %d calls and %+v", calls, festival)
+		}
+	})
+
+	t.Run("passes authentication through", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			verifyTokenFunc: func(token string) (*User, error) {
+				return &User{ID: token}, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+
+		user, err := cached.VerifyToken(ctx, "user-123")
+		if err != nil || user.ID != "user-123" {
+			t.Errorf("Expected user-123, got %v (%v)", user, err)
+		}
+	})
+
+	t.Run("evicts expired entries that are never read again", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+				return []Festival{}, 0, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Millisecond)
+
+		for _, city := range []string{"Paris", "Lyon", "Lille"} {
+			cached.ListFestivals(ctx, FestivalQuery{City: city})
+		}
+		time.Sleep(5 * time.Millisecond)
+		cached.ListFestivals(ctx, FestivalQuery{City: "Nantes"})
+
+		if len(cached.entries) != 1 {
+			t.Errorf("Expected only the fresh entry to remain, got %d entries", len(cached.entries))
+		}
+	})
+
+	t.Run("evicts the oldest entry when full", func(t *testing.T) {
+		calls := map[string]int{}
+		mockDB := &MockDatabase{
+			listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+				calls[query.City]++
+				return []Festival{}, 0, nil
+			},
+		}
+		cached := NewCachedDatabase(mockDB, time.Minute)
+		cached.maxEntries = 2
+
+		for _, city := range []string{"Paris", "Lyon", "Lille", "Lyon", "Paris"} {
+			cached.ListFestivals(ctx, FestivalQuery{City: city})
+			time.Sleep(time.Millisecond)
+		}
+
+		if len(cached.entries) != 2 {
+			t.Errorf("Expected 2 entries, got %d", len(cached.entries))
+		}
+		if calls["Paris"] != 2 || calls["Lyon"] != 1 || calls["Lille"] != 1 {
+			t.Errorf("Expected only the evicted Paris query to be refetched, got %v", calls)
+		}
+	})
+}
+
+func TestFestivalQueryKey(t *testing.T) {
+	today := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tomorrow := today.AddDate(0, 0, 1)
+
+	tests := []struct {
+		name     string
+		a, b     FestivalQuery
+		aAt, bAt time.Time
+		wantSame bool
+	}{
+		{
+			name:     "same query on different days without a time filter",
+			a:        FestivalQuery{Region: "Bretagne", Limit: 10},
+			b:        FestivalQuery{Region: "Bretagne", Limit: 10},
+			aAt:      today,
+			bAt:      tomorrow,
+			wantSame: true,
+		},
+		{
+			name: "upcoming query on different days",
+			a:    FestivalQuery{Upcoming: true},
+			b:    FestivalQuery{Upcoming: true},
+			aAt:  today,
+			bAt:  tomorrow,
+		},
+		{
+			name: "past query on different days",
+			a:    FestivalQuery{Past: true},
+			b:    FestivalQuery{Past: true},
+			aAt:  today,
+			bAt:  tomorrow,
+		},
+		{
+			name: "upcoming versus past on the same day",
+			a:    FestivalQuery{Upcoming: true},
+			b:    FestivalQuery{Past: true},
+			aAt:  today,
+			bAt:  today,
+		},
+		{
+			name: "different bounding boxes",
+			a:    FestivalQuery{Bounds: BoundingBox{South: 47, West: -5, North: 49, East: -1}},
+			b:    FestivalQuery{Bounds: BoundingBox{South: 47, West: -5, North: 49, East: 0}},
+			aAt:  today,
+			bAt:  today,
+		},
+		{
+			name: "region value that looks like another parameter",
+			a:    FestivalQuery{Region: "Bretagne&city=Rennes"},
+			b:    FestivalQuery{Region: "Bretagne", City: "Rennes"},
+			aAt:  today,
+			bAt:  today,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := festivalQueryKey(tt.a, tt.aAt)
+			b := festivalQueryKey(tt.b, tt.bAt)
+
+			if (a == b) != tt.wantSame {
+				t.Errorf("Expected same key %v, got %q and %q", tt.wantSame, a, b)
+			}
+		})
+	}
+}
@@ -1,17 +1,134 @@
 package main
 
-import "os"
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 func getConfig() Config {
 	port := os.Getenv("PORT")
 	allowedOrigins := os.Getenv("ALLOWED_ORIGINS")
 	supabaseURL := os.Getenv("SUPABASE_URL")
 	supabaseKey := os.Getenv("SUPABASE_KEY")
+	jwtSecret := os.Getenv("SUPABASE_JWT_SECRET")
+	jwksSource := os.Getenv("SUPABASE_JWKS")
+
+	databaseDriver := os.Getenv("DATABASE_DRIVER")
+	if databaseDriver == "" {
+		databaseDriver = DatabaseDriverSupabase
+	}
+
+	sqlitePath := os.Getenv("SQLITE_PATH")
+	if sqlitePath == "" {
+		sqlitePath = DefaultSQLitePath
+	}
+
+	seedPath := os.Getenv("SEED_PATH")
+	if seedPath == "" {
+		seedPath = DefaultSeedPath
+	}
+
+	cacheTTL := DefaultCacheTTL
+	if value := os.Getenv("CACHE_TTL"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			log.Printf("Invalid CACHE_TTL %q, using default %v: %v", value, DefaultCacheTTL, err)
+		} else {
+			cacheTTL = parsed
+		}
+	}
+
+	requestTimeout := DefaultRequestTimeout
+	if value := os.Getenv("REQUEST_TIMEOUT"); value != "" {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			log.Printf("Invalid REQUEST_TIMEOUT %q, using default %v: %v", value, DefaultRequestTimeout, err)
+		} else {
+			requestTimeout = parsed
+		}
+	}
+
+	frontendURL := strings.TrimSuffix(os.Getenv("FRONTEND_URL"), "/")
+	if frontendURL == "" {
+		frontendURL = DefaultFrontendURL
+	}
+
+	jwtAudience := os.Getenv("JWT_AUDIENCE")
+	if jwtAudience == "" {
+		jwtAudience = DefaultJWTAudience
+	}
+
+	jwtIssuer := os.Getenv("JWT_ISSUER")
+	if jwtIssuer == "" && supabaseURL != "" {
+		jwtIssuer = strings.TrimSuffix(supabaseURL, "/") + "/auth/v1"
+	}
+
+	jwtRemoteFallback := false
+	if value := os.Getenv("JWT_REMOTE_FALLBACK"); value != "" {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			log.Printf("Invalid JWT_REMOTE_FALLBACK %q, using default false: %v", value, err)
+		} else {
+			jwtRemoteFallback = parsed
+		}
+	}
+
+	rolesSource := os.Getenv("USER_ROLES_SOURCE")
+	switch rolesSource {
+	case "":
+		rolesSource = RolesSourceAppMetadata
+	case RolesSourceAppMetadata, RolesSourceTable:
+	default:
+		log.Printf("Invalid USER_ROLES_SOURCE %q, using default %s", rolesSource, RolesSourceAppMetadata)
+		rolesSource = RolesSourceAppMetadata
+	}
 
 	return Config{
-		Port:           port,
-		AllowedOrigins: allowedOrigins,
-		SupabaseURL:    supabaseURL,
-		SupabaseKey:    supabaseKey,
+		Port:              port,
+		AllowedOrigins:    allowedOrigins,
+		SupabaseURL:       supabaseURL,
+		SupabaseKey:       supabaseKey,
+		DatabaseDriver:    databaseDriver,
+		SQLitePath:        sqlitePath,
+		SeedPath:          seedPath,
+		CacheTTL:          cacheTTL,
+		RequestTimeout:    requestTimeout,
+		FrontendURL:       frontendURL,
+		JWTSecret:         jwtSecret,
+		JWKSSource:        jwksSource,
+		JWTAudience:       jwtAudience,
+		JWTIssuer:         jwtIssuer,
+		JWTRemoteFallback: jwtRemoteFallback,
+		RolesSource:       rolesSource,
+	}
+}
+
+func openDatabase(config Config) (DatabaseInterface, error) {
+	switch config.DatabaseDriver {
+	case DatabaseDriverSupabase:
+		db, err := NewDatabase(config.SupabaseURL, config.SupabaseKey)
+		if err != nil {
+			return nil, err
+		}
+		if db.verifier, err = NewJWTVerifier(config.JWTSecret, config.JWKSSource, config.JWTAudience, config.JWTIssuer); err != nil {
+			return nil, err
+		}
+		db.remoteFallback = config.JWTRemoteFallback
+		db.rolesSource = config.RolesSource
+		return db, nil
+	case DatabaseDriverSQLite:
+		return NewSQLiteDatabase(config.SQLitePath)
+	case DatabaseDriverMemory:
+		seed, err := LoadSeed(config.SeedPath)
+		if err != nil {
+			return nil, err
+		}
+		return NewMemoryDatabase(seed)
+	default:
+		return nil, fmt.Errorf("unknown DATABASE_DRIVER %q", config.DatabaseDriver)
 	}
 }
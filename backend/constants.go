@@ -1,29 +1,149 @@
 package main
 
+import "time"
+
 const (
 	HeaderContentType     = "Content-Type"
 	HeaderCORSOrigin      = "Access-Control-Allow-Origin"
 	HeaderCORSMethods     = "Access-Control-Allow-Methods"
 	HeaderCORSHeaders     = "Access-Control-Allow-Headers"
 	HeaderOrigin          = "Origin"
+	HeaderCORSExpose      = "Access-Control-Expose-Headers"
+	HeaderTotalCount      = "X-Total-Count"
+	HeaderDeprecation     = "Deprecation"
+	HeaderLink            = "Link"
+	HeaderWWWAuthenticate = "WWW-Authenticate"
 	DefaultTimeFormat     = "2006-01-02"
 	DefaultAllowedOrigins = "*"
 
-	ContentTypeJSON = "application/json"
+	ContentTypeJSON    = "application/json"
+	ContentTypeGeoJSON = "application/geo+json"
+	ContentTypeICal    = "text/calendar; charset=utf-8"
+	ContentTypeAtom    = "application/atom+xml; charset=utf-8"
+	ContentTypeRSS     = "application/rss+xml; charset=utf-8"
+	ContentTypeProblem = "application/problem+json"
 
-	CORSMethods = "GET, POST, OPTIONS"
+	CORSMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
 	CORSHeaders = "Content-Type, Authorization"
 
+	AuthChallenge = `Bearer realm="beer-festival"`
+
 	APIBasePath            = "/api/v1"
+	LegacyAPIBasePath      = "/api"
 	HealthPath             = "/health"
-	FestivalsPath          = "/api/festivals"
-	CreateFestivalPath     = "/api/festivals/create"
-	LoginPath              = "/api/auth/login"
-	VerifyPath             = "/api/auth/verify"
-	FestivalsBreweriesPath = "/api/festivals/"
-	BreweriesPath = "/api/breweries"
+	FestivalsPath          = "/festivals"
+	CreateFestivalPath     = "/festivals/create"
+	FestivalPath           = "/festivals/{id}"
+	FestivalsBreweriesPath = "/festivals/{id}/breweries"
+	FestivalBreweryPath    = "/festivals/{id}/breweries/{breweryId}"
+	FestivalOwnersPath     = "/festivals/{id}/owners"
+	MyFestivalsPath        = "/me/festivals"
+	NearbyFestivalsPath    = "/festivals/nearby"
+	FestivalMapPath        = "/festivals/map"
+	FestivalsGeoJSONPath   = "/festivals.geojson"
+	FestivalsICalPath      = "/festivals.ics"
+	BreweriesPath          = "/breweries"
+	BreweryPath            = "/breweries/{id}"
+	FeedPath               = "/feeds/{feed}"
+	SearchPath             = "/search"
+	SuggestPath            = "/suggest"
+	LoginPath              = "/auth/login"
+	RefreshPath            = "/auth/refresh"
+	LogoutPath             = "/auth/logout"
+	VerifyPath             = "/auth/verify"
+	OpenAPIPath            = "/openapi.json"
 
 	AppVersion = "1.0.0"
 
+	DatabaseDriverSupabase = "supabase"
+	DatabaseDriverSQLite   = "sqlite"
+	DatabaseDriverMemory   = "memory"
+	DefaultSQLitePath      = "beer-festival.db"
+	DefaultSeedPath        = "seed.json"
+	SessionDuration        = 24 * time.Hour
+	RefreshTokenDuration   = 30 * 24 * time.Hour
+	DefaultCacheTTL        = time.Minute
+	CacheMaxEntries        = 1000
+	DefaultRequestTimeout  = 10 * time.Second
+	SupabaseClientTimeout  = 15 * time.Second
+	MaxPageSize            = 100
+	EarthRadiusKm          = 6371.0
+	DefaultNearbyRadiusKm  = 50.0
+	MaxNearbyRadiusKm      = 1000.0
+	MapTileSize            = 256
+	ClusterCellPixels      = 64
+	ClusterMaxZoom         = 14
+	MaxMapZoom             = 22
+	ICalDateFormat         = "20060102"
+	ICalTimestampFormat    = "20060102T150405Z"
+	ICalLineLimit          = 75
+	ICalProductID          = "-//beer-festival//festivals//FR"
+	ICalCalendarName       = "Festivals de bière"
+	ICalUIDDomain          = "beer-festival"
+	DefaultFrontendURL     = "http://localhost:5173"
+	FeedSize               = 20
+	FeedTitleNew           = "Nouveaux festivals de bière"
+	FeedTitleUpcoming      = "Prochains festivals de bière"
+	FeedAuthor             = "Beer Festival"
+	DefaultJWTAudience     = "authenticated"
+	JWTLeeway              = 30 * time.Second
+	JWKSRefreshInterval    = 5 * time.Minute
+	JWKSRetryInterval      = 10 * time.Second
+	UserRolesCacheTTL      = 30 * time.Second
+
+	SearchTypeFestival      = "festival"
+	SearchTypeBrewery       = "brewery"
+	SearchIndexMaxAge       = time.Minute
+	SearchDefaultLimit      = 20
+	SearchMinQueryLength    = 2
+	SearchSnippetRadius     = 40
+	SearchWeightName        = 3.0
+	SearchWeightPlace       = 2.0
+	SearchWeightDescription = 1.0
+	SearchPrefixFactor      = 0.5
+	SuggestCategoryCity     = "city"
+	SuggestCategoryRegion   = "region"
+	SuggestCategoryBrewery  = "brewery"
+	SuggestDefaultLimit     = 5
+
+	RoleAdmin                    = "admin"
+	RoleOrganizer                = "organizer"
+	RoleContributor              = "contributor"
+	RoleViewer                   = "viewer"
+	PermissionFestivalsWrite     = "festivals:write"
+	PermissionFestivalsDelete    = "festivals:delete"
+	PermissionFestivalsManageAny = "festivals:manage_any"
+	PermissionOwnersWrite        = "owners:write"
+	PermissionLineupsWrite       = "lineups:write"
+	PermissionBreweriesWrite     = "breweries:write"
+	PermissionBreweriesDelete    = "breweries:delete"
+	RolesSourceAppMetadata       = "app_metadata"
+	RolesSourceTable             = "table"
+
 	DefaultErrorMessage = "Internal server error"
+	TimeoutErrorMessage = "Request timed out"
+
+	ProblemTypeDefault          = "about:blank"
+	ErrorCodeInvalidRequest     = "invalid_request"
+	ErrorCodeValidation         = "validation_failed"
+	ErrorCodeInvalidBody        = "invalid_body"
+	ErrorCodeUnauthorized       = "unauthorized"
+	ErrorCodeInvalidToken       = "invalid_token"
+	ErrorCodeInvalidCredentials = "invalid_credentials"
+	ErrorCodeForbidden          = "forbidden"
+	ErrorCodeNotFestivalOwner   = "not_festival_owner"
+	ErrorCodeNotFound           = "not_found"
+	ErrorCodeFestivalNotFound   = "festival_not_found"
+	ErrorCodeBreweryNotFound    = "brewery_not_found"
+	ErrorCodeFeedNotFound       = "feed_not_found"
+	ErrorCodeNotInLineup        = "not_in_lineup"
+	ErrorCodeAlreadyInLineup    = "already_in_lineup"
+	ErrorCodeBreweryInUse       = "brewery_in_use"
+	ErrorCodeMethodNotAllowed   = "method_not_allowed"
+	ErrorCodeTimeout            = "timeout"
+	ErrorCodeInternal           = "internal_error"
+	FieldCodeRequired           = "required"
+	FieldCodeInvalid            = "invalid"
+	FieldCodeOutOfRange         = "out_of_range"
+	FieldCodeDuplicate          = "duplicate"
 )
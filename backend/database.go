@@ -1,17 +1,43 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
 
-	"github.com/supabase-community/supabase-go"
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("conflict")
 )
 
 type Database struct {
-	client *supabase.Client
-	url    string
-	key    string
+	httpClient     *http.Client
+	url            string
+	key            string
+	verifier       *JWTVerifier
+	remoteFallback bool
+	rolesSource    string
+
+	rolesMu    sync.Mutex
+	rolesCache map[string]cachedRoles
+}
+
+type cachedRoles struct {
+	roles     []string
+	expiresAt time.Time
 }
 
 func NewDatabase(supabaseURL, supabaseKey string) (*Database, error) {
@@ -19,16 +45,142 @@ func NewDatabase(supabaseURL, supabaseKey string) (*Database, error) {
 		return nil, fmt.Errorf("SUPABASE_URL and SUPABASE_KEY environment variables are required")
 	}
 
-	client, err := supabase.NewClient(supabaseURL, supabaseKey, nil)
+	return &Database{
+		httpClient: &http.Client{Timeout: SupabaseClientTimeout},
+		url:        strings.TrimSuffix(supabaseURL, "/"),
+		key:        supabaseKey,
+		rolesCache: map[string]cachedRoles{},
+	}, nil
+}
+
+const (
+	postgresUniqueViolation     = "23505"
+	postgresForeignKeyViolation = "23503"
+)
+
+type appMetadata struct {
+	Roles []string `json:"roles"`
+}
+
+type supabaseUser struct {
+	ID          string      `json:"id"`
+	Email       string      `json:"email"`
+	AppMetadata appMetadata `json:"app_metadata"`
+}
+
+func (u supabaseUser) user() User {
+	return User{ID: u.ID, Email: u.Email, Roles: normalizeRoles(u.AppMetadata.Roles)}
+}
+
+type supabaseError struct {
+	Code             string `json:"code"`
+	Message          string `json:"message"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	Msg              string `json:"msg"`
+}
+
+func (db *Database) do(ctx context.Context, method, endpoint string, query url.Values, body any, headers map[string]string, out any) error {
+	_, err := db.send(ctx, method, endpoint, query, body, headers, out)
+	return err
+}
+
+func (db *Database) send(ctx context.Context, method, endpoint string, query url.Values, body any, headers map[string]string, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request body: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	target := db.url + endpoint
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Supabase client: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
-	return &Database{
-		client: client,
-		url:    supabaseURL,
-		key:    supabaseKey,
-	}, nil
+	req.Header.Set("apikey", db.key)
+	req.Header.Set("Authorization", "Bearer "+db.key)
+	req.Header.Set(HeaderContentType, ContentTypeJSON)
+	req.Header.Set("Accept", ContentTypeJSON)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := db.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr supabaseError
+		json.Unmarshal(respBody, &apiErr)
+		message := apiErr.Message
+		for _, candidate := range []string{apiErr.ErrorDescription, apiErr.Msg, apiErr.Error} {
+			if message == "" {
+				message = candidate
+			}
+		}
+		switch apiErr.Code {
+		case postgresUniqueViolation:
+			return nil, fmt.Errorf("supabase returned status %d: (%s) %s: %w", resp.StatusCode, apiErr.Code, message, ErrConflict)
+		case postgresForeignKeyViolation:
+			return nil, fmt.Errorf("supabase returned status %d: (%s) %s: %w", resp.StatusCode, apiErr.Code, message, ErrNotFound)
+		}
+		return nil, fmt.Errorf("supabase returned status %d: (%s) %s", resp.StatusCode, apiErr.Code, message)
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return resp.Header, nil
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return resp.Header, nil
+}
+
+func (db *Database) rest(ctx context.Context, method, table string, query url.Values, body any, out any) error {
+	headers := map[string]string{}
+	if method != http.MethodGet {
+		headers["Prefer"] = "return=representation"
+	}
+	return db.do(ctx, method, "/rest/v1/"+table, query, body, headers, out)
+}
+
+func (db *Database) restCount(ctx context.Context, table string, query url.Values, out any) (int, error) {
+	header, err := db.send(ctx, http.MethodGet, "/rest/v1/"+table, query, nil, map[string]string{"Prefer": "count=exact"}, out)
+	if err != nil {
+		return 0, err
+	}
+
+	contentRange := header.Get("Content-Range")
+	total, err := strconv.Atoi(contentRange[strings.LastIndex(contentRange, "/")+1:])
+	if err != nil {
+		return 0, fmt.Errorf("invalid Content-Range header %q", contentRange)
+	}
+
+	return total, nil
+}
+
+func (db *Database) rpc(ctx context.Context, name string, params map[string]any, out any) error {
+	if params == nil {
+		params = map[string]any{}
+	}
+	return db.do(ctx, http.MethodPost, "/rest/v1/rpc/"+name, nil, params, nil, out)
 }
 
 type BreweryCount struct {
@@ -41,126 +193,264 @@ type FestivalCount struct {
 	Count int64 `json:"count"`
 }
 
-func (db *Database) GetFestivals() ([]Festival, error) {
+func (db *Database) GetFestivals(ctx context.Context) ([]Festival, error) {
 	var festivalsDB []FestivalDB
-	_, err := db.client.From("festivals").Select("*", "", false).ExecuteTo(&festivalsDB)
+	err := db.rest(ctx, http.MethodGet, "festivals", url.Values{"select": {"*"}}, nil, &festivalsDB)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch festivals: %w", err)
 	}
 
-	breweryCounts, err := db.getBreweryCounts()
+	breweryCounts, err := db.getBreweryCounts(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch brewery counts: %w", err)
 	}
 
 	festivals := make([]Festival, len(festivalsDB))
 	for i, fdb := range festivalsDB {
-		startDate, _ := ConvertTime(fdb.StartDate)
-		endDate, _ := ConvertTime(fdb.EndDate)
-
-		festivals[i] = Festival{
-			ID:          fdb.ID,
-			Name:        fdb.Name,
-			Description: fdb.Description,
-			StartDate:   startDate,
-			EndDate:     endDate,
-			City:        fdb.City,
-			Region:      fdb.Region,
-			Location: Location{
-				Latitude:  fdb.Latitude,
-				Longitude: fdb.Longitude,
-			},
-			Image:        fdb.Image,
-			Website:      fdb.Website,
-			BreweryCount: breweryCounts[fdb.ID],
-		}
+		festivals[i] = festivalFromDB(fdb, breweryCounts[fdb.ID])
 	}
 
 	return festivals, nil
 }
 
-func (db *Database) getBreweryCounts() (map[int64]int, error) {
-	var counts []BreweryCount
-	rpcResult := db.client.Rpc("get_festival_brewery_counts", "", nil)
+func (db *Database) ListFestivals(ctx context.Context, query FestivalQuery) ([]Festival, int, error) {
+	values := url.Values{"select": {"*"}}
+	if query.Region != "" {
+		values.Add("region", "eq."+query.Region)
+	}
+	if query.City != "" {
+		values.Add("city", "eq."+query.City)
+	}
+	if query.From != "" {
+		values.Add("end_date", "gte."+query.From)
+	}
+	if query.To != "" {
+		values.Add("start_date", "lte."+query.To)
+	}
+
+	today := time.Now().UTC().Format(DefaultTimeFormat)
+	if query.Upcoming {
+		values.Add("end_date", "gte."+today)
+	}
+	if query.Past {
+		values.Add("end_date", "lt."+today)
+	}
+	if query.Bounds != (BoundingBox{}) {
+		values.Add("latitude", "gte."+strconv.FormatFloat(query.Bounds.South, 'f', -1, 64))
+		values.Add("latitude", "lte."+strconv.FormatFloat(query.Bounds.North, 'f', -1, 64))
+		values.Add("longitude", "gte."+strconv.FormatFloat(query.Bounds.West, 'f', -1, 64))
+		values.Add("longitude", "lte."+strconv.FormatFloat(query.Bounds.East, 'f', -1, 64))
+	}
 
-	err := json.Unmarshal([]byte(rpcResult), &counts)
+	values.Set("order", postgrestOrder(query.Sort, query.Desc))
+	setPostgrestPage(values, query.Limit, query.Offset)
 
-	if err == nil && len(counts) > 0 {
-		result := make(map[int64]int)
-		for _, c := range counts {
-			result[c.FestivalID] = int(c.Count)
-		}
-		return result, nil
+	var festivalsDB []FestivalDB
+	total, err := db.restCount(ctx, "festivals", values, &festivalsDB)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch festivals: %w", err)
 	}
-	return nil, err
+
+	breweryCounts, err := db.getBreweryCounts(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch brewery counts: %w", err)
+	}
+
+	festivals := make([]Festival, len(festivalsDB))
+	for i, fdb := range festivalsDB {
+		festivals[i] = festivalFromDB(fdb, breweryCounts[fdb.ID])
+	}
+
+	return festivals, total, nil
 }
 
-func (db *Database) getFestivalCounts() (map[int64]int, error) {
-	var counts []FestivalCount
-	rpcResult := db.client.Rpc("get_brewery_festival_counts", "", nil)
+func postgrestOrder(column string, desc bool) string {
+	if column == "" {
+		column = "id"
+	}
 
-	err := json.Unmarshal([]byte(rpcResult), &counts)
+	direction := ".asc"
+	if desc {
+		direction = ".desc"
+	}
 
-	if err == nil && len(counts) > 0 {
-		result := make(map[int64]int)
-		for _, c := range counts {
-			result[c.BreweryID] = int(c.Count)
-		}
-		return result, nil
+	if column == "id" {
+		return column + direction
+	}
+	return column + direction + ",id.asc"
+}
+
+func setPostgrestPage(values url.Values, limit, offset int) {
+	if limit > 0 {
+		values.Set("limit", strconv.Itoa(limit))
+	}
+	if offset > 0 {
+		values.Set("offset", strconv.Itoa(offset))
 	}
-	return nil, err
 }
-func (db *Database) Login(email, password string) (*LoginResponse, error) {
-	resp, err := db.client.Auth.SignInWithEmailPassword(email, password)
+
+func (db *Database) GetFestival(ctx context.Context, id int64) (*Festival, error) {
+	var festivalsDB []FestivalDB
+	err := db.rest(ctx, http.MethodGet, "festivals", url.Values{
+		"select": {"*"},
+		"id":     {"eq." + strconv.FormatInt(id, 10)},
+	}, nil, &festivalsDB)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch festival %d: %w", id, err)
+	}
+
+	if len(festivalsDB) == 0 {
+		return nil, fmt.Errorf("festival %d: %w", id, ErrNotFound)
+	}
+
+	breweryCounts, err := db.getBreweryCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch brewery counts: %w", err)
+	}
+
+	festival := festivalFromDB(festivalsDB[0], breweryCounts[id])
+	return &festival, nil
+}
+
+func (db *Database) getBreweryCounts(ctx context.Context) (map[int64]int, error) {
+	var counts []BreweryCount
+	if err := db.rpc(ctx, "get_festival_brewery_counts", nil, &counts); err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]int)
+	for _, c := range counts {
+		result[c.FestivalID] = int(c.Count)
+	}
+	return result, nil
+}
+
+func (db *Database) getFestivalCounts(ctx context.Context) (map[int64]int, error) {
+	var counts []FestivalCount
+	if err := db.rpc(ctx, "get_brewery_festival_counts", nil, &counts); err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]int)
+	for _, c := range counts {
+		result[c.BreweryID] = int(c.Count)
+	}
+	return result, nil
+}
+
+func (db *Database) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
+	resp, err := db.grantToken(ctx, "password", LoginRequest{Email: email, Password: password})
 	if err != nil {
 		return nil, fmt.Errorf("authentication failed: %w", err)
 	}
+	return resp, nil
+}
+
+func (db *Database) RefreshSession(ctx context.Context, refreshToken string) (*LoginResponse, error) {
+	resp, err := db.grantToken(ctx, "refresh_token", map[string]string{"refresh_token": refreshToken})
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+	return resp, nil
+}
 
-	return &LoginResponse{
+func (db *Database) Logout(ctx context.Context, token string) error {
+	err := db.do(ctx, http.MethodPost, "/auth/v1/logout", url.Values{"scope": {"local"}}, nil,
+		map[string]string{"Authorization": "Bearer " + token}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	return nil
+}
+
+func (db *Database) grantToken(ctx context.Context, grantType string, body any) (*LoginResponse, error) {
+	var resp struct {
+		AccessToken  string       `json:"access_token"`
+		RefreshToken string       `json:"refresh_token"`
+		User         supabaseUser `json:"user"`
+	}
+
+	err := db.do(ctx, http.MethodPost, "/auth/v1/token", url.Values{"grant_type": {grantType}}, body, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	session := &LoginResponse{
 		AccessToken:  resp.AccessToken,
 		RefreshToken: resp.RefreshToken,
-		User: User{
-			ID:    resp.User.ID.String(),
-			Email: resp.User.Email,
-		},
-	}, nil
+		User:         resp.User.user(),
+	}
+	return session, db.loadRoles(ctx, &session.User)
 }
 
-func (db *Database) VerifyToken(token string) (*User, error) {
-	req, err := http.NewRequest("GET", db.url+"/auth/v1/user", nil)
+func (db *Database) VerifyToken(ctx context.Context, token string) (*User, error) {
+	if db.verifier != nil {
+		user, err := db.verifier.Verify(ctx, token)
+		if err == nil {
+			return user, db.loadCachedRoles(ctx, user)
+		}
+		if !db.remoteFallback {
+			return nil, err
+		}
+		log.Printf("Local token verification failed, falling back to Supabase Auth: %v", err)
+	}
+
+	var userResp supabaseUser
+	err := db.do(ctx, http.MethodGet, "/auth/v1/user", nil, nil,
+		map[string]string{"Authorization": "Bearer " + token}, &userResp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to verify token: %w", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("apikey", db.key)
+	user := userResp.user()
+	return &user, db.loadCachedRoles(ctx, &user)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+func (db *Database) loadRoles(ctx context.Context, user *User) error {
+	if db.rolesSource != RolesSourceTable {
+		return nil
+	}
+
+	var rows []struct {
+		Role string `json:"role"`
+	}
+	err := db.rest(ctx, http.MethodGet, "user_roles", url.Values{
+		"select":  {"role"},
+		"user_id": {"eq." + user.ID},
+	}, nil, &rows)
 	if err != nil {
-		return nil, fmt.Errorf("failed to verify token: %w", err)
+		return fmt.Errorf("failed to load roles of user %s: %w", user.ID, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("invalid token: status %d", resp.StatusCode)
+	roles := make([]string, len(rows))
+	for i, row := range rows {
+		roles[i] = row.Role
 	}
+	user.Roles = normalizeRoles(roles)
 
-	var userResp struct {
-		ID    string `json:"id"`
-		Email string `json:"email"`
+	db.rolesMu.Lock()
+	db.rolesCache[user.ID] = cachedRoles{roles: user.Roles, expiresAt: time.Now().Add(UserRolesCacheTTL)}
+	db.rolesMu.Unlock()
+	return nil
+}
+
+func (db *Database) loadCachedRoles(ctx context.Context, user *User) error {
+	if db.rolesSource != RolesSourceTable {
+		return nil
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&userResp); err != nil {
-		return nil, fmt.Errorf("failed to decode user response: %w", err)
+
+	db.rolesMu.Lock()
+	cached, ok := db.rolesCache[user.ID]
+	db.rolesMu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		user.Roles = slices.Clone(cached.roles)
+		return nil
 	}
 
-	return &User{
-		ID:    userResp.ID,
-		Email: userResp.Email,
-	}, nil
+	return db.loadRoles(ctx, user)
 }
 
-func (db *Database) GetBreweriesByFestival(festivalID string) ([]Brewery, error) {
+func (db *Database) GetBreweriesByFestival(ctx context.Context, festivalID int64) ([]Brewery, error) {
 	type FestivalBreweryWithBrewery struct {
 		BreweryID int64     `json:"brewery_id"`
 		Breweries BreweryDB `json:"breweries"`
@@ -168,10 +458,10 @@ func (db *Database) GetBreweriesByFestival(festivalID string) ([]Brewery, error)
 
 	var festivalBreweries []FestivalBreweryWithBrewery
 
-	_, err := db.client.From("festivals_breweries").
-		Select("brewery_id, breweries(*)", "", false).
-		Eq("festival_id", festivalID).
-		ExecuteTo(&festivalBreweries)
+	err := db.rest(ctx, http.MethodGet, "festivals_breweries", url.Values{
+		"select":      {"brewery_id,breweries(*)"},
+		"festival_id": {"eq." + strconv.FormatInt(festivalID, 10)},
+	}, nil, &festivalBreweries)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch breweries: %w", err)
@@ -183,52 +473,124 @@ func (db *Database) GetBreweriesByFestival(festivalID string) ([]Brewery, error)
 
 	breweries := make([]Brewery, len(festivalBreweries))
 	for index, brewery := range festivalBreweries {
-		breweries[index] = Brewery{
-			ID:          brewery.Breweries.ID,
-			Name:        brewery.Breweries.Name,
-			Description: brewery.Breweries.Description,
-			City:        brewery.Breweries.City,
-			Website:     brewery.Breweries.Website,
-			Logo:        brewery.Breweries.Logo,
-		}
+		breweries[index] = breweryFromDB(brewery.Breweries, 0)
 	}
 
 	return breweries, nil
 }
 
-func (db *Database) GetBreweries() ([]Brewery, error) {
+func (db *Database) GetFestivalsByBrewery(ctx context.Context, breweryID int64) ([]Festival, error) {
+	type FestivalBreweryWithFestival struct {
+		FestivalID int64      `json:"festival_id"`
+		Festivals  FestivalDB `json:"festivals"`
+	}
+
+	var breweryFestivals []FestivalBreweryWithFestival
+
+	err := db.rest(ctx, http.MethodGet, "festivals_breweries", url.Values{
+		"select":     {"festival_id,festivals(*)"},
+		"brewery_id": {"eq." + strconv.FormatInt(breweryID, 10)},
+	}, nil, &breweryFestivals)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch festivals: %w", err)
+	}
+
+	if len(breweryFestivals) == 0 {
+		return []Festival{}, nil
+	}
+
+	breweryCounts, err := db.getBreweryCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch brewery counts: %w", err)
+	}
+
+	festivals := make([]Festival, len(breweryFestivals))
+	for index, festival := range breweryFestivals {
+		festivals[index] = festivalFromDB(festival.Festivals, breweryCounts[festival.FestivalID])
+	}
+
+	return festivals, nil
+}
+
+func (db *Database) GetBrewery(ctx context.Context, id int64) (*Brewery, error) {
 	var breweriesDb []BreweryDB
-	_, err := db.client.From("breweries").Select("*", "", false).ExecuteTo(&breweriesDb)
+	err := db.rest(ctx, http.MethodGet, "breweries", url.Values{
+		"select": {"*"},
+		"id":     {"eq." + strconv.FormatInt(id, 10)},
+	}, nil, &breweriesDb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch brewery %d: %w", id, err)
+	}
+
+	if len(breweriesDb) == 0 {
+		return nil, fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+	}
+
+	festivalCounts, err := db.getFestivalCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch brewery counts: %w", err)
+	}
+
+	brewery := breweryFromDB(breweriesDb[0], festivalCounts[id])
+	return &brewery, nil
+}
+
+func (db *Database) GetBreweries(ctx context.Context) ([]Brewery, error) {
+	var breweriesDb []BreweryDB
+	err := db.rest(ctx, http.MethodGet, "breweries", url.Values{"select": {"*"}}, nil, &breweriesDb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch breweries: %w", err)
 	}
 
-	festivalCounts, err := db.getFestivalCounts()
+	festivalCounts, err := db.getFestivalCounts(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch brewery counts: %w", err)
 	}
 
 	breweries := make([]Brewery, len(breweriesDb))
 	for i, brewery := range breweriesDb {
-		breweries[i] = Brewery{
-			ID:          brewery.ID,
-			Name:        brewery.Name,
-			Description: brewery.Description,
-			City:        brewery.City,
-			Logo:        brewery.Logo,
-			Website:      brewery.Website,
-			FestivalCount: festivalCounts[brewery.ID],
-		}
+		breweries[i] = breweryFromDB(brewery, festivalCounts[brewery.ID])
 	}
 
 	return breweries, nil
 }
 
-func (db *Database) CreateFestival(festival *FestivalDB) (*FestivalDB, error) {
+func (db *Database) ListBreweries(ctx context.Context, query BreweryQuery) ([]Brewery, int, error) {
+	values := url.Values{"select": {"*"}}
+	if query.City != "" {
+		values.Add("city", "eq."+query.City)
+	}
+
+	values.Set("order", postgrestOrder(query.Sort, query.Desc))
+	setPostgrestPage(values, query.Limit, query.Offset)
+
+	var breweriesDb []BreweryDB
+	total, err := db.restCount(ctx, "breweries", values, &breweriesDb)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch breweries: %w", err)
+	}
+
+	festivalCounts, err := db.getFestivalCounts(ctx)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch brewery counts: %w", err)
+	}
+
+	breweries := make([]Brewery, len(breweriesDb))
+	for i, brewery := range breweriesDb {
+		breweries[i] = breweryFromDB(brewery, festivalCounts[brewery.ID])
+	}
+
+	return breweries, total, nil
+}
+
+func (db *Database) CreateFestival(ctx context.Context, festival *FestivalDB) (*FestivalDB, error) {
+	created := *festival
+	created.CreatedAt = timestamp(time.Now())
+	created.UpdatedAt = created.CreatedAt
+
 	var result []FestivalDB
-	_, err := db.client.From("festivals").
-		Insert(festival, false, "", "", "").
-		ExecuteTo(&result)
+	err := db.rest(ctx, http.MethodPost, "festivals", nil, created, &result)
 
 	if err != nil {
 		return nil, fmt.Errorf("failed to create festival: %w", err)
@@ -238,5 +600,315 @@ func (db *Database) CreateFestival(festival *FestivalDB) (*FestivalDB, error) {
 		return nil, fmt.Errorf("no festival returned after creation")
 	}
 
+	if created.CreatedBy != "" {
+		if err := db.AddFestivalOwners(ctx, result[0].ID, []string{created.CreatedBy}); err != nil {
+			if deleteErr := db.DeleteFestival(ctx, result[0].ID); deleteErr != nil {
+				log.Printf("Failed to remove festival %d without owner: %v", result[0].ID, deleteErr)
+			}
+			return nil, err
+		}
+	}
+
+	return &result[0], nil
+}
+
+func (db *Database) UpdateFestival(ctx context.Context, id int64, festival *FestivalDB) (*FestivalDB, error) {
+	updated := *festival
+	updated.ID = id
+	updated.CreatedBy = ""
+	updated.CreatedAt = ""
+	updated.UpdatedAt = timestamp(time.Now())
+
+	var result []FestivalDB
+	err := db.rest(ctx, http.MethodPatch, "festivals", url.Values{"id": {"eq." + strconv.FormatInt(id, 10)}}, updated, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update festival %d: %w", id, err)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("festival %d: %w", id, ErrNotFound)
+	}
+
 	return &result[0], nil
 }
+
+func (db *Database) DeleteFestival(ctx context.Context, id int64) error {
+	var result []FestivalDB
+	err := db.rest(ctx, http.MethodDelete, "festivals", url.Values{"id": {"eq." + strconv.FormatInt(id, 10)}}, nil, &result)
+	if err != nil {
+		return fmt.Errorf("failed to delete festival %d: %w", id, err)
+	}
+
+	if len(result) == 0 {
+		return fmt.Errorf("festival %d: %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+func (db *Database) CreateBrewery(ctx context.Context, brewery *BreweryDB) (*BreweryDB, error) {
+	created := *brewery
+	created.ID = 0
+
+	var result []BreweryDB
+	err := db.rest(ctx, http.MethodPost, "breweries", nil, created, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create brewery: %w", err)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no brewery returned after creation")
+	}
+
+	return &result[0], nil
+}
+
+func (db *Database) UpdateBrewery(ctx context.Context, id int64, brewery *BreweryDB) (*BreweryDB, error) {
+	updated := *brewery
+	updated.ID = id
+
+	var result []BreweryDB
+	err := db.rest(ctx, http.MethodPatch, "breweries", url.Values{"id": {"eq." + strconv.FormatInt(id, 10)}}, updated, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update brewery %d: %w", id, err)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+	}
+
+	return &result[0], nil
+}
+
+func (db *Database) DeleteBrewery(ctx context.Context, id int64, cascade bool) error {
+	linkQuery := url.Values{"brewery_id": {"eq." + strconv.FormatInt(id, 10)}}
+
+	if cascade {
+		err := db.rest(ctx, http.MethodDelete, "festivals_breweries", linkQuery, nil, nil)
+		if err != nil {
+			return fmt.Errorf("failed to unlink brewery %d: %w", id, err)
+		}
+	} else {
+		var links []FestivalBrewery
+		err := db.rest(ctx, http.MethodGet, "festivals_breweries", url.Values{
+			"select":     {"festival_id,brewery_id"},
+			"brewery_id": linkQuery["brewery_id"],
+			"limit":      {"1"},
+		}, nil, &links)
+		if err != nil {
+			return fmt.Errorf("failed to check festivals for brewery %d: %w", id, err)
+		}
+
+		if len(links) > 0 {
+			return fmt.Errorf("brewery %d is linked to festivals: %w", id, ErrConflict)
+		}
+	}
+
+	var result []BreweryDB
+	err := db.rest(ctx, http.MethodDelete, "breweries", url.Values{"id": {"eq." + strconv.FormatInt(id, 10)}}, nil, &result)
+	if err != nil {
+		return fmt.Errorf("failed to delete brewery %d: %w", id, err)
+	}
+
+	if len(result) == 0 {
+		return fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+	}
+
+	return nil
+}
+
+func (db *Database) AddBreweriesToFestival(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	links := make([]FestivalBrewery, len(breweryIDs))
+	for i, breweryID := range breweryIDs {
+		links[i] = FestivalBrewery{FestivalID: festivalID, BreweryID: breweryID}
+	}
+
+	err := db.rest(ctx, http.MethodPost, "festivals_breweries", nil, links, nil)
+	if err != nil {
+		return fmt.Errorf("failed to add breweries to festival %d: %w", festivalID, err)
+	}
+
+	return nil
+}
+
+func (db *Database) RemoveBreweryFromFestival(ctx context.Context, festivalID, breweryID int64) error {
+	var result []FestivalBrewery
+	err := db.rest(ctx, http.MethodDelete, "festivals_breweries", url.Values{
+		"festival_id": {"eq." + strconv.FormatInt(festivalID, 10)},
+		"brewery_id":  {"eq." + strconv.FormatInt(breweryID, 10)},
+	}, nil, &result)
+	if err != nil {
+		return fmt.Errorf("failed to remove brewery %d from festival %d: %w", breweryID, festivalID, err)
+	}
+
+	if len(result) == 0 {
+		return fmt.Errorf("brewery %d in festival %d: %w", breweryID, festivalID, ErrNotFound)
+	}
+
+	return nil
+}
+
+func (db *Database) ReplaceFestivalBreweries(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	err := db.rpc(ctx, "replace_festival_breweries", map[string]any{
+		"p_festival_id": festivalID,
+		"p_brewery_ids": breweryIDs,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to replace breweries of festival %d: %w", festivalID, err)
+	}
+
+	return nil
+}
+
+func (db *Database) GetFestivalOwners(ctx context.Context, festivalID int64) ([]string, error) {
+	var result []struct {
+		FestivalOwners []FestivalOwner `json:"festival_owners"`
+	}
+	err := db.rest(ctx, http.MethodGet, "festivals", url.Values{
+		"select": {"id,festival_owners(user_id)"},
+		"id":     {"eq." + strconv.FormatInt(festivalID, 10)},
+	}, nil, &result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch owners of festival %d: %w", festivalID, err)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("festival %d: %w", festivalID, ErrNotFound)
+	}
+
+	owners := make([]string, len(result[0].FestivalOwners))
+	for i, owner := range result[0].FestivalOwners {
+		owners[i] = owner.UserID
+	}
+	sort.Strings(owners)
+	return owners, nil
+}
+
+func (db *Database) GetFestivalsByOwner(ctx context.Context, userID string) ([]Festival, error) {
+	var ownedFestivals []struct {
+		FestivalID int64      `json:"festival_id"`
+		Festivals  FestivalDB `json:"festivals"`
+	}
+
+	err := db.rest(ctx, http.MethodGet, "festival_owners", url.Values{
+		"select":  {"festival_id,festivals(*)"},
+		"user_id": {"eq." + userID},
+		"order":   {"festival_id"},
+	}, nil, &ownedFestivals)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch festivals of user %s: %w", userID, err)
+	}
+
+	if len(ownedFestivals) == 0 {
+		return []Festival{}, nil
+	}
+
+	breweryCounts, err := db.getBreweryCounts(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch brewery counts: %w", err)
+	}
+
+	festivals := make([]Festival, len(ownedFestivals))
+	for index, festival := range ownedFestivals {
+		festivals[index] = festivalFromDB(festival.Festivals, breweryCounts[festival.FestivalID])
+	}
+
+	return festivals, nil
+}
+
+func (db *Database) AddFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	owners := make([]FestivalOwner, len(userIDs))
+	for i, userID := range userIDs {
+		owners[i] = FestivalOwner{FestivalID: festivalID, UserID: userID}
+	}
+
+	err := db.do(ctx, http.MethodPost, "/rest/v1/festival_owners", nil, owners, map[string]string{
+		"Prefer": "resolution=ignore-duplicates",
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to add owners to festival %d: %w", festivalID, err)
+	}
+
+	return nil
+}
+
+func (db *Database) ReplaceFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	err := db.rpc(ctx, "replace_festival_owners", map[string]any{
+		"p_festival_id": festivalID,
+		"p_user_ids":    userIDs,
+	}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to replace owners of festival %d: %w", festivalID, err)
+	}
+
+	return nil
+}
+
+func festivalFromDB(fdb FestivalDB, breweryCount int) Festival {
+	startDate, _ := ConvertTime(fdb.StartDate)
+	endDate, _ := ConvertTime(fdb.EndDate)
+	createdAt, _ := time.Parse(time.RFC3339, fdb.CreatedAt)
+	updatedAt, _ := time.Parse(time.RFC3339, fdb.UpdatedAt)
+
+	return Festival{
+		ID:          fdb.ID,
+		Name:        fdb.Name,
+		Description: fdb.Description,
+		StartDate:   startDate,
+		EndDate:     endDate,
+		City:        fdb.City,
+		Region:      fdb.Region,
+		Location: Location{
+			Latitude:  fdb.Latitude,
+			Longitude: fdb.Longitude,
+		},
+		Image:        fdb.Image,
+		Website:      fdb.Website,
+		BreweryCount: breweryCount,
+		Cancelled:    fdb.Cancelled,
+		CreatedBy:    fdb.CreatedBy,
+		CreatedAt:    createdAt,
+		UpdatedAt:    updatedAt,
+	}
+}
+
+func festivalToDB(festival Festival) FestivalDB {
+	return FestivalDB{
+		ID:          festival.ID,
+		Name:        festival.Name,
+		Description: festival.Description,
+		StartDate:   festival.StartDate.Format(DefaultTimeFormat),
+		EndDate:     festival.EndDate.Format(DefaultTimeFormat),
+		City:        festival.City,
+		Region:      festival.Region,
+		Latitude:    festival.Location.Latitude,
+		Longitude:   festival.Location.Longitude,
+		Image:       festival.Image,
+		Website:     festival.Website,
+		Cancelled:   festival.Cancelled,
+		CreatedBy:   festival.CreatedBy,
+	}
+}
+
+func breweryFromDB(bdb BreweryDB, festivalCount int) Brewery {
+	return Brewery{
+		ID:            bdb.ID,
+		Name:          bdb.Name,
+		Description:   bdb.Description,
+		City:          bdb.City,
+		Website:       bdb.Website,
+		Logo:          bdb.Logo,
+		FestivalCount: festivalCount,
+	}
+}
+
+func breweryToDB(brewery Brewery) BreweryDB {
+	return BreweryDB{
+		ID:          brewery.ID,
+		Name:        brewery.Name,
+		Description: brewery.Description,
+		City:        brewery.City,
+		Website:     brewery.Website,
+		Logo:        brewery.Logo,
+	}
+}
@@ -1,7 +1,15 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestNewDatabase(t *testing.T) {
@@ -37,3 +45,531 @@ func TestNewDatabase(t *testing.T) {
 		}
 	})
 }
+
+func newTestSupabaseServer(t *testing.T, handler http.HandlerFunc) *Database {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	db, err := NewDatabase(server.URL, "test-key")
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+
+	return db
+}
+
+func TestDatabaseSupabaseRequests(t *testing.T) {
+	t.Run("fetches festivals and brewery counts", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("apikey") != "test-key" {
+				t.Errorf("Expected apikey header test-key, got %s", r.Header.Get("apikey"))
+			}
+
+			switch r.URL.Path {
+			case "/rest/v1/festivals":
+				w.Write([]byte(`[{"id":1,"name":"Test Festival","start_date":"2025-10-01","end_date":"2025-10-03"}]`))
+			case "/rest/v1/rpc/get_festival_brewery_counts":
+				w.Write([]byte(`[{"festival_id":1,"count":4}]`))
+			default:
+				t.Errorf("Unexpected request to %s", r.URL.Path)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		})
+
+		festivals, err := db.GetFestivals(context.Background())
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(festivals) != 1 || festivals[0].BreweryCount != 4 {
+			t.Errorf("Expected one festival with 4 breweries, got %+v", festivals)
+		}
+	})
+
+	t.Run("pushes festival filters down to PostgREST", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/rest/v1/festivals":
+				query := r.URL.Query()
+				if query.Get("region") != "eq.Bretagne" || query.Get("start_date") != "lte.2025-12-31" ||
+					query.Get("order") != "start_date.desc,id.asc" || query.Get("limit") != "10" || query.Get("offset") != "20" {
+					t.Errorf("Unexpected festival query %s", r.URL.RawQuery)
+				}
+				if r.Header.Get("Prefer") != "count=exact" {
+					t.Errorf("Expected exact count, got %s", r.Header.Get("Prefer"))
+				}
+				w.Header().Set("Content-Range", "20-20/21")
+				w.Write([]byte(`[{"id":21,"name":"Test Festival","start_date":"2025-10-01","end_date":"2025-10-03"}]`))
+			case "/rest/v1/rpc/get_festival_brewery_counts":
+				w.Write([]byte(`[]`))
+			}
+		})
+
+		festivals, total, err := db.ListFestivals(context.Background(), FestivalQuery{
+			Region: "Bretagne", To: "2025-12-31", Sort: "start_date", Desc: true, Limit: 10, Offset: 20,
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if total != 21 || len(festivals) != 1 {
+			t.Errorf("Expected one festival out of 21, got %d %+v", total, festivals)
+		}
+	})
+
+	t.Run("asks PostgREST to return created rows", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.Header.Get("Prefer") != "return=representation" {
+				t.Errorf("Expected POST with return=representation, got %s %q", r.Method, r.Header.Get("Prefer"))
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`[{"id":7,"name":"New Festival"}]`))
+		})
+
+		created, err := db.CreateFestival(context.Background(), &FestivalDB{Name: "New Festival"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if created.ID != 7 {
+			t.Errorf("Expected festival 7, got %d", created.ID)
+		}
+	})
+
+	t.Run("verifies token with the user's bearer token", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/auth/v1/user" {
+				t.Errorf("Expected /auth/v1/user, got %s", r.URL.Path)
+			}
+			if r.Header.Get("Authorization") != "Bearer user-token" {
+				t.Errorf("Expected user bearer token, got %s", r.Header.Get("Authorization"))
+			}
+			w.Write([]byte(`{"id":"user-123","email":"test@example.com","app_metadata":{"roles":["admin"]}}`))
+		})
+
+		user, err := db.VerifyToken(context.Background(), "user-token")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if user.ID != "user-123" {
+			t.Errorf("Expected user-123, got %s", user.ID)
+		}
+
+		if !slices.Equal(user.Roles, []string{RoleAdmin}) {
+			t.Errorf("Expected roles from the app metadata, got %v", user.Roles)
+		}
+	})
+
+	t.Run("loads roles from the user_roles table", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/auth/v1/user":
+				w.Write([]byte(`{"id":"user-123","email":"test@example.com","app_metadata":{"roles":["admin"]}}`))
+			case "/rest/v1/user_roles":
+				if r.URL.Query().Get("user_id") != "eq.user-123" {
+					t.Errorf("Expected roles of user-123, got %s", r.URL.RawQuery)
+				}
+				w.Write([]byte(`[{"role":"contributor"},{"role":"viewer"}]`))
+			default:
+				t.Errorf("Unexpected %s request to %s", r.Method, r.URL.Path)
+			}
+		})
+		db.rolesSource = RolesSourceTable
+
+		user, err := db.VerifyToken(context.Background(), "user-token")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if !slices.Equal(user.Roles, []string{RoleContributor, RoleViewer}) {
+			t.Errorf("Expected roles from the table, got %v", user.Roles)
+		}
+	})
+
+	t.Run("caches table roles per user between requests", func(t *testing.T) {
+		roleRequests := 0
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/auth/v1/user":
+				w.Write([]byte(`{"id":"user-123","email":"test@example.com"}`))
+			case "/rest/v1/user_roles":
+				roleRequests++
+				w.Write([]byte(`[{"role":"organizer"}]`))
+			}
+		})
+		db.rolesSource = RolesSourceTable
+
+		for range 3 {
+			user, err := db.VerifyToken(context.Background(), "user-token")
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !slices.Equal(user.Roles, []string{RoleOrganizer}) {
+				t.Errorf("Expected organizer role, got %v", user.Roles)
+			}
+		}
+		if roleRequests != 1 {
+			t.Errorf("Expected roles to be fetched once, got %d requests", roleRequests)
+		}
+
+		db.rolesCache["user-123"] = cachedRoles{roles: []string{RoleOrganizer}, expiresAt: time.Now().Add(-time.Second)}
+		if _, err := db.VerifyToken(context.Background(), "user-token"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if roleRequests != 2 {
+			t.Errorf("Expected expired roles to be refetched, got %d requests", roleRequests)
+		}
+	})
+
+	t.Run("verifies JWTs locally when a secret is configured", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			t.Errorf("Unexpected %s request to %s", r.Method, r.URL.Path)
+		})
+		db.verifier, _ = NewJWTVerifier(testJWTSecret, "", testJWTAudience, testJWTIssuer)
+
+		user, err := db.VerifyToken(context.Background(), mintToken(t, "HS256", "", []byte(testJWTSecret), testClaims(time.Now())))
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if user.ID != "user-123" {
+			t.Errorf("Expected user-123, got %s", user.ID)
+		}
+
+		if _, err := db.VerifyToken(context.Background(), "user-token"); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken without fallback, got %v", err)
+		}
+	})
+
+	t.Run("falls back to Supabase Auth when local verification fails", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/auth/v1/user" {
+				t.Errorf("Expected /auth/v1/user, got %s", r.URL.Path)
+			}
+			w.Write([]byte(`{"id":"user-456","email":"remote@example.com"}`))
+		})
+		db.verifier, _ = NewJWTVerifier(testJWTSecret, "", testJWTAudience, testJWTIssuer)
+		db.remoteFallback = true
+
+		user, err := db.VerifyToken(context.Background(), "user-token")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if user.ID != "user-456" {
+			t.Errorf("Expected user-456, got %s", user.ID)
+		}
+	})
+
+	t.Run("refreshes sessions with the refresh token grant", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/auth/v1/token" || r.URL.Query().Get("grant_type") != "refresh_token" {
+				t.Errorf("Unexpected %s request to %s", r.Method, r.URL)
+			}
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["refresh_token"] != "old-refresh" {
+				t.Errorf("Expected refresh_token old-refresh, got %v", body)
+			}
+			w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","user":{"id":"user-123","email":"test@example.com"}}`))
+		})
+
+		session, err := db.RefreshSession(context.Background(), "old-refresh")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if session.AccessToken != "new-access" || session.RefreshToken != "new-refresh" || session.User.ID != "user-123" {
+			t.Errorf("Expected the new session, got %+v", session)
+		}
+	})
+
+	t.Run("logs out with the user's bearer token", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodPost || r.URL.Path != "/auth/v1/logout" {
+				t.Errorf("Unexpected %s request to %s", r.Method, r.URL.Path)
+			}
+			if r.Header.Get("Authorization") != "Bearer user-token" {
+				t.Errorf("Expected user bearer token, got %s", r.Header.Get("Authorization"))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		if err := db.Logout(context.Background(), "user-token"); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("refuses to delete a brewery that is still linked", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodGet || r.URL.Path != "/rest/v1/festivals_breweries" {
+				t.Errorf("Unexpected %s request to %s", r.Method, r.URL.Path)
+			}
+			w.Write([]byte(`[{"festival_id":1,"brewery_id":1}]`))
+		})
+
+		err := db.DeleteBrewery(context.Background(), 1, false)
+
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("unlinks brewery before deleting it with cascade", func(t *testing.T) {
+		var requests []string
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			w.Write([]byte(`[{"id":1,"name":"Test Brewery"}]`))
+		})
+
+		if err := db.DeleteBrewery(context.Background(), 1, true); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := []string{"DELETE /rest/v1/festivals_breweries", "DELETE /rest/v1/breweries"}
+		if strings.Join(requests, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected requests %v, got %v", expected, requests)
+		}
+	})
+
+	t.Run("maps duplicate links to ErrConflict", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"code":"23505","message":"duplicate key value violates unique constraint"}`))
+		})
+
+		err := db.AddBreweriesToFestival(context.Background(), 1, []int64{2})
+
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+	})
+
+	t.Run("replaces lineup through a single RPC call", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/rest/v1/rpc/replace_festival_breweries" {
+				t.Errorf("Unexpected request to %s", r.URL.Path)
+			}
+			var params map[string]any
+			json.NewDecoder(r.Body).Decode(&params)
+			if params["p_festival_id"] != float64(1) {
+				t.Errorf("Expected p_festival_id 1, got %v", params["p_festival_id"])
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		if err := db.ReplaceFestivalBreweries(context.Background(), 1, []int64{2, 3}); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+	})
+
+	t.Run("records the creator as owner after creating a festival", func(t *testing.T) {
+		var requests []string
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch r.URL.Path {
+			case "/rest/v1/festivals":
+				w.Write([]byte(`[{"id":7,"name":"Owned","created_by":"user-123"}]`))
+			case "/rest/v1/festival_owners":
+				if prefer := r.Header.Get("Prefer"); prefer != "resolution=ignore-duplicates" {
+					t.Errorf("Expected duplicates to be ignored, got Prefer %q", prefer)
+				}
+				var owners []FestivalOwner
+				json.NewDecoder(r.Body).Decode(&owners)
+				if len(owners) != 1 || owners[0] != (FestivalOwner{FestivalID: 7, UserID: "user-123"}) {
+					t.Errorf("Expected owner user-123 of festival 7, got %+v", owners)
+				}
+				w.WriteHeader(http.StatusCreated)
+			}
+		})
+
+		created, err := db.CreateFestival(context.Background(), &FestivalDB{Name: "Owned", CreatedBy: "user-123"})
+		if err != nil || created.ID != 7 {
+			t.Fatalf("Expected festival 7, got %+v, %v", created, err)
+		}
+
+		expected := []string{"POST /rest/v1/festivals", "POST /rest/v1/festival_owners"}
+		if strings.Join(requests, ",") != strings.Join(expected, ",") {
+			t.Errorf("Expected requests %v, got %v", expected, requests)
+		}
+	})
+
+	t.Run("embeds owners when fetching them", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("id") == "eq.999" {
+				w.Write([]byte(`[]`))
+				return
+			}
+			if selected := r.URL.Query().Get("select"); selected != "id,festival_owners(user_id)" {
+				t.Errorf("Expected owners to be embedded, got select %q", selected)
+			}
+			w.Write([]byte(`[{"id":1,"festival_owners":[{"user_id":"user-456"},{"user_id":"user-123"}]}]`))
+		})
+
+		owners, err := db.GetFestivalOwners(context.Background(), 1)
+		if err != nil || !slices.Equal(owners, []string{"user-123", "user-456"}) {
+			t.Errorf("Expected owners user-123 and user-456, got %v, %v", owners, err)
+		}
+
+		if _, err := db.GetFestivalOwners(context.Background(), 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("replaces owners through a single RPC call", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/rest/v1/rpc/replace_festival_owners" {
+				t.Errorf("Unexpected request to %s", r.URL.Path)
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"23503","message":"festival not found"}`))
+		})
+
+		if err := db.ReplaceFestivalOwners(context.Background(), 1, []string{"user-123"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("stops waiting when the context deadline passes", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-release:
+			case <-r.Context().Done():
+			}
+		})
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := db.VerifyToken(ctx, "user-token")
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+		}
+	})
+}
+
+func TestDatabaseSupabaseErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		contains []string
+	}{
+		{
+			name:     "PostgREST error",
+			status:   http.StatusBadRequest,
+			body:     `{"code":"22P02","message":"invalid input syntax"}`,
+			contains: []string{"status 400", "(22P02)", "invalid input syntax"},
+		},
+		{
+			name:     "GoTrue error description",
+			status:   http.StatusBadRequest,
+			body:     `{"error":"invalid_grant","error_description":"Invalid login credentials"}`,
+			contains: []string{"status 400", "Invalid login credentials"},
+		},
+		{
+			name:     "GoTrue msg",
+			status:   http.StatusUnauthorized,
+			body:     `{"code":401,"msg":"invalid JWT"}`,
+			contains: []string{"status 401", "invalid JWT"},
+		},
+		{
+			name:     "plain error field",
+			status:   http.StatusForbidden,
+			body:     `{"error":"forbidden"}`,
+			contains: []string{"status 403", "forbidden"},
+		},
+		{
+			name:     "non-JSON body",
+			status:   http.StatusBadGateway,
+			body:     `<html>Bad Gateway</html>`,
+			contains: []string{"status 502"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			})
+
+			err := db.do(context.Background(), http.MethodGet, "/rest/v1/festivals", nil, nil, nil, nil)
+
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			for _, part := range tt.contains {
+				if !strings.Contains(err.Error(), part) {
+					t.Errorf("Expected error to contain %q, got %v", part, err)
+				}
+			}
+		})
+	}
+
+	t.Run("rejects undecodable success bodies", func(t *testing.T) {
+		db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`not json`))
+		})
+
+		_, err := db.GetBreweries(context.Background())
+
+		if err == nil || !strings.Contains(err.Error(), "failed to decode response") {
+			t.Errorf("Expected decode error, got %v", err)
+		}
+	})
+}
+
+func TestDatabaseRestCount(t *testing.T) {
+	tests := []struct {
+		name         string
+		contentRange string
+		status       int
+		wantTotal    int
+		wantErr      bool
+	}{
+		{name: "page of a larger set", contentRange: "0-9/42", status: http.StatusPartialContent, wantTotal: 42},
+		{name: "single row", contentRange: "0-0/1", status: http.StatusOK, wantTotal: 1},
+		{name: "empty result", contentRange: "*/0", status: http.StatusOK, wantTotal: 0},
+		{name: "offset past the end", contentRange: "*/5", status: http.StatusRequestedRangeNotSatisfiable, wantErr: true},
+		{name: "unknown total", contentRange: "0-9/*", status: http.StatusOK, wantErr: true},
+		{name: "missing header", status: http.StatusOK, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newTestSupabaseServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Header.Get("Prefer") != "count=exact" {
+					t.Errorf("Expected exact count, got %s", r.Header.Get("Prefer"))
+				}
+				if tt.contentRange != "" {
+					w.Header().Set("Content-Range", tt.contentRange)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(`[]`))
+			})
+
+			var rows []FestivalDB
+			total, err := db.restCount(context.Background(), "festivals", nil, &rows)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error, got total %d", total)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if total != tt.wantTotal {
+				t.Errorf("Expected total %d, got %d", tt.wantTotal, total)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var festivalFeeds = map[string]struct {
+	title string
+	query FestivalQuery
+}{
+	"new":      {title: FeedTitleNew, query: FestivalQuery{Sort: "created_at", Desc: true, Limit: FeedSize}},
+	"upcoming": {title: FeedTitleUpcoming, query: FestivalQuery{Upcoming: true, Sort: "start_date", Limit: FeedSize}},
+}
+
+func festivalURL(frontendURL string, id int64) string {
+	return frontendURL + "/festival/" + strconv.FormatInt(id, 10)
+}
+
+func festivalSummary(festival Festival) string {
+	summary := festival.StartDate.Format(DefaultTimeFormat) + " → " + festival.EndDate.Format(DefaultTimeFormat)
+	if location := festivalLocation(festival); location != "" {
+		summary += ", " + location
+	}
+	if festival.Cancelled {
+		summary += " (annulé)"
+	}
+	if festival.Description != "" {
+		summary += "\n\n" + festival.Description
+	}
+	return summary
+}
+
+func festivalUpdatedAt(festival Festival) time.Time {
+	if festival.UpdatedAt.IsZero() {
+		return festival.CreatedAt
+	}
+	return festival.UpdatedAt
+}
+
+func feedUpdatedAt(festivals []Festival, fallback time.Time) time.Time {
+	updated := time.Time{}
+	for _, festival := range festivals {
+		if at := festivalUpdatedAt(festival); at.After(updated) {
+			updated = at
+		}
+	}
+	if updated.IsZero() {
+		return fallback
+	}
+	return updated
+}
+
+func festivalsToAtom(festivals []Festival, name, title, frontendURL string, now time.Time) AtomFeed {
+	feed := AtomFeed{
+		ID:      fmt.Sprintf("urn:%s:feed:%s", ICalUIDDomain, name),
+		Title:   title,
+		Link:    AtomLink{Href: frontendURL + "/", Rel: "alternate"},
+		Updated: timestamp(feedUpdatedAt(festivals, now)),
+		Author:  AtomPerson{Name: FeedAuthor},
+		Entries: []AtomEntry{},
+	}
+
+	for _, festival := range festivals {
+		feed.Entries = append(feed.Entries, AtomEntry{
+			ID:        fmt.Sprintf("urn:%s:festival:%d", ICalUIDDomain, festival.ID),
+			Title:     festival.Name,
+			Link:      AtomLink{Href: festivalURL(frontendURL, festival.ID), Rel: "alternate"},
+			Published: timestamp(festival.CreatedAt),
+			Updated:   timestamp(festivalUpdatedAt(festival)),
+			Summary:   festivalSummary(festival),
+		})
+	}
+
+	return feed
+}
+
+func festivalsToRSS(festivals []Festival, title, frontendURL string, now time.Time) RSSFeed {
+	feed := RSSFeed{
+		Version: "2.0",
+		Channel: RSSChannel{
+			Title:         title,
+			Link:          frontendURL + "/",
+			Description:   title,
+			LastBuildDate: feedUpdatedAt(festivals, now).UTC().Format(time.RFC1123Z),
+			Items:         []RSSItem{},
+		},
+	}
+
+	for _, festival := range festivals {
+		link := festivalURL(frontendURL, festival.ID)
+		feed.Channel.Items = append(feed.Channel.Items, RSSItem{
+			Title:       festival.Name,
+			Link:        link,
+			GUID:        RSSGUID{Value: link, IsPermaLink: true},
+			PubDate:     festival.CreatedAt.UTC().Format(time.RFC1123Z),
+			Description: festivalSummary(festival),
+		})
+	}
+
+	return feed
+}
+
+func parseFeedPath(feed string) (string, string, bool) {
+	name, format, ok := strings.Cut(feed, ".")
+	if _, known := festivalFeeds[name]; !ok || !known || (format != "atom" && format != "rss") {
+		return "", "", false
+	}
+	return name, format, true
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFestivalsToAtom(t *testing.T) {
+	now := time.Date(2025, 9, 10, 0, 0, 0, 0, time.UTC)
+	festivals := []Festival{
+		{
+			ID:        1,
+			Name:      "Lille Beer Fest",
+			StartDate: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2025, 10, 3, 0, 0, 0, 0, time.UTC),
+			City:      "Lille",
+			CreatedAt: time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2025, 9, 1, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			ID:        2,
+			Name:      "Rennes Craft",
+			CreatedAt: time.Date(2025, 8, 15, 9, 0, 0, 0, time.UTC),
+		},
+	}
+
+	feed := festivalsToAtom(festivals, "new", FeedTitleNew, "https://festivals.example.com", now)
+
+	if feed.Updated != "2025-09-01T09:00:00Z" {
+		t.Errorf("Expected feed updated at the latest festival change, got %s", feed.Updated)
+	}
+
+	entry := feed.Entries[0]
+	if entry.ID != "urn:beer-festival:festival:1" || entry.Published != "2025-08-01T09:00:00Z" || entry.Updated != "2025-09-01T09:00:00Z" {
+		t.Errorf("Expected stable id and timestamps, got %+v", entry)
+	}
+	if !strings.HasPrefix(entry.Summary, "2025-10-01 → 2025-10-03, Lille") {
+		t.Errorf("Expected dates and location in summary, got %q", entry.Summary)
+	}
+
+	if feed.Entries[1].Updated != "2025-08-15T09:00:00Z" {
+		t.Errorf("Expected creation time when never updated, got %s", feed.Entries[1].Updated)
+	}
+
+	if empty := festivalsToAtom(nil, "new", FeedTitleNew, "https://festivals.example.com", now); empty.Updated != "2025-09-10T00:00:00Z" {
+		t.Errorf("Expected empty feed to use the current time, got %s", empty.Updated)
+	}
+}
+
+func TestFestivalsToRSS(t *testing.T) {
+	festivals := []Festival{{ID: 4, Name: "Nancy Fest", CreatedAt: time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)}}
+
+	feed := festivalsToRSS(festivals, FeedTitleUpcoming, "https://festivals.example.com", time.Now())
+
+	item := feed.Channel.Items[0]
+	if item.Link != "https://festivals.example.com/festival/4" || item.GUID.Value != item.Link || !item.GUID.IsPermaLink {
+		t.Errorf("Expected permalink to the festival page, got %+v", item)
+	}
+	if item.PubDate != "Fri, 01 Aug 2025 09:00:00 +0000" {
+		t.Errorf("Expected RFC 1123 publication date, got %s", item.PubDate)
+	}
+}
@@ -1,9 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
 	"log"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -25,6 +33,7 @@ func enableCORS(w http.ResponseWriter, r *http.Request, allowedOrigins string) {
 
 	w.Header().Set(HeaderCORSMethods, CORSMethods)
 	w.Header().Set(HeaderCORSHeaders, CORSHeaders)
+	w.Header().Set(HeaderCORSExpose, HeaderTotalCount)
 }
 
 func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
@@ -45,17 +54,24 @@ func makeFestivalsHandler(db DatabaseInterface, allowedOrigins string) http.Hand
 			return
 		}
 
-		festivals, err := db.GetFestivals()
+		query, err := parseFestivalQuery(r.URL.Query())
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		festivals, total, err := db.ListFestivals(r.Context(), query)
 		if err != nil {
 			log.Printf("Error fetching festivals from database: %v", err)
-			http.Error(w, DefaultErrorMessage, http.StatusInternalServerError)
+			writeDatabaseError(w, r, err)
 			return
 		}
 
 		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		w.Header().Set(HeaderTotalCount, strconv.Itoa(total))
 		if err := json.NewEncoder(w).Encode(festivals); err != nil {
 			log.Printf("Error encoding festivals: %v", err)
-			http.Error(w, DefaultErrorMessage, http.StatusInternalServerError)
+			writeInternalError(w, r)
 			return
 		}
 	}
@@ -70,38 +86,44 @@ func makeLoginHandler(db DatabaseInterface, allowedOrigins string) http.HandlerF
 			return
 		}
 
-		if r.Method != "POST" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-			return
-		}
-
 		var loginReq LoginRequest
 		if err := json.NewDecoder(r.Body).Decode(&loginReq); err != nil {
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, ErrorCodeInvalidBody, "Invalid request body")
 			return
 		}
 
-		if loginReq.Email == "" || loginReq.Password == "" {
-			http.Error(w, "Email and password are required", http.StatusBadRequest)
+		var validation ValidationError
+		if loginReq.Email == "" {
+			validation.Add("email", FieldCodeRequired, "email is required")
+		}
+		if loginReq.Password == "" {
+			validation.Add("password", FieldCodeRequired, "password is required")
+		}
+		if err := validation.Err(); err != nil {
+			writeInvalidRequest(w, r, err)
 			return
 		}
 
-		loginResp, err := db.Login(loginReq.Email, loginReq.Password)
+		loginResp, err := db.Login(r.Context(), loginReq.Email, loginReq.Password)
 		if err != nil {
-			http.Error(w, "Invalid credentials", http.StatusUnauthorized)
+			if isTimeout(err) {
+				writeDatabaseError(w, r, err)
+				return
+			}
+			writeProblem(w, r, http.StatusUnauthorized, ErrorCodeInvalidCredentials, "Invalid credentials")
 			return
 		}
 
 		w.Header().Set(HeaderContentType, ContentTypeJSON)
 		if err := json.NewEncoder(w).Encode(loginResp); err != nil {
 			log.Printf("Error encoding login response: %v", err)
-			http.Error(w, DefaultErrorMessage, http.StatusInternalServerError)
+			writeInternalError(w, r)
 			return
 		}
 	}
 }
 
-func makeVerifyHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+func makeRefreshHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		enableCORS(w, r, allowedOrigins)
 
@@ -110,50 +132,107 @@ func makeVerifyHandler(db DatabaseInterface, allowedOrigins string) http.Handler
 			return
 		}
 
-		if r.Method != "GET" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		var refreshReq RefreshRequest
+		if err := json.NewDecoder(r.Body).Decode(&refreshReq); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, ErrorCodeInvalidBody, "Invalid request body")
 			return
 		}
 
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			w.Header().Set(HeaderContentType, ContentTypeJSON)
-			json.NewEncoder(w).Encode(VerifyResponse{
-				Valid: false,
-				Error: "No authorization header",
-			})
+		if refreshReq.RefreshToken == "" {
+			writeInvalidRequest(w, r, fieldError("refreshToken", FieldCodeRequired, "refreshToken is required"))
 			return
 		}
 
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token == authHeader {
-			w.Header().Set(HeaderContentType, ContentTypeJSON)
-			json.NewEncoder(w).Encode(VerifyResponse{
-				Valid: false,
-				Error: "Invalid authorization format",
-			})
+		refreshResp, err := db.RefreshSession(r.Context(), refreshReq.RefreshToken)
+		if err != nil {
+			log.Printf("Session refresh failed: %v", err)
+			if isTimeout(err) {
+				writeDatabaseError(w, r, err)
+				return
+			}
+			writeProblem(w, r, http.StatusUnauthorized, ErrorCodeInvalidToken, "Invalid refresh token")
 			return
 		}
 
-		user, err := db.VerifyToken(token)
-		if err != nil {
-			w.Header().Set(HeaderContentType, ContentTypeJSON)
-			json.NewEncoder(w).Encode(VerifyResponse{
-				Valid: false,
-				Error: "Invalid token",
-			})
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		if err := json.NewEncoder(w).Encode(refreshResp); err != nil {
+			log.Printf("Error encoding refresh response: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeLogoutHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			writeUnauthorized(w, r, ErrorCodeUnauthorized, "Authorization header required", AuthChallenge)
+			return
+		}
+
+		if err := db.Logout(r.Context(), token); err != nil {
+			log.Printf("Logout failed: %v", err)
+			if isTimeout(err) {
+				writeDatabaseError(w, r, err)
+				return
+			}
+			writeUnauthorized(w, r, ErrorCodeInvalidToken, "Invalid token", AuthChallenge+`, error="invalid_token"`)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func makeVerifyHandler(allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		user, ok := userFromContext(r.Context())
+		if !ok {
+			writeUnauthorized(w, r, ErrorCodeUnauthorized, "Authorization header required", AuthChallenge)
 			return
 		}
 
 		w.Header().Set(HeaderContentType, ContentTypeJSON)
-		json.NewEncoder(w).Encode(VerifyResponse{
-			Valid: true,
-			User:  user,
-		})
+		if err := json.NewEncoder(w).Encode(VerifyResponse{Valid: true, User: user, Permissions: user.Permissions()}); err != nil {
+			log.Printf("Error encoding verify response: %v", err)
+			writeInternalError(w, r)
+			return
+		}
 	}
 }
 
-func makeFestivalBreweriesHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+func parsePathID(w http.ResponseWriter, r *http.Request, name, resource string) (int64, bool) {
+	segment := r.PathValue(name)
+	if segment == "" {
+		writeInvalidRequest(w, r, fieldError(name, FieldCodeRequired, resource+" ID is required"))
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(segment, 10, 64)
+	if err != nil || id <= 0 {
+		writeInvalidRequest(w, r, fieldError(name, FieldCodeInvalid, "Invalid "+strings.ToLower(resource)+" ID"))
+		return 0, false
+	}
+
+	return id, true
+}
+
+func makeFestivalHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		enableCORS(w, r, allowedOrigins)
 
@@ -162,36 +241,107 @@ func makeFestivalBreweriesHandler(db DatabaseInterface, allowedOrigins string) h
 			return
 		}
 
-		if r.Method != "GET" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		festivalID, ok := parsePathID(w, r, "id", "Festival")
+		if !ok {
+			return
+		}
+
+		festival, err := db.GetFestival(r.Context(), festivalID)
+		if errors.Is(err, ErrNotFound) {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeFestivalNotFound, "Festival not found")
+			return
+		}
+		if err != nil {
+			log.Printf("Error fetching festival %d: %v", festivalID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		if err := json.NewEncoder(w).Encode(festival); err != nil {
+			log.Printf("Error encoding festival: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeUpdateFestivalHandler(db DatabaseInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		festivalID, ok := parsePathID(w, r, "id", "Festival")
+		if !ok {
 			return
 		}
 
-		festivalID := strings.TrimPrefix(r.URL.Path, FestivalsBreweriesPath)
-		festivalID = strings.TrimSuffix(festivalID, "/breweries")
+		var festival FestivalDB
+		if r.Method == "PATCH" {
+			existing, err := db.GetFestival(r.Context(), festivalID)
+			if errors.Is(err, ErrNotFound) {
+				writeProblem(w, r, http.StatusNotFound, ErrorCodeFestivalNotFound, "Festival not found")
+				return
+			}
+			if err != nil {
+				log.Printf("Error fetching festival %d: %v", festivalID, err)
+				writeDatabaseError(w, r, err)
+				return
+			}
+			festival = festivalToDB(*existing)
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&festival); err != nil {
+			log.Printf("Error decoding request body: %v", err)
+			writeProblem(w, r, http.StatusBadRequest, ErrorCodeInvalidBody, "Invalid request body")
+			return
+		}
 
-		if festivalID == "" {
-			http.Error(w, "Festival ID is required", http.StatusBadRequest)
+		if err := validateFestival(&festival); err != nil {
+			writeInvalidRequest(w, r, err)
 			return
 		}
 
-		breweries, err := db.GetBreweriesByFestival(festivalID)
+		updatedFestival, err := db.UpdateFestival(r.Context(), festivalID, &festival)
+		if errors.Is(err, ErrNotFound) {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeFestivalNotFound, "Festival not found")
+			return
+		}
 		if err != nil {
-			log.Printf("Error fetching breweries for festival %s: %v", festivalID, err)
-			http.Error(w, DefaultErrorMessage, http.StatusInternalServerError)
+			log.Printf("Error updating festival %d: %v", festivalID, err)
+			writeDatabaseError(w, r, err)
 			return
 		}
 
 		w.Header().Set(HeaderContentType, ContentTypeJSON)
-		if err := json.NewEncoder(w).Encode(breweries); err != nil {
-			log.Printf("Error encoding breweries: %v", err)
-			http.Error(w, DefaultErrorMessage, http.StatusInternalServerError)
+		if err := json.NewEncoder(w).Encode(updatedFestival); err != nil {
+			log.Printf("Error encoding updated festival: %v", err)
+			writeInternalError(w, r)
 			return
 		}
 	}
 }
 
-func makeBreweriesHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+func makeDeleteFestivalHandler(db DatabaseInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		festivalID, ok := parsePathID(w, r, "id", "Festival")
+		if !ok {
+			return
+		}
+
+		err := db.DeleteFestival(r.Context(), festivalID)
+		if errors.Is(err, ErrNotFound) {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeFestivalNotFound, "Festival not found")
+			return
+		}
+		if err != nil {
+			log.Printf("Error deleting festival %d: %v", festivalID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func makeFestivalBreweriesHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		enableCORS(w, r, allowedOrigins)
 
@@ -200,27 +350,86 @@ func makeBreweriesHandler(db DatabaseInterface, allowedOrigins string) http.Hand
 			return
 		}
 
-		if r.Method != "GET" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		festivalID, ok := parsePathID(w, r, "id", "Festival")
+		if !ok {
 			return
 		}
 
-		breweries, err := db.GetBreweries()
+		breweries, err := db.GetBreweriesByFestival(r.Context(), festivalID)
 		if err != nil {
-			log.Printf("Error fetching breweries %s", err)
-			http.Error(w, DefaultErrorMessage, http.StatusInternalServerError)
+			log.Printf("Error fetching breweries for festival %d: %v", festivalID, err)
+			writeDatabaseError(w, r, err)
 			return
 		}
 
 		w.Header().Set(HeaderContentType, ContentTypeJSON)
 		if err := json.NewEncoder(w).Encode(breweries); err != nil {
 			log.Printf("Error encoding breweries: %v", err)
-			http.Error(w, DefaultErrorMessage, http.StatusInternalServerError)
+			writeInternalError(w, r)
 			return
 		}
 	}
 }
-func makeCreateFestivalHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+
+func makeUpdateLineupHandler(db DatabaseInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		festivalID, ok := parsePathID(w, r, "id", "Festival")
+		if !ok {
+			return
+		}
+
+		var lineup LineupRequest
+		if err := json.NewDecoder(r.Body).Decode(&lineup); err != nil {
+			log.Printf("Error decoding request body: %v", err)
+			writeProblem(w, r, http.StatusBadRequest, ErrorCodeInvalidBody, "Invalid request body")
+			return
+		}
+
+		if err := validateLineup(&lineup, r.Method == "POST"); err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		var err error
+		status := http.StatusOK
+		if r.Method == "POST" {
+			err = db.AddBreweriesToFestival(r.Context(), festivalID, lineup.BreweryIDs)
+			status = http.StatusCreated
+		} else {
+			err = db.ReplaceFestivalBreweries(r.Context(), festivalID, lineup.BreweryIDs)
+		}
+		if errors.Is(err, ErrNotFound) {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeNotFound, "Festival or brewery not found")
+			return
+		}
+		if errors.Is(err, ErrConflict) {
+			writeProblem(w, r, http.StatusConflict, ErrorCodeAlreadyInLineup, "Brewery is already in the festival lineup")
+			return
+		}
+		if err != nil {
+			log.Printf("Error updating lineup of festival %d: %v", festivalID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		breweries, err := db.GetBreweriesByFestival(r.Context(), festivalID)
+		if err != nil {
+			log.Printf("Error fetching breweries for festival %d: %v", festivalID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		w.WriteHeader(status)
+		if err := json.NewEncoder(w).Encode(breweries); err != nil {
+			log.Printf("Error encoding breweries: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeFestivalBreweryHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		enableCORS(w, r, allowedOrigins)
 
@@ -229,66 +438,784 @@ func makeCreateFestivalHandler(db DatabaseInterface, allowedOrigins string) http
 			return
 		}
 
-		if r.Method != "POST" {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		festivalID, ok := parsePathID(w, r, "id", "Festival")
+		if !ok {
 			return
 		}
 
-		authHeader := r.Header.Get("Authorization")
-		if authHeader == "" {
-			http.Error(w, "Authorization header required", http.StatusUnauthorized)
+		breweryID, ok := parsePathID(w, r, "breweryId", "Brewery")
+		if !ok {
 			return
 		}
 
-		token := strings.TrimPrefix(authHeader, "Bearer ")
-		if token == authHeader {
-			http.Error(w, "Invalid authorization format", http.StatusUnauthorized)
+		err := db.RemoveBreweryFromFestival(r.Context(), festivalID, breweryID)
+		if errors.Is(err, ErrNotFound) {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeNotInLineup, "Brewery is not in the festival lineup")
 			return
 		}
-
-		_, err := db.VerifyToken(token)
 		if err != nil {
-			log.Printf("Token verification failed: %v", err)
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			log.Printf("Error removing brewery %d from festival %d: %v", breweryID, festivalID, err)
+			writeDatabaseError(w, r, err)
 			return
 		}
 
-		var festival FestivalDB
-		if err := json.NewDecoder(r.Body).Decode(&festival); err != nil {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func makeFestivalOwnersHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		festivalID, ok := parsePathID(w, r, "id", "Festival")
+		if !ok {
+			return
+		}
+
+		writeFestivalOwners(w, r, db, festivalID, http.StatusOK)
+	}
+}
+
+func makeUpdateFestivalOwnersHandler(db DatabaseInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		festivalID, ok := parsePathID(w, r, "id", "Festival")
+		if !ok {
+			return
+		}
+
+		var owners OwnersRequest
+		if err := json.NewDecoder(r.Body).Decode(&owners); err != nil {
 			log.Printf("Error decoding request body: %v", err)
-			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			writeProblem(w, r, http.StatusBadRequest, ErrorCodeInvalidBody, "Invalid request body")
+			return
+		}
+
+		if err := validateOwners(&owners); err != nil {
+			writeInvalidRequest(w, r, err)
 			return
 		}
 
-		if festival.Name == "" || festival.StartDate == "" || festival.EndDate == "" {
-			http.Error(w, "Name, start_date, and end_date are required", http.StatusBadRequest)
+		var err error
+		status := http.StatusOK
+		if r.Method == "POST" {
+			err = db.AddFestivalOwners(r.Context(), festivalID, owners.UserIDs)
+			status = http.StatusCreated
+		} else {
+			err = db.ReplaceFestivalOwners(r.Context(), festivalID, owners.UserIDs)
+		}
+		if errors.Is(err, ErrNotFound) {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeNotFound, "Festival or user not found")
+			return
+		}
+		if err != nil {
+			log.Printf("Error updating owners of festival %d: %v", festivalID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		writeFestivalOwners(w, r, db, festivalID, status)
+	}
+}
+
+func writeFestivalOwners(w http.ResponseWriter, r *http.Request, db DatabaseInterface, festivalID int64, status int) {
+	owners, err := db.GetFestivalOwners(r.Context(), festivalID)
+	if errors.Is(err, ErrNotFound) {
+		writeProblem(w, r, http.StatusNotFound, ErrorCodeFestivalNotFound, "Festival not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching owners of festival %d: %v", festivalID, err)
+		writeDatabaseError(w, r, err)
+		return
+	}
+
+	w.Header().Set(HeaderContentType, ContentTypeJSON)
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(FestivalOwners{FestivalID: festivalID, Owners: owners}); err != nil {
+		log.Printf("Error encoding owners: %v", err)
+		writeInternalError(w, r)
+	}
+}
+
+func makeMyFestivalsHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		user, ok := userFromContext(r.Context())
+		if !ok {
+			writeUnauthorized(w, r, ErrorCodeUnauthorized, "Authorization header required", AuthChallenge)
 			return
 		}
 
-		_, err = time.Parse("2006-01-02", festival.StartDate)
+		festivals, err := db.GetFestivalsByOwner(r.Context(), user.ID)
 		if err != nil {
-			http.Error(w, "Invalid start_date format. Expected YYYY-MM-DD", http.StatusBadRequest)
+			log.Printf("Error fetching festivals of user %s: %v", user.ID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		if err := json.NewEncoder(w).Encode(festivals); err != nil {
+			log.Printf("Error encoding festivals: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeBreweriesHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
 			return
 		}
 
-		_, err = time.Parse("2006-01-02", festival.EndDate)
+		query, err := parseBreweryQuery(r.URL.Query())
 		if err != nil {
-			http.Error(w, "Invalid end_date format. Expected YYYY-MM-DD", http.StatusBadRequest)
+			writeInvalidRequest(w, r, err)
 			return
 		}
 
-		createdFestival, err := db.CreateFestival(&festival)
+		breweries, total, err := db.ListBreweries(r.Context(), query)
 		if err != nil {
-			log.Printf("Error creating festival: %v", err)
-			http.Error(w, DefaultErrorMessage, http.StatusInternalServerError)
+			log.Printf("Error fetching breweries %s", err)
+			writeDatabaseError(w, r, err)
 			return
 		}
 
 		w.Header().Set(HeaderContentType, ContentTypeJSON)
-		w.WriteHeader(http.StatusCreated)
-		if err := json.NewEncoder(w).Encode(createdFestival); err != nil {
-			log.Printf("Error encoding created festival: %v", err)
-			http.Error(w, DefaultErrorMessage, http.StatusInternalServerError)
+		w.Header().Set(HeaderTotalCount, strconv.Itoa(total))
+		if err := json.NewEncoder(w).Encode(breweries); err != nil {
+			log.Printf("Error encoding breweries: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func validateFestival(festival *FestivalDB) error {
+	var validation ValidationError
+	if festival.Name == "" {
+		validation.Add("name", FieldCodeRequired, "name is required")
+	}
+
+	startDate := validateDate(&validation, "start_date", festival.StartDate)
+	endDate := validateDate(&validation, "end_date", festival.EndDate)
+	if !startDate.IsZero() && !endDate.IsZero() && endDate.Before(startDate) {
+		validation.Add("end_date", FieldCodeOutOfRange, "end_date must not be before start_date")
+	}
+
+	if !isValidLocation(Location{Latitude: festival.Latitude, Longitude: festival.Longitude}) {
+		if festival.Latitude < -90 || festival.Latitude > 90 {
+			validation.Add("latitude", FieldCodeOutOfRange, "Invalid latitude. Expected a number between -90 and 90")
+		}
+		if festival.Longitude < -180 || festival.Longitude > 180 {
+			validation.Add("longitude", FieldCodeOutOfRange, "Invalid longitude. Expected a number between -180 and 180")
+		}
+	}
+
+	return validation.Err()
+}
+
+func validateDate(validation *ValidationError, field, value string) time.Time {
+	if value == "" {
+		validation.Add(field, FieldCodeRequired, field+" is required")
+		return time.Time{}
+	}
+
+	date, err := ConvertTime(value)
+	if err != nil {
+		validation.Add(field, FieldCodeInvalid, "Invalid "+field+" format. Expected YYYY-MM-DD")
+		return time.Time{}
+	}
+	return date
+}
+
+func validateBrewery(brewery *BreweryDB) error {
+	var validation ValidationError
+	if strings.TrimSpace(brewery.Name) == "" {
+		validation.Add("name", FieldCodeRequired, "name is required")
+	}
+	if strings.TrimSpace(brewery.City) == "" {
+		validation.Add("city", FieldCodeRequired, "city is required")
+	}
+
+	if brewery.Website != "" && !isHTTPURL(brewery.Website) {
+		validation.Add("website", FieldCodeInvalid, "Invalid website URL. Expected an http or https URL")
+	}
+
+	if brewery.Logo != "" && !isHTTPURL(brewery.Logo) {
+		validation.Add("logo", FieldCodeInvalid, "Invalid logo URL. Expected an http or https URL")
+	}
+
+	return validation.Err()
+}
+
+func validateLineup(lineup *LineupRequest, requireBreweries bool) error {
+	if requireBreweries && len(lineup.BreweryIDs) == 0 {
+		return fieldError("brewery_ids", FieldCodeRequired, "brewery_ids must not be empty")
+	}
+
+	var validation ValidationError
+	seen := make(map[int64]bool, len(lineup.BreweryIDs))
+	for i, breweryID := range lineup.BreweryIDs {
+		field := fmt.Sprintf("brewery_ids[%d]", i)
+		if breweryID <= 0 {
+			validation.Add(field, FieldCodeInvalid, "Invalid brewery ID in brewery_ids")
+		} else if seen[breweryID] {
+			validation.Add(field, FieldCodeDuplicate, "Duplicate brewery ID in brewery_ids")
+		}
+		seen[breweryID] = true
+	}
+
+	return validation.Err()
+}
+
+func validateOwners(owners *OwnersRequest) error {
+	if len(owners.UserIDs) == 0 {
+		return fieldError("user_ids", FieldCodeRequired, "user_ids must not be empty")
+	}
+
+	var validation ValidationError
+	seen := make(map[string]bool, len(owners.UserIDs))
+	for i, userID := range owners.UserIDs {
+		field := fmt.Sprintf("user_ids[%d]", i)
+		if strings.TrimSpace(userID) == "" {
+			validation.Add(field, FieldCodeRequired, "Empty user ID in user_ids")
+		} else if seen[userID] {
+			validation.Add(field, FieldCodeDuplicate, "Duplicate user ID in user_ids")
+		}
+		seen[userID] = true
+	}
+
+	return validation.Err()
+}
+
+func makeCreateFestivalHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		var festival FestivalDB
+		if err := json.NewDecoder(r.Body).Decode(&festival); err != nil {
+			log.Printf("Error decoding request body: %v", err)
+			writeProblem(w, r, http.StatusBadRequest, ErrorCodeInvalidBody, "Invalid request body")
+			return
+		}
+
+		if err := validateFestival(&festival); err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		festival.CreatedBy = ""
+		if user, ok := userFromContext(r.Context()); ok {
+			festival.CreatedBy = user.ID
+		}
+
+		createdFestival, err := db.CreateFestival(r.Context(), &festival)
+		if err != nil {
+			log.Printf("Error creating festival: %v", err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(createdFestival); err != nil {
+			log.Printf("Error encoding created festival: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeBreweryHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		breweryID, ok := parsePathID(w, r, "id", "Brewery")
+		if !ok {
+			return
+		}
+
+		brewery, err := db.GetBrewery(r.Context(), breweryID)
+		if errors.Is(err, ErrNotFound) {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeBreweryNotFound, "Brewery not found")
+			return
+		}
+		if err != nil {
+			log.Printf("Error fetching brewery %d: %v", breweryID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		festivals, err := db.GetFestivalsByBrewery(r.Context(), breweryID)
+		if err != nil {
+			log.Printf("Error fetching festivals for brewery %d: %v", breweryID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		upcoming, past := splitFestivalsByDate(festivals, time.Now())
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		if err := json.NewEncoder(w).Encode(BreweryDetail{
+			Brewery:           *brewery,
+			UpcomingFestivals: upcoming,
+			PastFestivals:     past,
+		}); err != nil {
+			log.Printf("Error encoding brewery: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeCreateBreweryHandler(db DatabaseInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var brewery BreweryDB
+		if err := json.NewDecoder(r.Body).Decode(&brewery); err != nil {
+			log.Printf("Error decoding request body: %v", err)
+			writeProblem(w, r, http.StatusBadRequest, ErrorCodeInvalidBody, "Invalid request body")
+			return
+		}
+
+		if err := validateBrewery(&brewery); err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		createdBrewery, err := db.CreateBrewery(r.Context(), &brewery)
+		if err != nil {
+			log.Printf("Error creating brewery: %v", err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		w.WriteHeader(http.StatusCreated)
+		if err := json.NewEncoder(w).Encode(createdBrewery); err != nil {
+			log.Printf("Error encoding created brewery: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeUpdateBreweryHandler(db DatabaseInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		breweryID, ok := parsePathID(w, r, "id", "Brewery")
+		if !ok {
+			return
+		}
+
+		var brewery BreweryDB
+		if r.Method == "PATCH" {
+			existing, err := db.GetBrewery(r.Context(), breweryID)
+			if errors.Is(err, ErrNotFound) {
+				writeProblem(w, r, http.StatusNotFound, ErrorCodeBreweryNotFound, "Brewery not found")
+				return
+			}
+			if err != nil {
+				log.Printf("Error fetching brewery %d: %v", breweryID, err)
+				writeDatabaseError(w, r, err)
+				return
+			}
+			brewery = breweryToDB(*existing)
+		}
+
+		if err := json.NewDecoder(r.Body).Decode(&brewery); err != nil {
+			log.Printf("Error decoding request body: %v", err)
+			writeProblem(w, r, http.StatusBadRequest, ErrorCodeInvalidBody, "Invalid request body")
+			return
+		}
+
+		if err := validateBrewery(&brewery); err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		updatedBrewery, err := db.UpdateBrewery(r.Context(), breweryID, &brewery)
+		if errors.Is(err, ErrNotFound) {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeBreweryNotFound, "Brewery not found")
+			return
+		}
+		if err != nil {
+			log.Printf("Error updating brewery %d: %v", breweryID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		if err := json.NewEncoder(w).Encode(updatedBrewery); err != nil {
+			log.Printf("Error encoding updated brewery: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeDeleteBreweryHandler(db DatabaseInterface) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		breweryID, ok := parsePathID(w, r, "id", "Brewery")
+		if !ok {
+			return
+		}
+
+		cascade, err := parseBoolParam(r.URL.Query(), "cascade")
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		err = db.DeleteBrewery(r.Context(), breweryID, cascade)
+		if errors.Is(err, ErrNotFound) {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeBreweryNotFound, "Brewery not found")
+			return
+		}
+		if errors.Is(err, ErrConflict) {
+			writeProblem(w, r, http.StatusConflict, ErrorCodeBreweryInUse, "Brewery is still linked to festivals. Retry with ?cascade=true to unlink it")
+			return
+		}
+		if err != nil {
+			log.Printf("Error deleting brewery %d: %v", breweryID, err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func parseCoordinate(values url.Values, name string, limit float64) (float64, error) {
+	value := values.Get(name)
+	if value == "" {
+		return 0, fieldError(name, FieldCodeRequired, name+" is required")
+	}
+
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(parsed) || math.Abs(parsed) > limit {
+		return 0, fieldError(name, FieldCodeOutOfRange, fmt.Sprintf("Invalid %s. Expected a number between -%g and %g", name, limit, limit))
+	}
+	return parsed, nil
+}
+
+func makeNearbyFestivalsHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		values := r.URL.Query()
+		latitude, err := parseCoordinate(values, "lat", 90)
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		longitude, err := parseCoordinate(values, "lon", 180)
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		radiusKm := DefaultNearbyRadiusKm
+		if value := values.Get("radius_km"); value != "" {
+			radiusKm, err = strconv.ParseFloat(value, 64)
+			if err != nil || !(radiusKm > 0 && radiusKm <= MaxNearbyRadiusKm) {
+				writeInvalidRequest(w, r, fieldError("radius_km", FieldCodeOutOfRange,
+					fmt.Sprintf("Invalid radius_km. Expected a number between 0 and %g", MaxNearbyRadiusKm)))
+				return
+			}
+		}
+
+		query, err := parseFestivalQuery(values)
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		limit, offset := query.Limit, query.Offset
+		query.Limit, query.Offset = 0, 0
+
+		festivals, _, err := db.ListFestivals(r.Context(), query)
+		if err != nil {
+			log.Printf("Error fetching festivals from database: %v", err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		nearby := festivalsNear(festivals, Location{Latitude: latitude, Longitude: longitude}, radiusKm)
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		w.Header().Set(HeaderTotalCount, strconv.Itoa(len(nearby)))
+		if err := json.NewEncoder(w).Encode(paginate(nearby, limit, offset)); err != nil {
+			log.Printf("Error encoding nearby festivals: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeFestivalMapHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		values := r.URL.Query()
+		bounds, err := parseBoundingBox(values.Get("bbox"))
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		zoom, err := strconv.Atoi(values.Get("zoom"))
+		if err != nil || zoom < 0 || zoom > MaxMapZoom {
+			writeInvalidRequest(w, r, fieldError("zoom", FieldCodeOutOfRange,
+				fmt.Sprintf("Invalid zoom. Expected a number between 0 and %d", MaxMapZoom)))
+			return
+		}
+
+		query, err := parseFestivalQuery(values)
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+		query.Bounds = bounds
+		query.Limit, query.Offset = 0, 0
+
+		festivals, _, err := db.ListFestivals(r.Context(), query)
+		if err != nil {
+			log.Printf("Error fetching festivals from database: %v", err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		if err := json.NewEncoder(w).Encode(clusterFestivals(festivals, zoom)); err != nil {
+			log.Printf("Error encoding festival map: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeFestivalsGeoJSONHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		query, err := parseFestivalQuery(r.URL.Query())
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		festivals, total, err := db.ListFestivals(r.Context(), query)
+		if err != nil {
+			log.Printf("Error fetching festivals from database: %v", err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		collection := festivalsToGeoJSON(festivals)
+
+		w.Header().Set(HeaderContentType, ContentTypeGeoJSON)
+		w.Header().Set(HeaderTotalCount, strconv.Itoa(total))
+		if err := json.NewEncoder(w).Encode(collection); err != nil {
+			log.Printf("Error encoding festivals GeoJSON: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeFestivalsICalHandler(db DatabaseInterface, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		query, err := parseFestivalQuery(r.URL.Query())
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		festivals, total, err := db.ListFestivals(r.Context(), query)
+		if err != nil {
+			log.Printf("Error fetching festivals from database: %v", err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeICal)
+		w.Header().Set(HeaderTotalCount, strconv.Itoa(total))
+		if _, err := io.WriteString(w, festivalsToICal(festivals, time.Now())); err != nil {
+			log.Printf("Error writing festivals calendar: %v", err)
+		}
+	}
+}
+
+func makeFeedHandler(db DatabaseInterface, allowedOrigins, frontendURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		name, format, ok := parseFeedPath(r.PathValue("feed"))
+		if !ok {
+			writeProblem(w, r, http.StatusNotFound, ErrorCodeFeedNotFound, "Feed not found")
+			return
+		}
+
+		feed := festivalFeeds[name]
+		festivals, _, err := db.ListFestivals(r.Context(), feed.query)
+		if err != nil {
+			log.Printf("Error fetching festivals from database: %v", err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		var document any
+		contentType := ContentTypeRSS
+		if format == "atom" {
+			contentType = ContentTypeAtom
+			document = festivalsToAtom(festivals, name, feed.title, frontendURL, time.Now())
+		} else {
+			document = festivalsToRSS(festivals, feed.title, frontendURL, time.Now())
+		}
+
+		var buf bytes.Buffer
+		buf.WriteString(xml.Header)
+		if err := xml.NewEncoder(&buf).Encode(document); err != nil {
+			log.Printf("Error encoding %s feed: %v", name, err)
+			writeInternalError(w, r)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, contentType)
+		if _, err := buf.WriteTo(w); err != nil {
+			log.Printf("Error writing %s feed: %v", name, err)
+		}
+	}
+}
+
+func makeSearchHandler(index *SearchIndex, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		query, err := parseSearchQuery(r.URL.Query())
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		hits, err := index.Search(r.Context(), query)
+		if err != nil {
+			log.Printf("Error searching festivals and breweries: %v", err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		w.Header().Set(HeaderTotalCount, strconv.Itoa(len(hits)))
+		if err := json.NewEncoder(w).Encode(paginate(hits, query.Limit, query.Offset)); err != nil {
+			log.Printf("Error encoding search results: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeSuggestHandler(index *SearchIndex, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		query, err := parseSuggestQuery(r.URL.Query())
+		if err != nil {
+			writeInvalidRequest(w, r, err)
+			return
+		}
+
+		suggestions, err := index.Suggest(r.Context(), query)
+		if err != nil {
+			log.Printf("Error building suggestions: %v", err)
+			writeDatabaseError(w, r, err)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		if err := json.NewEncoder(w).Encode(suggestions); err != nil {
+			log.Printf("Error encoding suggestions: %v", err)
+			writeInternalError(w, r)
+			return
+		}
+	}
+}
+
+func makeOpenAPIHandler(document OpenAPIDocument, allowedOrigins string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		enableCORS(w, r, allowedOrigins)
+
+		if r.Method == "OPTIONS" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		w.Header().Set(HeaderContentType, ContentTypeJSON)
+		if err := json.NewEncoder(w).Encode(document); err != nil {
+			log.Printf("Error encoding OpenAPI document: %v", err)
+			writeInternalError(w, r)
 			return
 		}
 	}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var icalTextEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`)
+
+func icalText(value string) string {
+	return icalTextEscaper.Replace(value)
+}
+
+func icalDate(date time.Time) string {
+	return date.Format(ICalDateFormat)
+}
+
+func writeICalLine(b *strings.Builder, line string) {
+	limit := ICalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = ICalLineLimit - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+func festivalLocation(festival Festival) string {
+	parts := []string{}
+	for _, part := range []string{festival.City, festival.Region} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func festivalsToICal(festivals []Festival, now time.Time) string {
+	var b strings.Builder
+	writeICalLine(&b, "BEGIN:VCALENDAR")
+	writeICalLine(&b, "VERSION:2.0")
+	writeICalLine(&b, "PRODID:"+ICalProductID)
+	writeICalLine(&b, "CALSCALE:GREGORIAN")
+	writeICalLine(&b, "METHOD:PUBLISH")
+	writeICalLine(&b, "X-WR-CALNAME:"+icalText(ICalCalendarName))
+
+	stamp := now.UTC().Format(ICalTimestampFormat)
+	for _, festival := range festivals {
+		writeICalLine(&b, "BEGIN:VEVENT")
+		writeICalLine(&b, fmt.Sprintf("UID:festival-%d@%s", festival.ID, ICalUIDDomain))
+		writeICalLine(&b, "DTSTAMP:"+stamp)
+		writeICalLine(&b, "DTSTART;VALUE=DATE:"+icalDate(festival.StartDate))
+		writeICalLine(&b, "DTEND;VALUE=DATE:"+icalDate(festival.EndDate.AddDate(0, 0, 1)))
+		writeICalLine(&b, "SUMMARY:"+icalText(festival.Name))
+		if festival.Description != "" {
+			writeICalLine(&b, "DESCRIPTION:"+icalText(festival.Description))
+		}
+		if location := festivalLocation(festival); location != "" {
+			writeICalLine(&b, "LOCATION:"+icalText(location))
+		}
+		if hasLocation(festival.Location) && isValidLocation(festival.Location) {
+			writeICalLine(&b, fmt.Sprintf("GEO:%g;%g", festival.Location.Latitude, festival.Location.Longitude))
+		}
+		if festival.Website != "" {
+			writeICalLine(&b, "URL:"+festival.Website)
+		}
+		if festival.Cancelled {
+			writeICalLine(&b, "STATUS:CANCELLED")
+		} else {
+			writeICalLine(&b, "STATUS:CONFIRMED")
+		}
+		writeICalLine(&b, "END:VEVENT")
+	}
+
+	writeICalLine(&b, "END:VCALENDAR")
+	return b.String()
+}
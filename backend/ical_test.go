@@ -0,0 +1,83 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFestivalsToICal(t *testing.T) {
+	now := time.Date(2025, 9, 1, 12, 30, 0, 0, time.UTC)
+	festivals := []Festival{
+		{
+			ID:          7,
+			Name:        "Rennes Craft, Bière & Co",
+			Description: "Deux jours\nde dégustation",
+			StartDate:   time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:     time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC),
+			City:        "Rennes",
+			Region:      "Bretagne",
+			Location:    Location{Latitude: 48.1173, Longitude: -1.6778},
+			Website:     "https://example.com",
+		},
+		{
+			ID:        8,
+			Name:      "Cancelled Fest",
+			StartDate: time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			EndDate:   time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC),
+			Cancelled: true,
+		},
+	}
+
+	calendar := festivalsToICal(festivals, now)
+
+	for _, line := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:festival-7@beer-festival\r\n",
+		"DTSTAMP:20250901T123000Z\r\n",
+		"DTSTART;VALUE=DATE:20251101\r\n",
+		"DTEND;VALUE=DATE:20251103\r\n",
+		"SUMMARY:Rennes Craft\\, Bière & Co\r\n",
+		"DESCRIPTION:Deux jours\\nde dégustation\r\n",
+		"LOCATION:Rennes\\, Bretagne\r\n",
+		"GEO:48.1173;-1.6778\r\n",
+		"URL:https://example.com\r\n",
+		"DTEND;VALUE=DATE:20260101\r\n",
+		"STATUS:CANCELLED\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(calendar, line) {
+			t.Errorf("Expected calendar to contain %q, got:\n%s", line, calendar)
+		}
+	}
+
+	if strings.Count(calendar, "BEGIN:VEVENT") != 2 || strings.Count(calendar, "GEO:") != 1 {
+		t.Errorf("Expected two events and one GEO, got:\n%s", calendar)
+	}
+}
+
+func TestWriteICalLine(t *testing.T) {
+	var b strings.Builder
+	writeICalLine(&b, "DESCRIPTION:"+strings.Repeat("é", 60))
+
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\r\n"), "\r\n")
+	if len(lines) < 2 {
+		t.Fatalf("Expected folded lines, got %q", b.String())
+	}
+
+	unfolded := lines[0]
+	for _, line := range lines {
+		if len(line) > ICalLineLimit {
+			t.Errorf("Expected at most %d octets, got %d", ICalLineLimit, len(line))
+		}
+		if !strings.HasPrefix(line, "DESCRIPTION:") && !strings.HasPrefix(line, " ") {
+			t.Errorf("Expected continuation line to start with a space, got %q", line)
+		}
+	}
+	for _, line := range lines[1:] {
+		unfolded += line[1:]
+	}
+	if unfolded != "DESCRIPTION:"+strings.Repeat("é", 60) {
+		t.Errorf("Expected unfolded line to round-trip, got %q", unfolded)
+	}
+}
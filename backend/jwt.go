@@ -0,0 +1,386 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtAudience []string
+
+func (a *jwtAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = jwtAudience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+type jwtClaims struct {
+	Subject   string      `json:"sub"`
+	Email     string      `json:"email"`
+	Issuer    string      `json:"iss"`
+	Audience  jwtAudience `json:"aud"`
+	ExpiresAt int64       `json:"exp"`
+	NotBefore int64       `json:"nbf"`
+	AppMeta   appMetadata `json:"app_metadata"`
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+type JWTVerifier struct {
+	secret     []byte
+	jwksURL    string
+	audience   string
+	issuer     string
+	httpClient *http.Client
+	now        func() time.Time
+
+	mu         sync.RWMutex
+	keys       map[string]crypto.PublicKey
+	fetchedAt  time.Time
+	failedAt   time.Time
+	refreshing chan struct{}
+}
+
+func NewJWTVerifier(secret, jwksSource, audience, issuer string) (*JWTVerifier, error) {
+	if secret == "" && jwksSource == "" {
+		return nil, nil
+	}
+
+	verifier := &JWTVerifier{
+		secret:     []byte(secret),
+		audience:   audience,
+		issuer:     issuer,
+		httpClient: &http.Client{Timeout: SupabaseClientTimeout},
+		now:        time.Now,
+		keys:       map[string]crypto.PublicKey{},
+	}
+
+	if jwksSource == "" {
+		return verifier, nil
+	}
+
+	if isHTTPURL(jwksSource) {
+		verifier.jwksURL = jwksSource
+		if err := verifier.refreshKeys(context.Background()); err != nil {
+			log.Printf("Failed to load JWKS from %s, retrying on the next unknown key after %s: %v", jwksSource, JWKSRetryInterval, err)
+		}
+		return verifier, nil
+	}
+
+	data, err := os.ReadFile(jwksSource)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+	if verifier.keys, err = parseJWKS(data); err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
+
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*User, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidToken)
+	}
+
+	if err := v.verifySignature(ctx, header, parts[0]+"."+parts[1], signature); err != nil {
+		return nil, err
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return nil, err
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return &User{ID: claims.Subject, Email: claims.Email, Roles: normalizeRoles(claims.AppMeta.Roles)}, nil
+}
+
+func (v *JWTVerifier) verifySignature(ctx context.Context, header jwtHeader, signed string, signature []byte) error {
+	digest := sha256.Sum256([]byte(signed))
+
+	switch header.Alg {
+	case "HS256":
+		if len(v.secret) == 0 {
+			return fmt.Errorf("%w: HS256 tokens are not accepted", ErrInvalidToken)
+		}
+		mac := hmac.New(sha256.New, v.secret)
+		mac.Write([]byte(signed))
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	case "RS256":
+		key, ok := v.publicKey(ctx, header.Kid).(*rsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: no RSA key for kid %q", ErrInvalidToken, header.Kid)
+		}
+		if rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature) != nil {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	case "ES256":
+		key, ok := v.publicKey(ctx, header.Kid).(*ecdsa.PublicKey)
+		if !ok {
+			return fmt.Errorf("%w: no EC key for kid %q", ErrInvalidToken, header.Kid)
+		}
+		if len(signature) != 64 {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+		if !ecdsa.Verify(key, digest[:], r, s) {
+			return fmt.Errorf("%w: bad signature", ErrInvalidToken)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrInvalidToken, header.Alg)
+	}
+}
+
+func (v *JWTVerifier) validateClaims(claims jwtClaims) error {
+	now := v.now()
+
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(JWTLeeway)) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+
+	if claims.NotBefore != 0 && now.Add(JWTLeeway).Before(time.Unix(claims.NotBefore, 0)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+
+	if v.issuer != "" && claims.Issuer != v.issuer {
+		return fmt.Errorf("%w: unexpected issuer %q", ErrInvalidToken, claims.Issuer)
+	}
+
+	if v.audience != "" && !slices.Contains(claims.Audience, v.audience) {
+		return fmt.Errorf("%w: unexpected audience %v", ErrInvalidToken, []string(claims.Audience))
+	}
+
+	if claims.Subject == "" {
+		return fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+func (v *JWTVerifier) publicKey(ctx context.Context, kid string) crypto.PublicKey {
+	if key := v.lookupKey(kid); key != nil {
+		return key
+	}
+
+	if v.jwksURL == "" {
+		return nil
+	}
+
+	v.mu.RLock()
+	now := v.now()
+	stale := now.Sub(v.fetchedAt) > JWKSRefreshInterval && now.Sub(v.failedAt) > JWKSRetryInterval
+	v.mu.RUnlock()
+	if !stale {
+		return nil
+	}
+
+	select {
+	case <-v.startRefresh(ctx):
+		return v.lookupKey(kid)
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func (v *JWTVerifier) startRefresh(ctx context.Context) <-chan struct{} {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.refreshing != nil {
+		return v.refreshing
+	}
+
+	done := make(chan struct{})
+	v.refreshing = done
+	go func() {
+		defer close(done)
+		if err := v.refreshKeys(context.WithoutCancel(ctx)); err != nil {
+			log.Printf("Failed to refresh JWKS from %s: %v", v.jwksURL, err)
+		}
+
+		v.mu.Lock()
+		v.refreshing = nil
+		v.mu.Unlock()
+	}()
+	return done
+}
+
+func (v *JWTVerifier) lookupKey(kid string) crypto.PublicKey {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if kid != "" {
+		return v.keys[kid]
+	}
+
+	if len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key
+		}
+	}
+	return nil
+}
+
+func (v *JWTVerifier) refreshKeys(ctx context.Context) error {
+	keys, err := v.fetchKeys(ctx)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if err != nil {
+		v.failedAt = v.now()
+		return err
+	}
+
+	v.keys = keys
+	v.fetchedAt = v.now()
+	return nil
+}
+
+func (v *JWTVerifier) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.jwksURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("JWKS request returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS: %w", err)
+	}
+
+	return parseJWKS(data)
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set jsonWebKeySet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := jwk.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWKS key %q: %w", jwk.Kid, err)
+		}
+		if key != nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	return keys, nil
+}
+
+func (jwk jsonWebKey) publicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		if jwk.Crv != "P-256" {
+			return nil, nil
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, nil
+	}
+}
+
+func decodeJWTPart(part string, target any) error {
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	if err := json.Unmarshal(data, target); err != nil {
+		return fmt.Errorf("%w: malformed token", ErrInvalidToken)
+	}
+	return nil
+}
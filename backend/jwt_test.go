@@ -0,0 +1,392 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	testJWTSecret   = "test-jwt-secret"
+	testJWTIssuer   = "https://test.supabase.co/auth/v1"
+	testJWTAudience = "authenticated"
+)
+
+func testClaims(now time.Time) map[string]any {
+	return map[string]any{
+		"sub":   "user-123",
+		"email": "admin@example.com",
+		"iss":   testJWTIssuer,
+		"aud":   testJWTAudience,
+		"exp":   now.Add(time.Hour).Unix(),
+		"iat":   now.Unix(),
+	}
+}
+
+func mintToken(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+
+	header := map[string]string{"alg": alg, "typ": "JWT"}
+	if kid != "" {
+		header["kid"] = kid
+	}
+
+	encode := func(value any) string {
+		data, err := json.Marshal(value)
+		if err != nil {
+			t.Fatalf("Failed to encode token part: %v", err)
+		}
+		return base64.RawURLEncoding.EncodeToString(data)
+	}
+
+	signed := encode(header) + "." + encode(claims)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signed))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:]); err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	case nil:
+	default:
+		t.Fatalf("Unsupported key type %T", key)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func testJWKS(t *testing.T, rsaKid string, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) []byte {
+	t.Helper()
+
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+
+	data, err := json.Marshal(jsonWebKeySet{Keys: []jsonWebKey{
+		{Kty: "RSA", Kid: rsaKid, N: encode(rsaKey.N), E: encode(big.NewInt(int64(rsaKey.E)))},
+		{Kty: "EC", Kid: "ec-key", Crv: "P-256", X: encode(ecKey.X), Y: encode(ecKey.Y)},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to encode JWKS: %v", err)
+	}
+	return data
+}
+
+func newTestKeys(t *testing.T) (*rsa.PrivateKey, *ecdsa.PrivateKey) {
+	t.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate EC key: %v", err)
+	}
+	return rsaKey, ecKey
+}
+
+func TestNewJWTVerifier(t *testing.T) {
+	t.Run("is disabled without a secret or JWKS", func(t *testing.T) {
+		verifier, err := NewJWTVerifier("", "", testJWTAudience, testJWTIssuer)
+		if err != nil || verifier != nil {
+			t.Errorf("Expected no verifier, got %v, %v", verifier, err)
+		}
+	})
+
+	t.Run("fails on a missing JWKS file", func(t *testing.T) {
+		if _, err := NewJWTVerifier("", filepath.Join(t.TempDir(), "missing.json"), testJWTAudience, testJWTIssuer); err == nil {
+			t.Error("Expected error for missing JWKS file")
+		}
+	})
+}
+
+func TestJWTVerifierHS256(t *testing.T) {
+	now := time.Now()
+	verifier, err := NewJWTVerifier(testJWTSecret, "", testJWTAudience, testJWTIssuer)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	t.Run("builds the user from the claims", func(t *testing.T) {
+		user, err := verifier.Verify(context.Background(), mintToken(t, "HS256", "", []byte(testJWTSecret), testClaims(now)))
+		if err != nil {
+			t.Fatalf("Expected valid token, got %v", err)
+		}
+
+		if user.ID != "user-123" || user.Email != "admin@example.com" {
+			t.Errorf("Expected user-123 admin@example.com, got %+v", user)
+		}
+	})
+
+	t.Run("reads roles from the app metadata", func(t *testing.T) {
+		claims := testClaims(now)
+		claims["app_metadata"] = map[string]any{"roles": []string{"organizer", "unknown"}}
+
+		user, err := verifier.Verify(context.Background(), mintToken(t, "HS256", "", []byte(testJWTSecret), claims))
+		if err != nil {
+			t.Fatalf("Expected valid token, got %v", err)
+		}
+
+		if !slices.Equal(user.Roles, []string{RoleOrganizer}) {
+			t.Errorf("Expected roles [organizer], got %v", user.Roles)
+		}
+	})
+
+	t.Run("accepts an audience list", func(t *testing.T) {
+		claims := testClaims(now)
+		claims["aud"] = []string{"other", testJWTAudience}
+
+		if _, err := verifier.Verify(context.Background(), mintToken(t, "HS256", "", []byte(testJWTSecret), claims)); err != nil {
+			t.Errorf("Expected valid token, got %v", err)
+		}
+	})
+
+	tests := []struct {
+		name   string
+		alg    string
+		key    any
+		change func(claims map[string]any)
+	}{
+		{"wrong secret", "HS256", []byte("other-secret"), nil},
+		{"unsigned token", "none", nil, nil},
+		{"expired token", "HS256", []byte(testJWTSecret), func(claims map[string]any) {
+			claims["exp"] = now.Add(-time.Hour).Unix()
+		}},
+		{"missing expiry", "HS256", []byte(testJWTSecret), func(claims map[string]any) {
+			delete(claims, "exp")
+		}},
+		{"token not valid yet", "HS256", []byte(testJWTSecret), func(claims map[string]any) {
+			claims["nbf"] = now.Add(time.Hour).Unix()
+		}},
+		{"wrong audience", "HS256", []byte(testJWTSecret), func(claims map[string]any) {
+			claims["aud"] = "anon"
+		}},
+		{"wrong issuer", "HS256", []byte(testJWTSecret), func(claims map[string]any) {
+			claims["iss"] = "https://other.supabase.co/auth/v1"
+		}},
+		{"missing subject", "HS256", []byte(testJWTSecret), func(claims map[string]any) {
+			delete(claims, "sub")
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			claims := testClaims(now)
+			if tt.change != nil {
+				tt.change(claims)
+			}
+
+			_, err := verifier.Verify(context.Background(), mintToken(t, tt.alg, "", tt.key, claims))
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Expected ErrInvalidToken, got %v", err)
+			}
+		})
+	}
+
+	t.Run("rejects malformed tokens", func(t *testing.T) {
+		for _, token := range []string{"", "not-a-jwt", "a.b.c", "a.b"} {
+			if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("%q: Expected ErrInvalidToken, got %v", token, err)
+			}
+		}
+	})
+}
+
+func TestJWTVerifierJWKS(t *testing.T) {
+	now := time.Now()
+	rsaKey, ecKey := newTestKeys(t)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, testJWKS(t, "rsa-key", rsaKey, ecKey), 0o600); err != nil {
+		t.Fatalf("Failed to write JWKS: %v", err)
+	}
+
+	verifier, err := NewJWTVerifier("", path, testJWTAudience, testJWTIssuer)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	t.Run("verifies RS256 tokens", func(t *testing.T) {
+		if _, err := verifier.Verify(context.Background(), mintToken(t, "RS256", "rsa-key", rsaKey, testClaims(now))); err != nil {
+			t.Errorf("Expected valid token, got %v", err)
+		}
+	})
+
+	t.Run("verifies ES256 tokens", func(t *testing.T) {
+		if _, err := verifier.Verify(context.Background(), mintToken(t, "ES256", "ec-key", ecKey, testClaims(now))); err != nil {
+			t.Errorf("Expected valid token, got %v", err)
+		}
+	})
+
+	t.Run("rejects HS256 tokens without a secret", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), mintToken(t, "HS256", "", []byte(""), testClaims(now)))
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken, got %v", err)
+		}
+	})
+
+	t.Run("rejects tokens signed with another key", func(t *testing.T) {
+		otherRSA, otherEC := newTestKeys(t)
+		for _, token := range []string{
+			mintToken(t, "RS256", "rsa-key", otherRSA, testClaims(now)),
+			mintToken(t, "ES256", "ec-key", otherEC, testClaims(now)),
+			mintToken(t, "RS256", "ec-key", rsaKey, testClaims(now)),
+			mintToken(t, "RS256", "unknown", rsaKey, testClaims(now)),
+		} {
+			if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Expected ErrInvalidToken, got %v", err)
+			}
+		}
+	})
+}
+
+func TestJWTVerifierJWKSURL(t *testing.T) {
+	now := time.Now()
+	rsaKey, ecKey := newTestKeys(t)
+	rotatedRSA, _ := newTestKeys(t)
+
+	jwks := testJWKS(t, "rsa-key", rsaKey, ecKey)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write(jwks)
+	}))
+	t.Cleanup(server.Close)
+
+	verifier, err := NewJWTVerifier("", server.URL, testJWTAudience, testJWTIssuer)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+
+	if _, err := verifier.Verify(context.Background(), mintToken(t, "RS256", "rsa-key", rsaKey, testClaims(now))); err != nil {
+		t.Fatalf("Expected valid token, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the JWKS to be fetched once, got %d", requests)
+	}
+
+	jwks = testJWKS(t, "rsa-key-2", rotatedRSA, ecKey)
+	if _, err := verifier.Verify(context.Background(), mintToken(t, "RS256", "rsa-key-2", rotatedRSA, testClaims(now))); err == nil {
+		t.Error("Expected rotated key to be unknown before the refresh interval")
+	}
+	if requests != 1 {
+		t.Errorf("Expected no refetch within the refresh interval, got %d requests", requests)
+	}
+
+	verifier.now = func() time.Time { return now.Add(JWKSRefreshInterval + time.Minute) }
+	if _, err := verifier.Verify(context.Background(), mintToken(t, "RS256", "rsa-key-2", rotatedRSA, testClaims(verifier.now()))); err != nil {
+		t.Errorf("Expected rotated key after refresh, got %v", err)
+	}
+}
+
+func TestJWTVerifierJWKSURLRetry(t *testing.T) {
+	now := time.Now()
+	rsaKey, ecKey := newTestKeys(t)
+	jwks := testJWKS(t, "rsa-key", rsaKey, ecKey)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write(jwks)
+	}))
+	t.Cleanup(server.Close)
+
+	verifier, err := NewJWTVerifier("", server.URL, testJWTAudience, testJWTIssuer)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	verifier.now = func() time.Time { return now }
+
+	token := mintToken(t, "RS256", "rsa-key", rsaKey, testClaims(now))
+	if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Expected ErrInvalidToken while backing off, got %v", err)
+	}
+	if requests.Load() != 1 {
+		t.Errorf("Expected no refetch during the back-off, got %d requests", requests.Load())
+	}
+
+	verifier.now = func() time.Time { return now.Add(JWKSRetryInterval + time.Second) }
+	if _, err := verifier.Verify(context.Background(), token); err != nil {
+		t.Errorf("Expected the key once the JWKS is fetched, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected one refetch after the failure, got %d requests", requests.Load())
+	}
+}
+
+func TestJWTVerifierJWKSRefreshIsDetached(t *testing.T) {
+	now := time.Now()
+	rsaKey, ecKey := newTestKeys(t)
+	jwks := testJWKS(t, "rsa-key", rsaKey, ecKey)
+
+	release := make(chan struct{})
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		<-release
+		w.Write(jwks)
+	}))
+	t.Cleanup(server.Close)
+
+	verifier, err := NewJWTVerifier("", server.URL, testJWTAudience, testJWTIssuer)
+	if err != nil {
+		t.Fatalf("Failed to create verifier: %v", err)
+	}
+	verifier.now = func() time.Time { return now.Add(JWKSRetryInterval + time.Second) }
+
+	token := mintToken(t, "RS256", "rsa-key", rsaKey, testClaims(now))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := verifier.Verify(ctx, token); err == nil {
+		t.Error("Expected the cancelled request to give up on the key")
+	}
+
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for verifier.lookupKey("rsa-key") == nil && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if _, err := verifier.Verify(context.Background(), token); err != nil {
+		t.Errorf("Expected the detached refresh to store the key, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("Expected the refresh to run once, got %d requests", requests.Load())
+	}
+}
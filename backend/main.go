@@ -13,23 +13,19 @@ import (
 func main() {
 	config := getConfig()
 
-	db, err := NewDatabase(config.SupabaseURL, config.SupabaseKey)
+	db, err := openDatabase(config)
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
 
-	log.Println("Successfully connected to Supabase")
+	log.Printf("Successfully connected to %s database", config.DatabaseDriver)
 
-	mux := http.NewServeMux()
-	mux.HandleFunc(HealthPath, healthCheckHandler)
-	mux.HandleFunc(FestivalsPath, makeFestivalsHandler(db, config.AllowedOrigins))
-	mux.HandleFunc(CreateFestivalPath, makeCreateFestivalHandler(db, config.AllowedOrigins))
-	mux.HandleFunc(FestivalsBreweriesPath, makeFestivalBreweriesHandler(db, config.AllowedOrigins))
-	mux.HandleFunc(BreweriesPath, makeBreweriesHandler(db, config.AllowedOrigins))
-	mux.HandleFunc(LoginPath, makeLoginHandler(db, config.AllowedOrigins))
-	mux.HandleFunc(VerifyPath, makeVerifyHandler(db, config.AllowedOrigins))
+	if config.CacheTTL > 0 {
+		db = NewCachedDatabase(db, config.CacheTTL)
+		log.Printf("Caching festival and brewery reads for %v", config.CacheTTL)
+	}
 
-	handler := chainMiddleware(mux, requestIDMiddleware, metricsMiddleware, gzipMiddleware)
+	handler := chainMiddleware(newRouter(db, config), requestIDMiddleware, timeoutMiddleware(config.RequestTimeout), metricsMiddleware, gzipMiddleware)
 
 	server := &http.Server{
 		Addr:         ":" + config.Port,
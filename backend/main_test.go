@@ -2,10 +2,15 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -35,6 +40,43 @@ func TestGetConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("reads JWT verification settings", func(t *testing.T) {
+		os.Setenv("SUPABASE_URL", "https://test.supabase.co/")
+		os.Setenv("SUPABASE_JWT_SECRET", "secret")
+		os.Setenv("SUPABASE_JWKS", "jwks.json")
+		os.Setenv("JWT_REMOTE_FALLBACK", "true")
+		defer os.Clearenv()
+
+		config := getConfig()
+
+		if config.JWTSecret != "secret" || config.JWKSSource != "jwks.json" || !config.JWTRemoteFallback {
+			t.Errorf("Expected JWT settings from the environment, got %+v", config)
+		}
+		if config.JWTIssuer != "https://test.supabase.co/auth/v1" {
+			t.Errorf("Expected issuer derived from SUPABASE_URL, got %s", config.JWTIssuer)
+		}
+		if config.JWTAudience != DefaultJWTAudience {
+			t.Errorf("Expected default audience %s, got %s", DefaultJWTAudience, config.JWTAudience)
+		}
+	})
+
+	t.Run("reads the roles source", func(t *testing.T) {
+		defer os.Clearenv()
+
+		tests := map[string]string{
+			"":             RolesSourceAppMetadata,
+			"table":        RolesSourceTable,
+			"app_metadata": RolesSourceAppMetadata,
+			"ldap":         RolesSourceAppMetadata,
+		}
+		for value, expected := range tests {
+			os.Setenv("USER_ROLES_SOURCE", value)
+			if source := getConfig().RolesSource; source != expected {
+				t.Errorf("USER_ROLES_SOURCE=%q: Expected %s, got %s", value, expected, source)
+			}
+		}
+	})
+
 	t.Run("returns empty strings when env vars not set", func(t *testing.T) {
 		os.Clearenv()
 		config := getConfig()
@@ -51,6 +93,124 @@ func TestGetConfig(t *testing.T) {
 		if config.SupabaseKey != "" {
 			t.Errorf("Expected empty Supabase key, got %s", config.SupabaseKey)
 		}
+		if config.DatabaseDriver != "supabase" {
+			t.Errorf("Expected default database driver supabase, got %s", config.DatabaseDriver)
+		}
+		if config.SQLitePath != "beer-festival.db" {
+			t.Errorf("Expected default SQLite path beer-festival.db, got %s", config.SQLitePath)
+		}
+		if config.SeedPath != "seed.json" {
+			t.Errorf("Expected default seed path seed.json, got %s", config.SeedPath)
+		}
+		if config.CacheTTL != time.Minute {
+			t.Errorf("Expected default cache TTL 1m, got %v", config.CacheTTL)
+		}
+		if config.FrontendURL != "http://localhost:5173" {
+			t.Errorf("Expected default frontend URL http://localhost:5173, got %s", config.FrontendURL)
+		}
+	})
+
+	t.Run("reads frontend URL without trailing slash", func(t *testing.T) {
+		os.Setenv("FRONTEND_URL", "https://festivals.example.com/")
+		defer os.Clearenv()
+
+		config := getConfig()
+
+		if config.FrontendURL != "https://festivals.example.com" {
+			t.Errorf("Expected frontend URL https://festivals.example.com, got %s", config.FrontendURL)
+		}
+	})
+
+	t.Run("parses cache TTL", func(t *testing.T) {
+		os.Setenv("CACHE_TTL", "30s")
+		defer os.Clearenv()
+
+		config := getConfig()
+
+		if config.CacheTTL != 30*time.Second {
+			t.Errorf("Expected cache TTL 30s, got %v", config.CacheTTL)
+		}
+	})
+
+	t.Run("falls back to default cache TTL when invalid", func(t *testing.T) {
+		os.Setenv("CACHE_TTL", "soon")
+		defer os.Clearenv()
+
+		config := getConfig()
+
+		if config.CacheTTL != time.Minute {
+			t.Errorf("Expected default cache TTL 1m, got %v", config.CacheTTL)
+		}
+	})
+
+	t.Run("parses request timeout", func(t *testing.T) {
+		os.Setenv("REQUEST_TIMEOUT", "5s")
+		defer os.Clearenv()
+
+		config := getConfig()
+
+		if config.RequestTimeout != 5*time.Second {
+			t.Errorf("Expected request timeout 5s, got %v", config.RequestTimeout)
+		}
+	})
+
+	t.Run("reads database driver settings", func(t *testing.T) {
+		os.Setenv("DATABASE_DRIVER", "sqlite")
+		os.Setenv("SQLITE_PATH", "/tmp/festivals.db")
+		defer os.Clearenv()
+
+		config := getConfig()
+
+		if config.DatabaseDriver != "sqlite" {
+			t.Errorf("Expected database driver sqlite, got %s", config.DatabaseDriver)
+		}
+		if config.SQLitePath != "/tmp/festivals.db" {
+			t.Errorf("Expected SQLite path /tmp/festivals.db, got %s", config.SQLitePath)
+		}
+	})
+}
+
+func TestOpenDatabase(t *testing.T) {
+	t.Run("opens sqlite database", func(t *testing.T) {
+		db, err := openDatabase(Config{
+			DatabaseDriver: "sqlite",
+			SQLitePath:     filepath.Join(t.TempDir(), "test.db"),
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if _, ok := db.(*SQLiteDatabase); !ok {
+			t.Errorf("Expected *SQLiteDatabase, got %T", db)
+		}
+	})
+
+	t.Run("requires Supabase credentials for supabase driver", func(t *testing.T) {
+		_, err := openDatabase(Config{DatabaseDriver: "supabase"})
+		if err == nil {
+			t.Error("Expected error without Supabase credentials, got nil")
+		}
+	})
+
+	t.Run("opens memory database from seed file", func(t *testing.T) {
+		db, err := openDatabase(Config{
+			DatabaseDriver: "memory",
+			SeedPath:       "seed.json",
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if _, ok := db.(*MemoryDatabase); !ok {
+			t.Errorf("Expected *MemoryDatabase, got %T", db)
+		}
+	})
+
+	t.Run("rejects unknown driver", func(t *testing.T) {
+		_, err := openDatabase(Config{DatabaseDriver: "oracle"})
+		if err == nil {
+			t.Error("Expected error for unknown driver, got nil")
+		}
 	})
 }
 
@@ -70,8 +230,8 @@ func TestFestivalsHandler(t *testing.T) {
 		}
 
 		methods := w.Header().Get("Access-Control-Allow-Methods")
-		if methods != "GET, POST, OPTIONS" {
-			t.Errorf("Expected CORS methods 'GET, POST, OPTIONS', got %s", methods)
+		if methods != "GET, POST, PUT, PATCH, DELETE, OPTIONS" {
+			t.Errorf("Expected CORS methods 'GET, POST, PUT, PATCH, DELETE, OPTIONS', got %s", methods)
 		}
 	})
 
@@ -137,6 +297,23 @@ func TestFestivalsHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("returns 504 when the database times out", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals", nil)
+		w := httptest.NewRecorder()
+		mockDB := &MockDatabase{
+			getFestivalsFunc: func() ([]Festival, error) {
+				return nil, fmt.Errorf("failed to fetch festivals: %w", context.DeadlineExceeded)
+			},
+		}
+
+		handler := makeFestivalsHandler(mockDB, "*")
+		handler(w, req)
+
+		if w.Code != http.StatusGatewayTimeout {
+			t.Errorf("Expected status 504, got %d", w.Code)
+		}
+	})
+
 	t.Run("handles GET request", func(t *testing.T) {
 		req := httptest.NewRequest("GET", "/api/festivals", nil)
 		w := httptest.NewRecorder()
@@ -220,57 +397,215 @@ func TestEnableCORS(t *testing.T) {
 	})
 }
 
+func newTestRouter(db DatabaseInterface) http.HandlerFunc {
+	return newRouter(db, Config{AllowedOrigins: "*"}).ServeHTTP
+}
+
 type MockDatabase struct {
 	loginFunc                  func(email, password string) (*LoginResponse, error)
 	verifyTokenFunc            func(token string) (*User, error)
+	refreshSessionFunc         func(refreshToken string) (*LoginResponse, error)
+	logoutFunc                 func(token string) error
 	getFestivalsFunc           func() ([]Festival, error)
-	getBreweriesByFestivalFunc func(festivalID string) ([]Brewery, error)
+	getFestivalFunc            func(id int64) (*Festival, error)
+	getBreweriesByFestivalFunc func(festivalID int64) ([]Brewery, error)
+	getBreweryFunc             func(id int64) (*Brewery, error)
+	getFestivalsByBreweryFunc  func(breweryID int64) ([]Festival, error)
 	getBreweriesFunc func() ([]Brewery, error)
 	createFestivalFunc         func(festival *FestivalDB) (*FestivalDB, error)
+	updateFestivalFunc         func(id int64, festival *FestivalDB) (*FestivalDB, error)
+	deleteFestivalFunc         func(id int64) error
+	createBreweryFunc          func(brewery *BreweryDB) (*BreweryDB, error)
+	updateBreweryFunc          func(id int64, brewery *BreweryDB) (*BreweryDB, error)
+	deleteBreweryFunc          func(id int64, cascade bool) error
+	addBreweriesFunc           func(festivalID int64, breweryIDs []int64) error
+	removeBreweryFunc          func(festivalID, breweryID int64) error
+	replaceBreweriesFunc       func(festivalID int64, breweryIDs []int64) error
+	getFestivalOwnersFunc      func(festivalID int64) ([]string, error)
+	getFestivalsByOwnerFunc    func(userID string) ([]Festival, error)
+	addOwnersFunc              func(festivalID int64, userIDs []string) error
+	replaceOwnersFunc          func(festivalID int64, userIDs []string) error
+	listFestivalsFunc          func(query FestivalQuery) ([]Festival, int, error)
+	listBreweriesFunc          func(query BreweryQuery) ([]Brewery, int, error)
 }
 
-func (m *MockDatabase) Login(email, password string) (*LoginResponse, error) {
+func (m *MockDatabase) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
 	if m.loginFunc != nil {
 		return m.loginFunc(email, password)
 	}
 	return nil, nil
 }
 
-func (m *MockDatabase) VerifyToken(token string) (*User, error) {
+func (m *MockDatabase) VerifyToken(ctx context.Context, token string) (*User, error) {
 	if m.verifyTokenFunc != nil {
 		return m.verifyTokenFunc(token)
 	}
 	return nil, nil
 }
 
-func (m *MockDatabase) GetFestivals() ([]Festival, error) {
+func (m *MockDatabase) RefreshSession(ctx context.Context, refreshToken string) (*LoginResponse, error) {
+	if m.refreshSessionFunc != nil {
+		return m.refreshSessionFunc(refreshToken)
+	}
+	return nil, nil
+}
+
+func (m *MockDatabase) Logout(ctx context.Context, token string) error {
+	if m.logoutFunc != nil {
+		return m.logoutFunc(token)
+	}
+	return nil
+}
+
+func (m *MockDatabase) GetFestivals(ctx context.Context) ([]Festival, error) {
 	if m.getFestivalsFunc != nil {
 		return m.getFestivalsFunc()
 	}
 	return nil, nil
 }
 
-func (m *MockDatabase) GetBreweries() ([]Brewery, error) {
+func (m *MockDatabase) ListFestivals(ctx context.Context, query FestivalQuery) ([]Festival, int, error) {
+	if m.listFestivalsFunc != nil {
+		return m.listFestivalsFunc(query)
+	}
+	festivals, err := m.GetFestivals(ctx)
+	return festivals, len(festivals), err
+}
+
+func (m *MockDatabase) GetBreweries(ctx context.Context) ([]Brewery, error) {
 	if m.getBreweriesFunc != nil {
 		return m.getBreweriesFunc()
 	}
 	return nil, nil
 }
 
-func (m *MockDatabase) GetBreweriesByFestival(festivalID string) ([]Brewery, error) {
+func (m *MockDatabase) ListBreweries(ctx context.Context, query BreweryQuery) ([]Brewery, int, error) {
+	if m.listBreweriesFunc != nil {
+		return m.listBreweriesFunc(query)
+	}
+	breweries, err := m.GetBreweries(ctx)
+	return breweries, len(breweries), err
+}
+
+func (m *MockDatabase) GetFestival(ctx context.Context, id int64) (*Festival, error) {
+	if m.getFestivalFunc != nil {
+		return m.getFestivalFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *MockDatabase) GetBreweriesByFestival(ctx context.Context, festivalID int64) ([]Brewery, error) {
 	if m.getBreweriesByFestivalFunc != nil {
 		return m.getBreweriesByFestivalFunc(festivalID)
 	}
 	return nil, nil
 }
 
-func (m *MockDatabase) CreateFestival(festival *FestivalDB) (*FestivalDB, error) {
+func (m *MockDatabase) GetBrewery(ctx context.Context, id int64) (*Brewery, error) {
+	if m.getBreweryFunc != nil {
+		return m.getBreweryFunc(id)
+	}
+	return nil, nil
+}
+
+func (m *MockDatabase) GetFestivalsByBrewery(ctx context.Context, breweryID int64) ([]Festival, error) {
+	if m.getFestivalsByBreweryFunc != nil {
+		return m.getFestivalsByBreweryFunc(breweryID)
+	}
+	return nil, nil
+}
+
+func (m *MockDatabase) CreateFestival(ctx context.Context, festival *FestivalDB) (*FestivalDB, error) {
 	if m.createFestivalFunc != nil {
 		return m.createFestivalFunc(festival)
 	}
 	return nil, nil
 }
 
+func (m *MockDatabase) UpdateFestival(ctx context.Context, id int64, festival *FestivalDB) (*FestivalDB, error) {
+	if m.updateFestivalFunc != nil {
+		return m.updateFestivalFunc(id, festival)
+	}
+	return nil, nil
+}
+
+func (m *MockDatabase) DeleteFestival(ctx context.Context, id int64) error {
+	if m.deleteFestivalFunc != nil {
+		return m.deleteFestivalFunc(id)
+	}
+	return nil
+}
+
+func (m *MockDatabase) CreateBrewery(ctx context.Context, brewery *BreweryDB) (*BreweryDB, error) {
+	if m.createBreweryFunc != nil {
+		return m.createBreweryFunc(brewery)
+	}
+	return nil, nil
+}
+
+func (m *MockDatabase) UpdateBrewery(ctx context.Context, id int64, brewery *BreweryDB) (*BreweryDB, error) {
+	if m.updateBreweryFunc != nil {
+		return m.updateBreweryFunc(id, brewery)
+	}
+	return nil, nil
+}
+
+func (m *MockDatabase) DeleteBrewery(ctx context.Context, id int64, cascade bool) error {
+	if m.deleteBreweryFunc != nil {
+		return m.deleteBreweryFunc(id, cascade)
+	}
+	return nil
+}
+
+func (m *MockDatabase) AddBreweriesToFestival(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	if m.addBreweriesFunc != nil {
+		return m.addBreweriesFunc(festivalID, breweryIDs)
+	}
+	return nil
+}
+
+func (m *MockDatabase) RemoveBreweryFromFestival(ctx context.Context, festivalID, breweryID int64) error {
+	if m.removeBreweryFunc != nil {
+		return m.removeBreweryFunc(festivalID, breweryID)
+	}
+	return nil
+}
+
+func (m *MockDatabase) ReplaceFestivalBreweries(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	if m.replaceBreweriesFunc != nil {
+		return m.replaceBreweriesFunc(festivalID, breweryIDs)
+	}
+	return nil
+}
+
+func (m *MockDatabase) GetFestivalOwners(ctx context.Context, festivalID int64) ([]string, error) {
+	if m.getFestivalOwnersFunc != nil {
+		return m.getFestivalOwnersFunc(festivalID)
+	}
+	return []string{}, nil
+}
+
+func (m *MockDatabase) GetFestivalsByOwner(ctx context.Context, userID string) ([]Festival, error) {
+	if m.getFestivalsByOwnerFunc != nil {
+		return m.getFestivalsByOwnerFunc(userID)
+	}
+	return []Festival{}, nil
+}
+
+func (m *MockDatabase) AddFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	if m.addOwnersFunc != nil {
+		return m.addOwnersFunc(festivalID, userIDs)
+	}
+	return nil
+}
+
+func (m *MockDatabase) ReplaceFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	if m.replaceOwnersFunc != nil {
+		return m.replaceOwnersFunc(festivalID, userIDs)
+	}
+	return nil
+}
+
 func TestLoginHandler(t *testing.T) {
 	t.Run("returns token on successful login", func(t *testing.T) {
 		mockDB := &MockDatabase{
@@ -373,7 +708,7 @@ func TestLoginHandler(t *testing.T) {
 		req := httptest.NewRequest("GET", "/api/auth/login", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeLoginHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusMethodNotAllowed {
@@ -396,116 +731,194 @@ func TestLoginHandler(t *testing.T) {
 	})
 }
 
-func TestVerifyHandler(t *testing.T) {
-	t.Run("returns valid response with valid token", func(t *testing.T) {
+func TestRefreshHandler(t *testing.T) {
+	t.Run("returns a new token pair", func(t *testing.T) {
 		mockDB := &MockDatabase{
-			verifyTokenFunc: func(token string) (*User, error) {
-				if token == "valid-token" {
-					return &User{
-						ID:    "user-123",
-						Email: "test@example.com",
-					}, nil
+			refreshSessionFunc: func(refreshToken string) (*LoginResponse, error) {
+				if refreshToken != "mock-refresh-token" {
+					t.Errorf("Expected mock-refresh-token, got %s", refreshToken)
 				}
-				return nil, &DatabaseError{Message: "invalid token"}
+				return &LoginResponse{
+					AccessToken:  "new-access-token",
+					RefreshToken: "new-refresh-token",
+					User:         User{ID: "user-123", Email: "test@example.com"},
+				}, nil
 			},
 		}
 
-		req := httptest.NewRequest("GET", "/api/auth/verify", nil)
-		req.Header.Set("Authorization", "Bearer valid-token")
+		body := []byte(`{"refreshToken":"mock-refresh-token"}`)
+		req := httptest.NewRequest("POST", "/api/v1/auth/refresh", bytes.NewBuffer(body))
 		w := httptest.NewRecorder()
 
-		handler := makeVerifyHandler(mockDB, "*")
-		handler(w, req)
+		newTestRouter(mockDB)(w, req)
 
 		if w.Code != http.StatusOK {
-			t.Errorf("Expected status 200, got %d", w.Code)
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
 		}
 
-		var response VerifyResponse
+		var response LoginResponse
 		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
 			t.Fatalf("Failed to decode response: %v", err)
 		}
 
-		if !response.Valid {
-			t.Error("Expected valid to be true")
+		if response.AccessToken != "new-access-token" || response.RefreshToken != "new-refresh-token" {
+			t.Errorf("Expected the new token pair, got %+v", response)
 		}
+	})
 
-		if response.User == nil {
-			t.Fatal("Expected user to be present")
+	t.Run("returns 401 on an invalid refresh token", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			refreshSessionFunc: func(refreshToken string) (*LoginResponse, error) {
+				return nil, ErrInvalidToken
+			},
 		}
 
-		if response.User.Email != "test@example.com" {
-			t.Errorf("Expected user email 'test@example.com', got %s", response.User.Email)
+		body := []byte(`{"refreshToken":"expired"}`)
+		req := httptest.NewRequest("POST", "/api/v1/auth/refresh", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		makeRefreshHandler(mockDB, "*")(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status 401, got %d", w.Code)
 		}
-	})
 
-	t.Run("returns invalid response with invalid token", func(t *testing.T) {
-		mockDB := &MockDatabase{
-			verifyTokenFunc: func(token string) (*User, error) {
-				return nil, &DatabaseError{Message: "invalid token"}
-			},
+		if problem := decodeProblem(t, w); problem.Code != ErrorCodeInvalidToken {
+			t.Errorf("Expected code %s, got %s", ErrorCodeInvalidToken, problem.Code)
 		}
+	})
 
-		req := httptest.NewRequest("GET", "/api/auth/verify", nil)
-		req.Header.Set("Authorization", "Bearer invalid-token")
+	tests := []struct {
+		name string
+		body string
+		code string
+	}{
+		{"missing refresh token", `{}`, ErrorCodeValidation},
+		{"malformed body", `{`, ErrorCodeInvalidBody},
+	}
+
+	for _, tt := range tests {
+		t.Run("returns 400 on "+tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/auth/refresh", bytes.NewBufferString(tt.body))
+			w := httptest.NewRecorder()
+
+			makeRefreshHandler(&MockDatabase{}, "*")(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("Expected status 400, got %d", w.Code)
+			}
+
+			if problem := decodeProblem(t, w); problem.Code != tt.code {
+				t.Errorf("Expected code %s, got %s", tt.code, problem.Code)
+			}
+		})
+	}
+
+	t.Run("handles OPTIONS request", func(t *testing.T) {
+		req := httptest.NewRequest("OPTIONS", "/api/v1/auth/refresh", nil)
+		req.Header.Set("Origin", "http://localhost:5173")
 		w := httptest.NewRecorder()
 
-		handler := makeVerifyHandler(mockDB, "*")
-		handler(w, req)
+		newTestRouter(&MockDatabase{})(w, req)
 
 		if w.Code != http.StatusOK {
-			t.Errorf("Expected status 200, got %d", w.Code)
+			t.Errorf("Expected status 200 for OPTIONS, got %d", w.Code)
 		}
+		if w.Header().Get("Access-Control-Allow-Origin") == "" {
+			t.Error("Expected CORS headers on OPTIONS")
+		}
+	})
+}
 
-		var response VerifyResponse
-		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
-			t.Fatalf("Failed to decode response: %v", err)
+func TestLogoutHandler(t *testing.T) {
+	t.Run("revokes the bearer token's session", func(t *testing.T) {
+		var revoked string
+		mockDB := &MockDatabase{
+			verifyTokenFunc: func(token string) (*User, error) {
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
+			},
+			logoutFunc: func(token string) error {
+				revoked = token
+				return nil
+			},
 		}
 
-		if response.Valid {
-			t.Error("Expected valid to be false")
+		req := httptest.NewRequest("POST", "/api/v1/auth/logout", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(mockDB)(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Fatalf("Expected status 204, got %d: %s", w.Code, w.Body.String())
 		}
 
-		if response.Error != "Invalid token" {
-			t.Errorf("Expected error 'Invalid token', got %s", response.Error)
+		if revoked != "valid-token" {
+			t.Errorf("Expected valid-token to be revoked, got %q", revoked)
 		}
 	})
 
-	t.Run("returns invalid response without authorization header", func(t *testing.T) {
-		mockDB := &MockDatabase{}
+	t.Run("returns 401 without a token", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			logoutFunc: func(token string) error {
+				t.Error("Expected logout not to be called")
+				return nil
+			},
+		}
 
-		req := httptest.NewRequest("GET", "/api/auth/verify", nil)
+		req := httptest.NewRequest("POST", "/api/v1/auth/logout", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeVerifyHandler(mockDB, "*")
-		handler(w, req)
+		newTestRouter(mockDB)(w, req)
 
-		if w.Code != http.StatusOK {
-			t.Errorf("Expected status 200, got %d", w.Code)
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
 		}
+	})
 
-		var response VerifyResponse
-		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
-			t.Fatalf("Failed to decode response: %v", err)
+	t.Run("returns 401 when the session is unknown upstream", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			logoutFunc: func(token string) error {
+				return ErrInvalidToken
+			},
 		}
 
-		if response.Valid {
-			t.Error("Expected valid to be false")
+		req := httptest.NewRequest("POST", "/api/v1/auth/logout", nil)
+		req.Header.Set("Authorization", "Bearer stale-token")
+		w := httptest.NewRecorder()
+
+		makeLogoutHandler(mockDB, "*")(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected status 401, got %d", w.Code)
 		}
 
-		if response.Error != "No authorization header" {
-			t.Errorf("Expected error 'No authorization header', got %s", response.Error)
+		if problem := decodeProblem(t, w); problem.Code != ErrorCodeInvalidToken {
+			t.Errorf("Expected code %s, got %s", ErrorCodeInvalidToken, problem.Code)
 		}
 	})
+}
 
-	t.Run("returns invalid response without Bearer prefix", func(t *testing.T) {
-		mockDB := &MockDatabase{}
+func TestVerifyHandler(t *testing.T) {
+	t.Run("returns valid response with valid token", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			verifyTokenFunc: func(token string) (*User, error) {
+				if token == "valid-token" {
+					return &User{
+						ID:    "user-123",
+						Email: "test@example.com",
+						Roles: []string{RoleOrganizer},
+					}, nil
+				}
+				return nil, &DatabaseError{Message: "invalid token"}
+			},
+		}
 
 		req := httptest.NewRequest("GET", "/api/auth/verify", nil)
-		req.Header.Set("Authorization", "invalid-token")
+		req.Header.Set("Authorization", "Bearer valid-token")
 		w := httptest.NewRecorder()
 
-		handler := makeVerifyHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusOK {
@@ -517,22 +930,74 @@ func TestVerifyHandler(t *testing.T) {
 			t.Fatalf("Failed to decode response: %v", err)
 		}
 
-		if response.Valid {
-			t.Error("Expected valid to be false")
+		if !response.Valid {
+			t.Error("Expected valid to be true")
 		}
 
-		if response.Error != "Invalid authorization format" {
-			t.Errorf("Expected error 'Invalid authorization format', got %s", response.Error)
+		if response.User == nil {
+			t.Fatal("Expected user to be present")
+		}
+
+		if response.User.Email != "test@example.com" {
+			t.Errorf("Expected user email 'test@example.com', got %s", response.User.Email)
+		}
+
+		if !slices.Equal(response.User.Roles, []string{RoleOrganizer}) {
+			t.Errorf("Expected roles [organizer], got %v", response.User.Roles)
+		}
+
+		expected := []string{PermissionBreweriesWrite, PermissionFestivalsWrite, PermissionLineupsWrite}
+		if !slices.Equal(response.Permissions, expected) {
+			t.Errorf("Expected permissions %v, got %v", expected, response.Permissions)
 		}
 	})
 
+	tests := []struct {
+		name          string
+		authorization string
+		code          string
+		detail        string
+	}{
+		{"rejects an invalid token", "Bearer invalid-token", ErrorCodeInvalidToken, "Invalid token"},
+		{"rejects a missing authorization header", "", ErrorCodeUnauthorized, "Authorization header required"},
+		{"rejects a token without Bearer prefix", "invalid-token", ErrorCodeUnauthorized, "Invalid authorization format"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockDB := &MockDatabase{
+				verifyTokenFunc: func(token string) (*User, error) {
+					return nil, &DatabaseError{Message: "invalid token"}
+				},
+			}
+
+			req := httptest.NewRequest("GET", "/api/auth/verify", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler := newTestRouter(mockDB)
+			handler(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Expected status 401, got %d", w.Code)
+			}
+
+			problem := decodeProblem(t, w)
+			if problem.Code != tt.code || problem.Detail != tt.detail {
+				t.Errorf("Expected %s problem %q, got %s %q", tt.code, tt.detail, problem.Code, problem.Detail)
+			}
+		})
+	}
+
 	t.Run("returns 405 on non-GET request", func(t *testing.T) {
 		mockDB := &MockDatabase{}
 
 		req := httptest.NewRequest("POST", "/api/auth/verify", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeVerifyHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusMethodNotAllowed {
@@ -546,7 +1011,7 @@ func TestVerifyHandler(t *testing.T) {
 		req := httptest.NewRequest("OPTIONS", "/api/auth/verify", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeVerifyHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusOK {
@@ -566,8 +1031,8 @@ func (e *DatabaseError) Error() string {
 func TestBreweriesHandler(t *testing.T) {
 	t.Run("returns breweries for a festival", func(t *testing.T) {
 		mockDB := &MockDatabase{
-			getBreweriesByFestivalFunc: func(festivalID string) ([]Brewery, error) {
-				if festivalID == "1" {
+			getBreweriesByFestivalFunc: func(festivalID int64) ([]Brewery, error) {
+				if festivalID == 1 {
 					return []Brewery{
 						{
 							ID:          1,
@@ -586,7 +1051,7 @@ func TestBreweriesHandler(t *testing.T) {
 		req := httptest.NewRequest("GET", "/api/festivals/1/breweries", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeFestivalBreweriesHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusOK {
@@ -609,7 +1074,7 @@ func TestBreweriesHandler(t *testing.T) {
 
 	t.Run("returns error for invalid festival ID", func(t *testing.T) {
 		mockDB := &MockDatabase{
-			getBreweriesByFestivalFunc: func(festivalID string) ([]Brewery, error) {
+			getBreweriesByFestivalFunc: func(festivalID int64) ([]Brewery, error) {
 				return nil, &DatabaseError{Message: "festival not found"}
 			},
 		}
@@ -617,7 +1082,7 @@ func TestBreweriesHandler(t *testing.T) {
 		req := httptest.NewRequest("GET", "/api/festivals/999/breweries", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeFestivalBreweriesHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusInternalServerError {
@@ -625,13 +1090,32 @@ func TestBreweriesHandler(t *testing.T) {
 		}
 	})
 
-	t.Run("returns 400 for missing festival ID", func(t *testing.T) {
+	t.Run("redirects a missing festival ID without reaching the database", func(t *testing.T) {
 		mockDB := &MockDatabase{}
 
 		req := httptest.NewRequest("GET", "/api/festivals//breweries", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeFestivalBreweriesHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusTemporaryRedirect {
+			t.Errorf("Expected status 307, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 400 for non-numeric festival ID", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			getBreweriesByFestivalFunc: func(festivalID int64) ([]Brewery, error) {
+				t.Error("Expected database not to be called")
+				return nil, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/api/festivals/abc/breweries", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusBadRequest {
@@ -639,13 +1123,13 @@ func TestBreweriesHandler(t *testing.T) {
 		}
 	})
 
-	t.Run("returns 405 on non-GET request", func(t *testing.T) {
+	t.Run("returns 405 on unsupported method", func(t *testing.T) {
 		mockDB := &MockDatabase{}
 
-		req := httptest.NewRequest("POST", "/api/festivals/1/breweries", nil)
+		req := httptest.NewRequest("DELETE", "/api/festivals/1/breweries", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeFestivalBreweriesHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusMethodNotAllowed {
@@ -659,7 +1143,7 @@ func TestBreweriesHandler(t *testing.T) {
 		req := httptest.NewRequest("OPTIONS", "/api/festivals/1/breweries", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeFestivalBreweriesHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusOK {
@@ -668,6 +1152,139 @@ func TestBreweriesHandler(t *testing.T) {
 	})
 }
 
+func TestFestivalHandler(t *testing.T) {
+	mockDB := &MockDatabase{
+		getFestivalFunc: func(id int64) (*Festival, error) {
+			if id == 1 {
+				return &Festival{ID: 1, Name: "Test Festival", BreweryCount: 12}, nil
+			}
+			return nil, fmt.Errorf("festival %d: %w", id, ErrNotFound)
+		},
+	}
+
+	t.Run("returns the festival with its brewery count", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/1", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var festival Festival
+		if err := json.NewDecoder(w.Body).Decode(&festival); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if festival.Name != "Test Festival" || festival.BreweryCount != 12 {
+			t.Errorf("Expected Test Festival with 12 breweries, got %+v", festival)
+		}
+	})
+
+	t.Run("returns 404 for unknown festival", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/999", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 400 for non-numeric festival ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/abc", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 500 on database error", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			getFestivalFunc: func(id int64) (*Festival, error) {
+				return nil, &DatabaseError{Message: "database error"}
+			},
+		}
+		req := httptest.NewRequest("GET", "/api/festivals/1", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 405 on unsupported method", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/festivals/1", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", w.Code)
+		}
+	})
+}
+
+func TestFestivalRoutesHandler(t *testing.T) {
+	mockDB := &MockDatabase{
+		getFestivalFunc: func(id int64) (*Festival, error) {
+			return &Festival{ID: id}, nil
+		},
+		getBreweriesByFestivalFunc: func(festivalID int64) ([]Brewery, error) {
+			return []Brewery{{ID: 7}}, nil
+		},
+	}
+
+	t.Run("routes festival detail requests", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/3", nil)
+		w := httptest.NewRecorder()
+
+		newTestRouter(mockDB)(w, req)
+
+		var festival Festival
+		json.NewDecoder(w.Body).Decode(&festival)
+		if w.Code != http.StatusOK || festival.ID != 3 {
+			t.Errorf("Expected festival 3 with status 200, got %d %+v", w.Code, festival)
+		}
+	})
+
+	t.Run("routes festival breweries requests", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/3/breweries", nil)
+		w := httptest.NewRecorder()
+
+		newTestRouter(mockDB)(w, req)
+
+		var breweries []Brewery
+		json.NewDecoder(w.Body).Decode(&breweries)
+		if w.Code != http.StatusOK || len(breweries) != 1 {
+			t.Errorf("Expected one brewery with status 200, got %d %+v", w.Code, breweries)
+		}
+	})
+
+	t.Run("returns 404 for unknown sub-resources", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/abc/whatever", nil)
+		w := httptest.NewRecorder()
+
+		newTestRouter(mockDB)(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+}
+
 func TestAllBreweriesHandler(t *testing.T) {
 	t.Run("returns all breweries", func(t *testing.T) {
 		mockDB := &MockDatabase{
@@ -739,13 +1356,13 @@ func TestAllBreweriesHandler(t *testing.T) {
 		}
 	})
 
-	t.Run("returns 405 on non-GET request", func(t *testing.T) {
+	t.Run("returns 405 on unsupported method", func(t *testing.T) {
 		mockDB := &MockDatabase{}
 
-		req := httptest.NewRequest("POST", "/api/breweries", nil)
+		req := httptest.NewRequest("PUT", "/api/breweries", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeBreweriesHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusMethodNotAllowed {
@@ -786,8 +1403,8 @@ func TestAllBreweriesHandler(t *testing.T) {
 		}
 
 		methods := w.Header().Get("Access-Control-Allow-Methods")
-		if methods != "GET, POST, OPTIONS" {
-			t.Errorf("Expected CORS methods 'GET, POST, OPTIONS', got %s", methods)
+		if methods != "GET, POST, PUT, PATCH, DELETE, OPTIONS" {
+			t.Errorf("Expected CORS methods 'GET, POST, PUT, PATCH, DELETE, OPTIONS', got %s", methods)
 		}
 	})
 
@@ -819,6 +1436,110 @@ func TestAllBreweriesHandler(t *testing.T) {
 	})
 }
 
+func TestBreweryHandler(t *testing.T) {
+	past := time.Now().AddDate(-1, 0, 0)
+	future := time.Now().AddDate(1, 0, 0)
+	mockDB := &MockDatabase{
+		getBreweryFunc: func(id int64) (*Brewery, error) {
+			if id == 1 {
+				return &Brewery{ID: 1, Name: "Test Brewery", FestivalCount: 2}, nil
+			}
+			return nil, fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+		},
+		getFestivalsByBreweryFunc: func(breweryID int64) ([]Festival, error) {
+			return []Festival{
+				{ID: 1, Name: "Past Festival", StartDate: past, EndDate: past},
+				{ID: 2, Name: "Upcoming Festival", StartDate: future, EndDate: future},
+			}, nil
+		},
+	}
+
+	t.Run("returns brewery with upcoming and past festivals", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/breweries/1", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var detail BreweryDetail
+		if err := json.NewDecoder(w.Body).Decode(&detail); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if detail.Name != "Test Brewery" || detail.FestivalCount != 2 {
+			t.Errorf("Expected Test Brewery with 2 festivals, got %+v", detail.Brewery)
+		}
+
+		if len(detail.UpcomingFestivals) != 1 || detail.UpcomingFestivals[0].Name != "Upcoming Festival" {
+			t.Errorf("Expected one upcoming festival, got %+v", detail.UpcomingFestivals)
+		}
+
+		if len(detail.PastFestivals) != 1 || detail.PastFestivals[0].Name != "Past Festival" {
+			t.Errorf("Expected one past festival, got %+v", detail.PastFestivals)
+		}
+	})
+
+	t.Run("returns 404 for unknown brewery", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/breweries/999", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 400 for non-numeric brewery ID", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/breweries/abc", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 404 for nested paths", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/breweries/1/whatever", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 500 when festivals cannot be fetched", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			getBreweryFunc: func(id int64) (*Brewery, error) {
+				return &Brewery{ID: id}, nil
+			},
+			getFestivalsByBreweryFunc: func(breweryID int64) ([]Festival, error) {
+				return nil, &DatabaseError{Message: "database error"}
+			},
+		}
+		req := httptest.NewRequest("GET", "/api/breweries/1", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(mockDB)
+		handler(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+}
+
 func TestFestivalsHandlerWithDB(t *testing.T) {
 	mockFestivals := []Festival{
 		{
@@ -959,6 +1680,35 @@ func TestCreateFestivalHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("records the authenticated user as creator", func(t *testing.T) {
+		var created *FestivalDB
+		mockDB := &MockDatabase{
+			verifyTokenFunc: func(token string) (*User, error) {
+				return &User{ID: "user-123", Roles: []string{RoleOrganizer}}, nil
+			},
+			createFestivalFunc: func(festival *FestivalDB) (*FestivalDB, error) {
+				created = festival
+				festival.ID = 1
+				return festival, nil
+			},
+		}
+
+		body := []byte(`{"name":"Test Festival","start_date":"2025-10-01","end_date":"2025-10-03","created_by":"someone-else"}`)
+		req := httptest.NewRequest("POST", "/api/v1/festivals", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(mockDB)(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		if created == nil || created.CreatedBy != "user-123" {
+			t.Errorf("Expected festival created by user-123, got %+v", created)
+		}
+	})
+
 	t.Run("returns 401 when authorization header is missing", func(t *testing.T) {
 		mockDB := &MockDatabase{}
 
@@ -967,7 +1717,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 		req.Header.Set("Content-Type", "application/json")
 		w := httptest.NewRecorder()
 
-		handler := makeCreateFestivalHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusUnauthorized {
@@ -984,7 +1734,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 		req.Header.Set("Authorization", "invalid-format")
 		w := httptest.NewRecorder()
 
-		handler := makeCreateFestivalHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusUnauthorized {
@@ -1005,7 +1755,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 		req.Header.Set("Authorization", "Bearer invalid-token")
 		w := httptest.NewRecorder()
 
-		handler := makeCreateFestivalHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusUnauthorized {
@@ -1016,10 +1766,10 @@ func TestCreateFestivalHandler(t *testing.T) {
 	t.Run("returns 405 on non-POST request", func(t *testing.T) {
 		mockDB := &MockDatabase{}
 
-		req := httptest.NewRequest("GET", "/api/festivals", nil)
+		req := httptest.NewRequest("PUT", "/api/festivals", nil)
 		w := httptest.NewRecorder()
 
-		handler := makeCreateFestivalHandler(mockDB, "*")
+		handler := newTestRouter(mockDB)
 		handler(w, req)
 
 		if w.Code != http.StatusMethodNotAllowed {
@@ -1044,7 +1794,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 	t.Run("returns 400 when request body is invalid", func(t *testing.T) {
 		mockDB := &MockDatabase{
 			verifyTokenFunc: func(token string) (*User, error) {
-				return &User{ID: "user-123", Email: "test@example.com"}, nil
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
 			},
 		}
 
@@ -1065,7 +1815,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 	t.Run("returns 400 when name is missing", func(t *testing.T) {
 		mockDB := &MockDatabase{
 			verifyTokenFunc: func(token string) (*User, error) {
-				return &User{ID: "user-123", Email: "test@example.com"}, nil
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
 			},
 		}
 
@@ -1086,7 +1836,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 	t.Run("returns 400 when start_date is missing", func(t *testing.T) {
 		mockDB := &MockDatabase{
 			verifyTokenFunc: func(token string) (*User, error) {
-				return &User{ID: "user-123", Email: "test@example.com"}, nil
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
 			},
 		}
 
@@ -1107,7 +1857,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 	t.Run("returns 400 when end_date is missing", func(t *testing.T) {
 		mockDB := &MockDatabase{
 			verifyTokenFunc: func(token string) (*User, error) {
-				return &User{ID: "user-123", Email: "test@example.com"}, nil
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
 			},
 		}
 
@@ -1128,7 +1878,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 	t.Run("returns 400 when start_date format is invalid", func(t *testing.T) {
 		mockDB := &MockDatabase{
 			verifyTokenFunc: func(token string) (*User, error) {
-				return &User{ID: "user-123", Email: "test@example.com"}, nil
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
 			},
 		}
 
@@ -1154,7 +1904,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 	t.Run("returns 400 when end_date format is invalid", func(t *testing.T) {
 		mockDB := &MockDatabase{
 			verifyTokenFunc: func(token string) (*User, error) {
-				return &User{ID: "user-123", Email: "test@example.com"}, nil
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
 			},
 		}
 
@@ -1177,10 +1927,52 @@ func TestCreateFestivalHandler(t *testing.T) {
 		}
 	})
 
+	t.Run("returns 400 when end_date is before start_date", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			verifyTokenFunc: func(token string) (*User, error) {
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
+			},
+		}
+
+		body := []byte(`{"name":"Test Festival","start_date":"2025-10-03","end_date":"2025-10-01"}`)
+		req := httptest.NewRequest("POST", "/api/festivals", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := makeCreateFestivalHandler(mockDB, "*")
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 400 when coordinates are out of range", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			verifyTokenFunc: func(token string) (*User, error) {
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
+			},
+		}
+
+		body := []byte(`{"name":"Test Festival","start_date":"2025-10-01","end_date":"2025-10-03","latitude":95,"longitude":2.35}`)
+		req := httptest.NewRequest("POST", "/api/festivals", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := makeCreateFestivalHandler(mockDB, "*")
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
 	t.Run("returns 500 when database fails to create festival", func(t *testing.T) {
 		mockDB := &MockDatabase{
 			verifyTokenFunc: func(token string) (*User, error) {
-				return &User{ID: "user-123", Email: "test@example.com"}, nil
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
 			},
 			createFestivalFunc: func(festival *FestivalDB) (*FestivalDB, error) {
 				return nil, &DatabaseError{Message: "database error"}
@@ -1204,7 +1996,7 @@ func TestCreateFestivalHandler(t *testing.T) {
 	t.Run("sets CORS headers", func(t *testing.T) {
 		mockDB := &MockDatabase{
 			verifyTokenFunc: func(token string) (*User, error) {
-				return &User{ID: "user-123", Email: "test@example.com"}, nil
+				return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
 			},
 			createFestivalFunc: func(festival *FestivalDB) (*FestivalDB, error) {
 				festival.ID = 1
@@ -1227,8 +2019,1367 @@ func TestCreateFestivalHandler(t *testing.T) {
 		}
 
 		methods := w.Header().Get("Access-Control-Allow-Methods")
-		if methods != "GET, POST, OPTIONS" {
-			t.Errorf("Expected CORS methods 'GET, POST, OPTIONS', got %s", methods)
+		if methods != "GET, POST, PUT, PATCH, DELETE, OPTIONS" {
+			t.Errorf("Expected CORS methods 'GET, POST, PUT, PATCH, DELETE, OPTIONS', got %s", methods)
+		}
+	})
+}
+
+func authedMockDB(overrides *MockDatabase) *MockDatabase {
+	overrides.verifyTokenFunc = func(token string) (*User, error) {
+		if token == "valid-token" {
+			return &User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}, nil
+		}
+		if _, ok := rolePermissions[token]; ok {
+			return &User{ID: "user-123", Email: "test@example.com", Roles: []string{token}}, nil
+		}
+		return nil, &DatabaseError{Message: "invalid token"}
+	}
+	return overrides
+}
+
+func newFestivalWriteMockDB() *MockDatabase {
+	return authedMockDB(&MockDatabase{
+		getFestivalOwnersFunc: func(festivalID int64) ([]string, error) {
+			owners := map[int64][]string{1: {"user-123"}, 2: {"user-456"}}
+			if _, ok := owners[festivalID]; !ok {
+				return nil, fmt.Errorf("festival %d: %w", festivalID, ErrNotFound)
+			}
+			return owners[festivalID], nil
+		},
+		getFestivalFunc: func(id int64) (*Festival, error) {
+			if id != 1 {
+				return nil, fmt.Errorf("festival %d: %w", id, ErrNotFound)
+			}
+			return &Festival{
+				ID:        1,
+				Name:      "Test Festival",
+				StartDate: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+				EndDate:   time.Date(2025, 10, 3, 0, 0, 0, 0, time.UTC),
+				City:      "Paris",
+			}, nil
+		},
+		updateFestivalFunc: func(id int64, festival *FestivalDB) (*FestivalDB, error) {
+			if id != 1 {
+				return nil, fmt.Errorf("festival %d: %w", id, ErrNotFound)
+			}
+			updated := *festival
+			updated.ID = id
+			return &updated, nil
+		},
+		deleteFestivalFunc: func(id int64) error {
+			if id != 1 {
+				return fmt.Errorf("festival %d: %w", id, ErrNotFound)
+			}
+			return nil
+		},
+	})
+}
+
+func TestFestivalWriteMiddlewareOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		path   string
+		status int
+		code   string
+	}{
+		{"rejects a missing token first", "", "/api/v1/festivals/2", http.StatusUnauthorized, ErrorCodeUnauthorized},
+		{"rejects an invalid token", "unknown-token", "/api/v1/festivals/2", http.StatusUnauthorized, ErrorCodeInvalidToken},
+		{"checks the permission before ownership", RoleViewer, "/api/v1/festivals/2", http.StatusForbidden, ErrorCodeForbidden},
+		{"checks ownership once the permission is granted", RoleOrganizer, "/api/v1/festivals/2", http.StatusForbidden, ErrorCodeNotFestivalOwner},
+		{"reports unknown festivals from the ownership check", RoleOrganizer, "/api/v1/festivals/999", http.StatusNotFound, ErrorCodeFestivalNotFound},
+		{"lets owners through", RoleOrganizer, "/api/v1/festivals/1", http.StatusOK, ""},
+		{"lets admins through without ownership", RoleAdmin, "/api/v1/festivals/1", http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("PATCH", tt.path, strings.NewReader(`{"name":"Renamed Festival"}`))
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+
+			newTestRouter(newFestivalWriteMockDB())(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+			if tt.code != "" {
+				if problem := decodeProblem(t, w); problem.Code != tt.code {
+					t.Errorf("Expected code %s, got %s", tt.code, problem.Code)
+				}
+			}
+		})
+	}
+}
+
+func TestUpdateFestivalHandler(t *testing.T) {
+	t.Run("replaces festival with PUT", func(t *testing.T) {
+		body := []byte(`{"name":"Renamed Festival","start_date":"2025-11-01","end_date":"2025-11-02","city":"Lyon"}`)
+		req := httptest.NewRequest("PUT", "/api/v1/festivals/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response FestivalDB
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if response.ID != 1 || response.Name != "Renamed Festival" || response.City != "Lyon" {
+			t.Errorf("Expected renamed festival in Lyon, got %+v", response)
+		}
+	})
+
+	t.Run("applies partial update with PATCH", func(t *testing.T) {
+		body := []byte(`{"name":"Renamed Festival"}`)
+		req := httptest.NewRequest("PATCH", "/api/v1/festivals/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response FestivalDB
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if response.Name != "Renamed Festival" {
+			t.Errorf("Expected name to be updated, got %s", response.Name)
+		}
+
+		if response.City != "Paris" || response.StartDate != "2025-10-01" || response.EndDate != "2025-10-03" {
+			t.Errorf("Expected other fields to be kept, got %+v", response)
+		}
+	})
+
+	t.Run("cancels festival with PATCH", func(t *testing.T) {
+		body := []byte(`{"cancelled":true}`)
+		req := httptest.NewRequest("PATCH", "/api/v1/festivals/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		var response FestivalDB
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if w.Code != http.StatusOK || !response.Cancelled {
+			t.Errorf("Expected cancelled festival with status 200, got %d %+v", w.Code, response)
+		}
+	})
+
+	t.Run("validates PATCH result", func(t *testing.T) {
+		body := []byte(`{"end_date":"2025-09-01"}`)
+		req := httptest.NewRequest("PATCH", "/api/v1/festivals/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 400 when PUT body misses required fields", func(t *testing.T) {
+		body := []byte(`{"name":"Renamed Festival"}`)
+		req := httptest.NewRequest("PUT", "/api/v1/festivals/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 404 for unknown festival", func(t *testing.T) {
+		body := []byte(`{"name":"Renamed Festival"}`)
+		req := httptest.NewRequest("PATCH", "/api/v1/festivals/999", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 401 without valid token", func(t *testing.T) {
+		body := []byte(`{"name":"Renamed Festival","start_date":"2025-11-01","end_date":"2025-11-02"}`)
+		req := httptest.NewRequest("PUT", "/api/v1/festivals/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer invalid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+}
+
+func TestDeleteFestivalHandler(t *testing.T) {
+	t.Run("deletes festival", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/festivals/1", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 404 for unknown festival", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/festivals/999", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 401 without authorization header", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/festivals/1", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newFestivalWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+}
+
+func newBreweryWriteMockDB() *MockDatabase {
+	return authedMockDB(&MockDatabase{
+		getBreweryFunc: func(id int64) (*Brewery, error) {
+			if id != 1 {
+				return nil, fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+			}
+			return &Brewery{ID: 1, Name: "Test Brewery", City: "Lille", Website: "https://brewery.example.com"}, nil
+		},
+		createBreweryFunc: func(brewery *BreweryDB) (*BreweryDB, error) {
+			created := *brewery
+			created.ID = 3
+			return &created, nil
+		},
+		updateBreweryFunc: func(id int64, brewery *BreweryDB) (*BreweryDB, error) {
+			if id != 1 {
+				return nil, fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+			}
+			updated := *brewery
+			updated.ID = id
+			return &updated, nil
+		},
+		deleteBreweryFunc: func(id int64, cascade bool) error {
+			if id != 1 {
+				return fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+			}
+			if !cascade {
+				return fmt.Errorf("brewery %d is linked to festivals: %w", id, ErrConflict)
+			}
+			return nil
+		},
+	})
+}
+
+func TestCreateBreweryHandler(t *testing.T) {
+	t.Run("creates brewery", func(t *testing.T) {
+		body := []byte(`{"name":"New Brewery","city":"Lyon","website":"https://new.example.com","logo":"https://new.example.com/logo.png"}`)
+		req := httptest.NewRequest("POST", "/api/v1/breweries", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Fatalf("Expected status 201, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var response BreweryDB
+		if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if response.ID != 3 || response.Name != "New Brewery" {
+			t.Errorf("Expected created brewery with ID 3, got %+v", response)
+		}
+	})
+
+	t.Run("validates brewery fields", func(t *testing.T) {
+		cases := map[string]string{
+			"missing name":    `{"city":"Lyon"}`,
+			"missing city":    `{"name":"New Brewery"}`,
+			"blank name":      `{"name":"   ","city":"Lyon"}`,
+			"invalid website": `{"name":"New Brewery","city":"Lyon","website":"not a url"}`,
+			"invalid logo":    `{"name":"New Brewery","city":"Lyon","logo":"ftp://new.example.com/logo.png"}`,
+		}
+
+		for name, body := range cases {
+			req := httptest.NewRequest("POST", "/api/v1/breweries", bytes.NewBufferString(body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			w := httptest.NewRecorder()
+
+			handler := newTestRouter(newBreweryWriteMockDB())
+			handler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: Expected status 400, got %d", name, w.Code)
+			}
+		}
+	})
+
+	t.Run("returns 401 without authorization header", func(t *testing.T) {
+		body := []byte(`{"name":"New Brewery","city":"Lyon"}`)
+		req := httptest.NewRequest("POST", "/api/v1/breweries", bytes.NewBuffer(body))
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+}
+
+func TestUpdateBreweryHandler(t *testing.T) {
+	t.Run("replaces brewery with PUT", func(t *testing.T) {
+		body := []byte(`{"name":"Renamed Brewery","city":"Roubaix"}`)
+		req := httptest.NewRequest("PUT", "/api/v1/breweries/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		var response BreweryDB
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if w.Code != http.StatusOK || response.Name != "Renamed Brewery" || response.Website != "" {
+			t.Errorf("Expected replaced brewery with status 200, got %d %+v", w.Code, response)
+		}
+	})
+
+	t.Run("applies partial update with PATCH", func(t *testing.T) {
+		body := []byte(`{"city":"Roubaix"}`)
+		req := httptest.NewRequest("PATCH", "/api/v1/breweries/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		var response BreweryDB
+		json.NewDecoder(w.Body).Decode(&response)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		if response.City != "Roubaix" || response.Name != "Test Brewery" || response.Website != "https://brewery.example.com" {
+			t.Errorf("Expected only city to change, got %+v", response)
+		}
+	})
+
+	t.Run("returns 404 for unknown brewery", func(t *testing.T) {
+		body := []byte(`{"name":"Renamed Brewery","city":"Roubaix"}`)
+		req := httptest.NewRequest("PUT", "/api/v1/breweries/999", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 401 without valid token", func(t *testing.T) {
+		body := []byte(`{"city":"Roubaix"}`)
+		req := httptest.NewRequest("PATCH", "/api/v1/breweries/1", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer invalid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+}
+
+func TestDeleteBreweryHandler(t *testing.T) {
+	t.Run("refuses to delete linked brewery", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/breweries/1", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("deletes linked brewery with cascade", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/breweries/1?cascade=true", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 400 for invalid cascade value", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/breweries/1?cascade=maybe", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 404 for unknown brewery", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/breweries/999?cascade=true", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 401 without authorization header", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/breweries/1", nil)
+		w := httptest.NewRecorder()
+
+		handler := newTestRouter(newBreweryWriteMockDB())
+		handler(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+}
+
+func newLineupMockDB() *MockDatabase {
+	lineup := map[int64]bool{1: true}
+	return authedMockDB(&MockDatabase{
+		getBreweriesByFestivalFunc: func(festivalID int64) ([]Brewery, error) {
+			breweries := []Brewery{}
+			for id := int64(1); id <= 3; id++ {
+				if lineup[id] {
+					breweries = append(breweries, Brewery{ID: id})
+				}
+			}
+			return breweries, nil
+		},
+		addBreweriesFunc: func(festivalID int64, breweryIDs []int64) error {
+			if festivalID != 1 {
+				return fmt.Errorf("festival %d: %w", festivalID, ErrNotFound)
+			}
+			for _, id := range breweryIDs {
+				if lineup[id] {
+					return fmt.Errorf("brewery %d: %w", id, ErrConflict)
+				}
+			}
+			for _, id := range breweryIDs {
+				lineup[id] = true
+			}
+			return nil
+		},
+		replaceBreweriesFunc: func(festivalID int64, breweryIDs []int64) error {
+			lineup = map[int64]bool{}
+			for _, id := range breweryIDs {
+				lineup[id] = true
+			}
+			return nil
+		},
+		removeBreweryFunc: func(festivalID, breweryID int64) error {
+			if !lineup[breweryID] {
+				return fmt.Errorf("brewery %d: %w", breweryID, ErrNotFound)
+			}
+			delete(lineup, breweryID)
+			return nil
+		},
+	})
+}
+
+func TestFestivalLineupHandlers(t *testing.T) {
+	t.Run("adds breweries to lineup", func(t *testing.T) {
+		body := []byte(`{"brewery_ids":[2,3]}`)
+		req := httptest.NewRequest("POST", "/api/v1/festivals/1/breweries", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(newLineupMockDB())(w, req)
+
+		var breweries []Brewery
+		json.NewDecoder(w.Body).Decode(&breweries)
+		if w.Code != http.StatusCreated || len(breweries) != 3 {
+			t.Errorf("Expected three breweries with status 201, got %d %+v", w.Code, breweries)
+		}
+	})
+
+	t.Run("returns 409 for brewery already in lineup", func(t *testing.T) {
+		body := []byte(`{"brewery_ids":[1]}`)
+		req := httptest.NewRequest("POST", "/api/v1/festivals/1/breweries", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(newLineupMockDB())(w, req)
+
+		if w.Code != http.StatusConflict {
+			t.Errorf("Expected status 409, got %d", w.Code)
+		}
+	})
+
+	t.Run("rejects invalid brewery lists", func(t *testing.T) {
+		cases := map[string]string{
+			"empty list":   `{"brewery_ids":[]}`,
+			"duplicate id": `{"brewery_ids":[2,2]}`,
+			"invalid id":   `{"brewery_ids":[0]}`,
+		}
+
+		for name, body := range cases {
+			req := httptest.NewRequest("POST", "/api/v1/festivals/1/breweries", bytes.NewBufferString(body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			w := httptest.NewRecorder()
+
+			newTestRouter(newLineupMockDB())(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: Expected status 400, got %d", name, w.Code)
+			}
+		}
+	})
+
+	t.Run("returns 404 for unknown festival", func(t *testing.T) {
+		body := []byte(`{"brewery_ids":[2]}`)
+		req := httptest.NewRequest("POST", "/api/v1/festivals/999/breweries", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(newLineupMockDB())(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("replaces lineup", func(t *testing.T) {
+		body := []byte(`{"brewery_ids":[3]}`)
+		req := httptest.NewRequest("PUT", "/api/v1/festivals/1/breweries", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(newLineupMockDB())(w, req)
+
+		var breweries []Brewery
+		json.NewDecoder(w.Body).Decode(&breweries)
+		if w.Code != http.StatusOK || len(breweries) != 1 || breweries[0].ID != 3 {
+			t.Errorf("Expected lineup with brewery 3 and status 200, got %d %+v", w.Code, breweries)
+		}
+	})
+
+	t.Run("clears lineup with empty replacement", func(t *testing.T) {
+		body := []byte(`{"brewery_ids":[]}`)
+		req := httptest.NewRequest("PUT", "/api/v1/festivals/1/breweries", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(newLineupMockDB())(w, req)
+
+		if w.Code != http.StatusOK || strings.TrimSpace(w.Body.String()) != "[]" {
+			t.Errorf("Expected empty lineup with status 200, got %d %s", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("removes brewery from lineup", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/festivals/1/breweries/1", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(newLineupMockDB())(w, req)
+
+		if w.Code != http.StatusNoContent {
+			t.Errorf("Expected status 204, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 404 when removing brewery not in lineup", func(t *testing.T) {
+		req := httptest.NewRequest("DELETE", "/api/v1/festivals/1/breweries/2", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(newLineupMockDB())(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 405 for GET on lineup entry", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/festivals/1/breweries/1", nil)
+		w := httptest.NewRecorder()
+
+		newTestRouter(newLineupMockDB())(w, req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected status 405, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 401 without authorization header", func(t *testing.T) {
+		for _, req := range []*http.Request{
+			httptest.NewRequest("POST", "/api/v1/festivals/1/breweries", bytes.NewBufferString(`{"brewery_ids":[2]}`)),
+			httptest.NewRequest("PUT", "/api/v1/festivals/1/breweries", bytes.NewBufferString(`{"brewery_ids":[2]}`)),
+			httptest.NewRequest("DELETE", "/api/v1/festivals/1/breweries/1", nil),
+		} {
+			w := httptest.NewRecorder()
+
+			newTestRouter(newLineupMockDB())(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("%s: Expected status 401, got %d", req.Method, w.Code)
+			}
+		}
+	})
+}
+
+func newOwnersMockDB() *MockDatabase {
+	owners := map[int64][]string{1: {"user-123"}}
+	users := []string{"user-123", "user-456"}
+
+	checkOwners := func(festivalID int64, userIDs []string) error {
+		if _, ok := owners[festivalID]; !ok {
+			return ErrNotFound
+		}
+		for _, userID := range userIDs {
+			if !slices.Contains(users, userID) {
+				return ErrNotFound
+			}
+		}
+		return nil
+	}
+
+	return authedMockDB(&MockDatabase{
+		getFestivalOwnersFunc: func(festivalID int64) ([]string, error) {
+			festivalOwners, ok := owners[festivalID]
+			if !ok {
+				return nil, ErrNotFound
+			}
+			return festivalOwners, nil
+		},
+		addOwnersFunc: func(festivalID int64, userIDs []string) error {
+			if err := checkOwners(festivalID, userIDs); err != nil {
+				return err
+			}
+			for _, userID := range userIDs {
+				if !slices.Contains(owners[festivalID], userID) {
+					owners[festivalID] = append(owners[festivalID], userID)
+				}
+			}
+			return nil
+		},
+		replaceOwnersFunc: func(festivalID int64, userIDs []string) error {
+			if err := checkOwners(festivalID, userIDs); err != nil {
+				return err
+			}
+			owners[festivalID] = userIDs
+			return nil
+		},
+	})
+}
+
+func TestFestivalOwnersHandler(t *testing.T) {
+	tests := []struct {
+		name   string
+		role   string
+		method string
+		path   string
+		body   string
+		status int
+		owners []string
+	}{
+		{"lists owners", RoleOrganizer, "GET", "/api/v1/festivals/1/owners", "", http.StatusOK, []string{"user-123"}},
+		{"adds a co-owner", RoleAdmin, "POST", "/api/v1/festivals/1/owners", `{"user_ids":["user-456"]}`, http.StatusCreated, []string{"user-123", "user-456"}},
+		{"ignores existing owners", RoleAdmin, "POST", "/api/v1/festivals/1/owners", `{"user_ids":["user-123"]}`, http.StatusCreated, []string{"user-123"}},
+		{"transfers ownership", RoleAdmin, "PUT", "/api/v1/festivals/1/owners", `{"user_ids":["user-456"]}`, http.StatusOK, []string{"user-456"}},
+		{"rejects an empty list", RoleAdmin, "PUT", "/api/v1/festivals/1/owners", `{"user_ids":[]}`, http.StatusBadRequest, nil},
+		{"rejects duplicate users", RoleAdmin, "POST", "/api/v1/festivals/1/owners", `{"user_ids":["user-456","user-456"]}`, http.StatusBadRequest, nil},
+		{"rejects an invalid body", RoleAdmin, "POST", "/api/v1/festivals/1/owners", `{`, http.StatusBadRequest, nil},
+		{"returns 404 for an unknown user", RoleAdmin, "POST", "/api/v1/festivals/1/owners", `{"user_ids":["unknown"]}`, http.StatusNotFound, nil},
+		{"returns 404 for an unknown festival", RoleAdmin, "GET", "/api/v1/festivals/999/owners", "", http.StatusNotFound, nil},
+		{"forbids organizers from adding co-owners", RoleOrganizer, "POST", "/api/v1/festivals/1/owners", `{"user_ids":["user-456"]}`, http.StatusForbidden, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer "+tt.role)
+			w := httptest.NewRecorder()
+
+			newTestRouter(newOwnersMockDB())(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+
+			if tt.owners == nil {
+				decodeProblem(t, w)
+				return
+			}
+
+			var response FestivalOwners
+			if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if response.FestivalID != 1 || !slices.Equal(response.Owners, tt.owners) {
+				t.Errorf("Expected festival 1 owned by %v, got %+v", tt.owners, response)
+			}
+		})
+	}
+}
+
+func TestMyFestivalsHandler(t *testing.T) {
+	t.Run("lists the festivals of the current user", func(t *testing.T) {
+		var requested string
+		mockDB := &MockDatabase{
+			verifyTokenFunc: func(token string) (*User, error) {
+				return &User{ID: "user-123", Roles: []string{RoleOrganizer}}, nil
+			},
+			getFestivalsByOwnerFunc: func(userID string) ([]Festival, error) {
+				requested = userID
+				return []Festival{{ID: 1, Name: "Test Festival", CreatedBy: userID}}, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/api/v1/me/festivals", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(mockDB)(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var festivals []Festival
+		if err := json.NewDecoder(w.Body).Decode(&festivals); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if requested != "user-123" || len(festivals) != 1 || festivals[0].CreatedBy != "user-123" {
+			t.Errorf("Expected the festivals of user-123, got %q %+v", requested, festivals)
+		}
+	})
+
+	t.Run("returns 401 without authorization header", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/me/festivals", nil)
+		w := httptest.NewRecorder()
+
+		newTestRouter(&MockDatabase{})(w, req)
+
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401, got %d", w.Code)
+		}
+	})
+
+	t.Run("returns 500 on database error", func(t *testing.T) {
+		mockDB := &MockDatabase{
+			verifyTokenFunc: func(token string) (*User, error) {
+				return &User{ID: "user-123"}, nil
+			},
+			getFestivalsByOwnerFunc: func(userID string) ([]Festival, error) {
+				return nil, &DatabaseError{Message: "database error"}
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/api/v1/me/festivals", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		newTestRouter(mockDB)(w, req)
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status 500, got %d", w.Code)
+		}
+	})
+}
+
+func TestListHandlersQueryParameters(t *testing.T) {
+	t.Run("passes festival filters and sets total count", func(t *testing.T) {
+		var received FestivalQuery
+		mockDB := &MockDatabase{
+			listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+				received = query
+				return []Festival{{ID: 1}}, 42, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/api/festivals?region=Bretagne&city=Rennes&from=2025-01-01&to=2025-12-31&upcoming=true&sort=startDate&order=desc&limit=10&offset=20", nil)
+		w := httptest.NewRecorder()
+
+		makeFestivalsHandler(mockDB, "*")(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		expected := FestivalQuery{Region: "Bretagne", City: "Rennes", From: "2025-01-01", To: "2025-12-31", Upcoming: true, Sort: "start_date", Desc: true, Limit: 10, Offset: 20}
+		if received != expected {
+			t.Errorf("Expected query %+v, got %+v", expected, received)
+		}
+
+		if w.Header().Get(HeaderTotalCount) != "42" {
+			t.Errorf("Expected %s 42, got %s", HeaderTotalCount, w.Header().Get(HeaderTotalCount))
+		}
+
+		if w.Header().Get(HeaderCORSExpose) != HeaderTotalCount {
+			t.Errorf("Expected %s to be exposed, got %s", HeaderTotalCount, w.Header().Get(HeaderCORSExpose))
+		}
+	})
+
+	t.Run("returns 400 for invalid festival query", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals?sort=population", nil)
+		w := httptest.NewRecorder()
+
+		makeFestivalsHandler(&MockDatabase{}, "*")(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+
+	t.Run("passes brewery filters and sets total count", func(t *testing.T) {
+		var received BreweryQuery
+		mockDB := &MockDatabase{
+			listBreweriesFunc: func(query BreweryQuery) ([]Brewery, int, error) {
+				received = query
+				return []Brewery{}, 7, nil
+			},
+		}
+
+		req := httptest.NewRequest("GET", "/api/breweries?city=Lille&sort=name&limit=5", nil)
+		w := httptest.NewRecorder()
+
+		makeBreweriesHandler(mockDB, "*")(w, req)
+
+		expected := BreweryQuery{City: "Lille", Sort: "name", Limit: 5}
+		if received != expected {
+			t.Errorf("Expected query %+v, got %+v", expected, received)
+		}
+
+		if w.Header().Get(HeaderTotalCount) != "7" {
+			t.Errorf("Expected %s 7, got %s", HeaderTotalCount, w.Header().Get(HeaderTotalCount))
+		}
+	})
+
+	t.Run("returns 400 for invalid brewery query", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/breweries?limit=1000", nil)
+		w := httptest.NewRecorder()
+
+		makeBreweriesHandler(&MockDatabase{}, "*")(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestNearbyFestivalsHandler(t *testing.T) {
+	var received FestivalQuery
+	mockDB := &MockDatabase{
+		listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+			received = query
+			return []Festival{
+				{ID: 1, Name: "Paris", Location: Location{Latitude: 48.8566, Longitude: 2.3522}},
+				{ID: 2, Name: "Roubaix", Location: Location{Latitude: 50.6927, Longitude: 3.1778}},
+				{ID: 3, Name: "Unknown", Location: Location{}},
+			}, 3, nil
+		},
+	}
+
+	t.Run("returns festivals within radius sorted by distance", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/nearby?lat=50.6292&lon=3.0573&radius_km=250&from=2025-10-01&to=2025-10-31&limit=10", nil)
+		w := httptest.NewRecorder()
+
+		makeNearbyFestivalsHandler(mockDB, "*")(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var nearby []NearbyFestival
+		if err := json.NewDecoder(w.Body).Decode(&nearby); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if len(nearby) != 2 || nearby[0].Name != "Roubaix" || nearby[1].Name != "Paris" {
+			t.Errorf("Expected Roubaix then Paris, got %+v", nearby)
+		}
+
+		if nearby[1].DistanceKm < 200 || nearby[1].DistanceKm > 210 {
+			t.Errorf("Expected Paris about 204 km away, got %.1f", nearby[1].DistanceKm)
+		}
+
+		if received.From != "2025-10-01" || received.To != "2025-10-31" || received.Limit != 0 {
+			t.Errorf("Expected date range without store pagination, got %+v", received)
+		}
+
+		if w.Header().Get(HeaderTotalCount) != "2" {
+			t.Errorf("Expected %s 2, got %s", HeaderTotalCount, w.Header().Get(HeaderTotalCount))
+		}
+	})
+
+	t.Run("uses default radius", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/nearby?lat=50.6292&lon=3.0573", nil)
+		w := httptest.NewRecorder()
+
+		makeNearbyFestivalsHandler(mockDB, "*")(w, req)
+
+		var nearby []NearbyFestival
+		json.NewDecoder(w.Body).Decode(&nearby)
+		if len(nearby) != 1 || nearby[0].Name != "Roubaix" {
+			t.Errorf("Expected only Roubaix within 50 km, got %+v", nearby)
+		}
+	})
+
+	t.Run("rejects invalid coordinates and radius", func(t *testing.T) {
+		for _, target := range []string{
+			"/api/festivals/nearby?lon=3.0573",
+			"/api/festivals/nearby?lat=91&lon=3.0573",
+			"/api/festivals/nearby?lat=50.6&lon=abc",
+			"/api/festivals/nearby?lat=50.6&lon=3.05&radius_km=0",
+			"/api/festivals/nearby?lat=50.6&lon=3.05&radius_km=5000",
+			"/api/festivals/nearby?lat=50.6&lon=3.05&from=yesterday",
+		} {
+			req := httptest.NewRequest("GET", target, nil)
+			w := httptest.NewRecorder()
+
+			makeNearbyFestivalsHandler(mockDB, "*")(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: Expected status 400, got %d", target, w.Code)
+			}
+		}
+	})
+}
+
+func TestFestivalMapHandler(t *testing.T) {
+	var received FestivalQuery
+	mockDB := &MockDatabase{
+		listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+			received = query
+			return []Festival{
+				{ID: 1, Name: "Lille", Location: Location{Latitude: 50.6292, Longitude: 3.0573}},
+				{ID: 2, Name: "Roubaix", Location: Location{Latitude: 50.6927, Longitude: 3.1778}},
+				{ID: 3, Name: "Paris", Location: Location{Latitude: 48.8566, Longitude: 2.3522}},
+			}, 3, nil
+		},
+	}
+
+	t.Run("returns clusters and festivals within bbox", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/map?bbox=-5,42,8,51.5&zoom=6&upcoming=true&limit=5", nil)
+		w := httptest.NewRecorder()
+
+		makeFestivalMapHandler(mockDB, "*")(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var result FestivalMap
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if len(result.Clusters) != 1 || result.Clusters[0].Count != 2 {
+			t.Errorf("Expected Lille and Roubaix clustered, got %+v", result.Clusters)
+		}
+		if len(result.Festivals) != 1 || result.Festivals[0].Name != "Paris" {
+			t.Errorf("Expected Paris on its own, got %+v", result.Festivals)
+		}
+
+		expected := BoundingBox{West: -5, South: 42, East: 8, North: 51.5}
+		if received.Bounds != expected || !received.Upcoming || received.Limit != 0 {
+			t.Errorf("Expected bbox and filters without pagination, got %+v", received)
+		}
+	})
+
+	t.Run("rejects invalid bbox and zoom", func(t *testing.T) {
+		for _, target := range []string{
+			"/api/festivals/map?zoom=6",
+			"/api/festivals/map?bbox=-5,42,8&zoom=6",
+			"/api/festivals/map?bbox=-5,42,8,51.5",
+			"/api/festivals/map?bbox=-5,42,8,51.5&zoom=30",
+			"/api/festivals/map?bbox=-5,42,8,51.5&zoom=6&from=yesterday",
+		} {
+			req := httptest.NewRequest("GET", target, nil)
+			w := httptest.NewRecorder()
+
+			makeFestivalMapHandler(mockDB, "*")(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("%s: Expected status 400, got %d", target, w.Code)
+			}
+		}
+	})
+}
+
+func TestFestivalsGeoJSONHandler(t *testing.T) {
+	var received FestivalQuery
+	mockDB := &MockDatabase{
+		listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+			received = query
+			return []Festival{
+				{ID: 1, Name: "Lille", Region: "Hauts-de-France", StartDate: time.Date(2025, 10, 1, 0, 0, 0, 0, time.UTC),
+					Location: Location{Latitude: 50.6292, Longitude: 3.0573}, BreweryCount: 4},
+				{ID: 2, Name: "Unknown", Region: "Hauts-de-France", Location: Location{}},
+			}, 42, nil
+		},
+	}
+
+	t.Run("returns a feature collection of located festivals", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals.geojson?region=Hauts-de-France&from=2025-10-01&to=2025-12-31", nil)
+		w := httptest.NewRecorder()
+
+		makeFestivalsGeoJSONHandler(mockDB, "*")(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		if w.Header().Get(HeaderContentType) != ContentTypeGeoJSON {
+			t.Errorf("Expected %s, got %s", ContentTypeGeoJSON, w.Header().Get(HeaderContentType))
+		}
+
+		var collection GeoJSONFeatureCollection
+		if err := json.NewDecoder(w.Body).Decode(&collection); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if collection.Type != "FeatureCollection" || len(collection.Features) != 1 {
+			t.Fatalf("Expected one feature, got %+v", collection)
+		}
+
+		feature := collection.Features[0]
+		if feature.Geometry.Type != "Point" || feature.Geometry.Coordinates[0] != 3.0573 || feature.Geometry.Coordinates[1] != 50.6292 {
+			t.Errorf("Expected a lon/lat point for Lille, got %+v", feature.Geometry)
+		}
+		if feature.Properties.Name != "Lille" || feature.Properties.BreweryCount != 4 || feature.Properties.StartDate != "2025-10-01" {
+			t.Errorf("Expected Lille properties, got %+v", feature.Properties)
+		}
+
+		if received.Region != "Hauts-de-France" || received.From != "2025-10-01" || received.To != "2025-12-31" {
+			t.Errorf("Expected region and date filters, got %+v", received)
+		}
+
+		if w.Header().Get(HeaderTotalCount) != "42" {
+			t.Errorf("Expected %s 42 from the store total, got %s", HeaderTotalCount, w.Header().Get(HeaderTotalCount))
+		}
+	})
+
+	t.Run("counts unmapped festivals in the total", func(t *testing.T) {
+		unmappedDB := &MockDatabase{
+			listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+				return []Festival{{ID: 2, Name: "Unknown", Region: "Hauts-de-France"}}, 1, nil
+			},
+		}
+		req := httptest.NewRequest("GET", "/api/festivals.geojson", nil)
+		w := httptest.NewRecorder()
+
+		makeFestivalsGeoJSONHandler(unmappedDB, "*")(w, req)
+
+		var collection GeoJSONFeatureCollection
+		if err := json.NewDecoder(w.Body).Decode(&collection); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if collection.Features == nil || len(collection.Features) != 0 {
+			t.Errorf("Expected an empty feature list, got %+v", collection.Features)
+		}
+
+		if w.Header().Get(HeaderTotalCount) != "1" {
+			t.Errorf("Expected %s 1 including the unmapped festival, got %q", HeaderTotalCount, w.Header().Get(HeaderTotalCount))
+		}
+	})
+
+	t.Run("rejects invalid filters", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals.geojson?from=yesterday", nil)
+		w := httptest.NewRecorder()
+
+		makeFestivalsGeoJSONHandler(mockDB, "*")(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestFestivalsICalHandler(t *testing.T) {
+	var received FestivalQuery
+	mockDB := &MockDatabase{
+		listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+			received = query
+			return []Festival{
+				{ID: 2, Name: "Rennes Craft", Region: "Bretagne",
+					StartDate: time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC)},
+			}, 12, nil
+		},
+	}
+
+	t.Run("returns a calendar filtered by region", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals.ics?region=Bretagne&upcoming=true", nil)
+		w := httptest.NewRecorder()
+
+		makeFestivalsICalHandler(mockDB, "*")(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		if w.Header().Get(HeaderContentType) != ContentTypeICal {
+			t.Errorf("Expected %s, got %s", ContentTypeICal, w.Header().Get(HeaderContentType))
+		}
+
+		if w.Header().Get(HeaderTotalCount) != "12" {
+			t.Errorf("Expected %s 12 from the store total, got %q", HeaderTotalCount, w.Header().Get(HeaderTotalCount))
+		}
+
+		if !strings.Contains(w.Body.String(), "UID:festival-2@beer-festival\r\n") {
+			t.Errorf("Expected festival 2 event, got %s", w.Body.String())
+		}
+
+		if received.Region != "Bretagne" || !received.Upcoming {
+			t.Errorf("Expected region and upcoming filters, got %+v", received)
+		}
+	})
+
+	t.Run("rejects invalid filters", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals.ics?to=later", nil)
+		w := httptest.NewRecorder()
+
+		makeFestivalsICalHandler(mockDB, "*")(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestFeedHandler(t *testing.T) {
+	var received FestivalQuery
+	mockDB := &MockDatabase{
+		listFestivalsFunc: func(query FestivalQuery) ([]Festival, int, error) {
+			received = query
+			return []Festival{
+				{ID: 3, Name: "Rennes Craft", CreatedAt: time.Date(2025, 9, 1, 10, 0, 0, 0, time.UTC), UpdatedAt: time.Date(2025, 9, 2, 10, 0, 0, 0, time.UTC)},
+			}, 1, nil
+		},
+	}
+
+	router := newRouter(mockDB, Config{AllowedOrigins: "*", FrontendURL: "https://festivals.example.com"})
+
+	t.Run("serves newest festivals as Atom", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/feeds/new.atom", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		if w.Header().Get(HeaderContentType) != ContentTypeAtom {
+			t.Errorf("Expected %s, got %s", ContentTypeAtom, w.Header().Get(HeaderContentType))
+		}
+
+		var feed AtomFeed
+		if err := xml.NewDecoder(w.Body).Decode(&feed); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if len(feed.Entries) != 1 || feed.Entries[0].Link.Href != "https://festivals.example.com/festival/3" {
+			t.Errorf("Expected entry linking to the festival page, got %+v", feed.Entries)
+		}
+
+		if received.Sort != "created_at" || !received.Desc || received.Limit != FeedSize {
+			t.Errorf("Expected newest festivals first, got %+v", received)
+		}
+	})
+
+	t.Run("serves upcoming festivals as RSS", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/feeds/upcoming.rss", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Header().Get(HeaderContentType) != ContentTypeRSS {
+			t.Errorf("Expected %s, got %s", ContentTypeRSS, w.Header().Get(HeaderContentType))
+		}
+
+		var feed RSSFeed
+		if err := xml.NewDecoder(w.Body).Decode(&feed); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if feed.Version != "2.0" || len(feed.Channel.Items) != 1 {
+			t.Errorf("Expected one RSS 2.0 item, got %+v", feed)
+		}
+
+		if !received.Upcoming || received.Sort != "start_date" || received.Desc {
+			t.Errorf("Expected soonest upcoming festivals first, got %+v", received)
+		}
+	})
+
+	t.Run("returns 404 for unknown feeds", func(t *testing.T) {
+		for _, target := range []string{"/api/feeds/", "/api/feeds/old.atom", "/api/feeds/new.json", "/api/feeds/new"} {
+			req := httptest.NewRequest("GET", target, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("%s: Expected status 404, got %d", target, w.Code)
+			}
+		}
+	})
+}
+
+func TestSearchHandler(t *testing.T) {
+	index := NewSearchIndex(&MockDatabase{
+		getFestivalsFunc: func() ([]Festival, error) {
+			return []Festival{{ID: 1, Name: "Fête de la Bière", City: "Lille"}, {ID: 2, Name: "Bières de Bretagne", City: "Rennes"}}, nil
+		},
+		getBreweriesFunc: func() ([]Brewery, error) {
+			return []Brewery{{ID: 1, Name: "Brasserie du Nord", Description: "Bières du Nord"}}, nil
+		},
+	})
+
+	t.Run("returns typed hits with the total", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/search?q=BIERE&limit=2", nil)
+		w := httptest.NewRecorder()
+
+		makeSearchHandler(index, "*")(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var hits []SearchHit
+		if err := json.NewDecoder(w.Body).Decode(&hits); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		if len(hits) != 2 || hits[0].Type != SearchTypeFestival || hits[0].ID != 1 {
+			t.Errorf("Expected the two festivals first, got %+v", hits)
+		}
+
+		if w.Header().Get(HeaderTotalCount) != "3" {
+			t.Errorf("Expected %s 3, got %s", HeaderTotalCount, w.Header().Get(HeaderTotalCount))
+		}
+	})
+
+	t.Run("rejects a missing query", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/search", nil)
+		w := httptest.NewRecorder()
+
+		makeSearchHandler(index, "*")(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
+
+func TestSuggestHandler(t *testing.T) {
+	index := NewSearchIndex(&MockDatabase{
+		getFestivalsFunc: func() ([]Festival, error) {
+			return []Festival{{ID: 1, City: "Lille", Region: "Hauts-de-France"}, {ID: 2, City: "Lille"}, {ID: 3, City: "Lyon"}}, nil
+		},
+		getBreweriesFunc: func() ([]Brewery, error) {
+			return []Brewery{{ID: 1, Name: "Brasserie du Nord", City: "Lens"}}, nil
+		},
+	})
+
+	t.Run("returns suggestions for every category", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/suggest?q=l&limit=2", nil)
+		w := httptest.NewRecorder()
+
+		makeSuggestHandler(index, "*")(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+
+		var suggestions map[string][]Suggestion
+		if err := json.NewDecoder(w.Body).Decode(&suggestions); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+
+		cities := suggestions[SuggestCategoryCity]
+		if len(cities) != 2 || cities[0] != (Suggestion{Value: "Lille", Count: 2}) || cities[1].Value != "Lens" {
+			t.Errorf("Expected Lille then Lens, got %+v", cities)
+		}
+
+		if suggestions[SuggestCategoryRegion] == nil || suggestions[SuggestCategoryBrewery] == nil {
+			t.Errorf("Expected every category in the response, got %+v", suggestions)
+		}
+	})
+
+	t.Run("rejects unknown categories", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/suggest?q=l&category=country", nil)
+		w := httptest.NewRecorder()
+
+		makeSuggestHandler(index, "*")(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
 		}
 	})
 }
@@ -0,0 +1,679 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memorySession struct {
+	userID           string
+	refreshToken     string
+	expiresAt        time.Time
+	refreshExpiresAt time.Time
+}
+
+type MemoryDatabase struct {
+	mu             sync.RWMutex
+	festivals      map[int64]FestivalDB
+	breweries      map[int64]BreweryDB
+	links          map[FestivalBrewery]struct{}
+	owners         map[FestivalOwner]struct{}
+	users          map[string]SeedUser
+	sessions       map[string]memorySession
+	nextFestivalID int64
+	nextBreweryID  int64
+}
+
+func LoadSeed(path string) (*Seed, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+
+	var seed Seed
+	if err := json.Unmarshal(data, &seed); err != nil {
+		return nil, fmt.Errorf("failed to parse seed file: %w", err)
+	}
+
+	return &seed, nil
+}
+
+func NewMemoryDatabase(seed *Seed) (*MemoryDatabase, error) {
+	db := &MemoryDatabase{
+		festivals: make(map[int64]FestivalDB),
+		breweries: make(map[int64]BreweryDB),
+		links:     make(map[FestivalBrewery]struct{}),
+		owners:    make(map[FestivalOwner]struct{}),
+		users:     make(map[string]SeedUser),
+		sessions:  make(map[string]memorySession),
+	}
+
+	now := timestamp(time.Now())
+	for _, festival := range seed.Festivals {
+		if _, exists := db.festivals[festival.ID]; exists {
+			return nil, fmt.Errorf("duplicate festival id %d in seed", festival.ID)
+		}
+		if festival.CreatedAt == "" {
+			festival.CreatedAt = now
+		}
+		if festival.UpdatedAt == "" {
+			festival.UpdatedAt = festival.CreatedAt
+		}
+		db.festivals[festival.ID] = festival
+		if festival.ID > db.nextFestivalID {
+			db.nextFestivalID = festival.ID
+		}
+	}
+
+	for _, brewery := range seed.Breweries {
+		if _, exists := db.breweries[brewery.ID]; exists {
+			return nil, fmt.Errorf("duplicate brewery id %d in seed", brewery.ID)
+		}
+		db.breweries[brewery.ID] = brewery
+		if brewery.ID > db.nextBreweryID {
+			db.nextBreweryID = brewery.ID
+		}
+	}
+
+	for _, link := range seed.FestivalsBreweries {
+		if _, ok := db.festivals[link.FestivalID]; !ok {
+			return nil, fmt.Errorf("seed links unknown festival %d", link.FestivalID)
+		}
+		if _, ok := db.breweries[link.BreweryID]; !ok {
+			return nil, fmt.Errorf("seed links unknown brewery %d", link.BreweryID)
+		}
+		db.links[link] = struct{}{}
+	}
+
+	for _, user := range seed.Users {
+		db.users[user.Email] = user
+	}
+
+	for _, owner := range seed.FestivalOwners {
+		if _, ok := db.festivals[owner.FestivalID]; !ok {
+			return nil, fmt.Errorf("seed owners reference unknown festival %d", owner.FestivalID)
+		}
+		if _, ok := db.findUser(owner.UserID); !ok {
+			return nil, fmt.Errorf("seed owners reference unknown user %s", owner.UserID)
+		}
+		db.owners[owner] = struct{}{}
+	}
+
+	return db, nil
+}
+
+func (m *MemoryDatabase) GetFestivals(ctx context.Context) ([]Festival, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	breweryCounts := make(map[int64]int)
+	for link := range m.links {
+		breweryCounts[link.FestivalID]++
+	}
+
+	festivals := make([]Festival, 0, len(m.festivals))
+	for _, fdb := range m.festivals {
+		festivals = append(festivals, festivalFromDB(fdb, breweryCounts[fdb.ID]))
+	}
+
+	sort.Slice(festivals, func(i, j int) bool { return festivals[i].ID < festivals[j].ID })
+	return festivals, nil
+}
+
+func (m *MemoryDatabase) ListFestivals(ctx context.Context, query FestivalQuery) ([]Festival, int, error) {
+	festivals, err := m.GetFestivals(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	today := time.Now().UTC().Format(DefaultTimeFormat)
+	filtered := []Festival{}
+	for _, festival := range festivals {
+		startDate := festival.StartDate.Format(DefaultTimeFormat)
+		endDate := festival.EndDate.Format(DefaultTimeFormat)
+		switch {
+		case query.Region != "" && festival.Region != query.Region,
+			query.City != "" && festival.City != query.City,
+			query.From != "" && endDate < query.From,
+			query.To != "" && startDate > query.To,
+			query.Upcoming && endDate < today,
+			query.Past && endDate >= today,
+			query.Bounds != (BoundingBox{}) && !query.Bounds.Contains(festival.Location):
+			continue
+		}
+		filtered = append(filtered, festival)
+	}
+
+	sortByColumn(filtered, query.Sort, query.Desc, func(festival Festival, column string) string {
+		switch column {
+		case "name":
+			return festival.Name
+		case "start_date":
+			return festival.StartDate.Format(DefaultTimeFormat)
+		case "end_date":
+			return festival.EndDate.Format(DefaultTimeFormat)
+		case "city":
+			return festival.City
+		case "region":
+			return festival.Region
+		case "created_at":
+			return timestamp(festival.CreatedAt)
+		case "updated_at":
+			return timestamp(festival.UpdatedAt)
+		}
+		return ""
+	})
+
+	return paginate(filtered, query.Limit, query.Offset), len(filtered), nil
+}
+
+func sortByColumn[T any](items []T, column string, desc bool, value func(T, string) string) {
+	if column == "" || column == "id" {
+		if desc {
+			for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+				items[i], items[j] = items[j], items[i]
+			}
+		}
+		return
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if desc {
+			return value(items[i], column) > value(items[j], column)
+		}
+		return value(items[i], column) < value(items[j], column)
+	})
+}
+
+func (m *MemoryDatabase) GetFestival(ctx context.Context, id int64) (*Festival, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	fdb, ok := m.festivals[id]
+	if !ok {
+		return nil, fmt.Errorf("festival %d: %w", id, ErrNotFound)
+	}
+
+	breweryCount := 0
+	for link := range m.links {
+		if link.FestivalID == id {
+			breweryCount++
+		}
+	}
+
+	festival := festivalFromDB(fdb, breweryCount)
+	return &festival, nil
+}
+
+func (m *MemoryDatabase) GetBreweries(ctx context.Context) ([]Brewery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	festivalCounts := make(map[int64]int)
+	for link := range m.links {
+		festivalCounts[link.BreweryID]++
+	}
+
+	breweries := make([]Brewery, 0, len(m.breweries))
+	for _, bdb := range m.breweries {
+		breweries = append(breweries, breweryFromDB(bdb, festivalCounts[bdb.ID]))
+	}
+
+	sort.Slice(breweries, func(i, j int) bool { return breweries[i].ID < breweries[j].ID })
+	return breweries, nil
+}
+
+func (m *MemoryDatabase) ListBreweries(ctx context.Context, query BreweryQuery) ([]Brewery, int, error) {
+	breweries, err := m.GetBreweries(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	filtered := []Brewery{}
+	for _, brewery := range breweries {
+		if query.City == "" || brewery.City == query.City {
+			filtered = append(filtered, brewery)
+		}
+	}
+
+	sortByColumn(filtered, query.Sort, query.Desc, func(brewery Brewery, column string) string {
+		switch column {
+		case "name":
+			return brewery.Name
+		case "city":
+			return brewery.City
+		}
+		return ""
+	})
+
+	return paginate(filtered, query.Limit, query.Offset), len(filtered), nil
+}
+
+func (m *MemoryDatabase) GetBreweriesByFestival(ctx context.Context, festivalID int64) ([]Brewery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	breweries := []Brewery{}
+	for link := range m.links {
+		if link.FestivalID == festivalID {
+			breweries = append(breweries, breweryFromDB(m.breweries[link.BreweryID], 0))
+		}
+	}
+
+	sort.Slice(breweries, func(i, j int) bool { return breweries[i].ID < breweries[j].ID })
+	return breweries, nil
+}
+
+func (m *MemoryDatabase) GetBrewery(ctx context.Context, id int64) (*Brewery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	bdb, ok := m.breweries[id]
+	if !ok {
+		return nil, fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+	}
+
+	festivalCount := 0
+	for link := range m.links {
+		if link.BreweryID == id {
+			festivalCount++
+		}
+	}
+
+	brewery := breweryFromDB(bdb, festivalCount)
+	return &brewery, nil
+}
+
+func (m *MemoryDatabase) GetFestivalsByBrewery(ctx context.Context, breweryID int64) ([]Festival, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	breweryCounts := make(map[int64]int)
+	for link := range m.links {
+		breweryCounts[link.FestivalID]++
+	}
+
+	festivals := []Festival{}
+	for link := range m.links {
+		if link.BreweryID == breweryID {
+			festivals = append(festivals, festivalFromDB(m.festivals[link.FestivalID], breweryCounts[link.FestivalID]))
+		}
+	}
+
+	sort.Slice(festivals, func(i, j int) bool { return festivals[i].ID < festivals[j].ID })
+	return festivals, nil
+}
+
+func (m *MemoryDatabase) CreateFestival(ctx context.Context, festival *FestivalDB) (*FestivalDB, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextFestivalID++
+	created := *festival
+	created.ID = m.nextFestivalID
+	created.CreatedAt = timestamp(time.Now())
+	created.UpdatedAt = created.CreatedAt
+	m.festivals[created.ID] = created
+	if created.CreatedBy != "" {
+		m.owners[FestivalOwner{FestivalID: created.ID, UserID: created.CreatedBy}] = struct{}{}
+	}
+
+	return &created, nil
+}
+
+func (m *MemoryDatabase) UpdateFestival(ctx context.Context, id int64, festival *FestivalDB) (*FestivalDB, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, ok := m.festivals[id]
+	if !ok {
+		return nil, fmt.Errorf("festival %d: %w", id, ErrNotFound)
+	}
+
+	updated := *festival
+	updated.ID = id
+	updated.CreatedBy = existing.CreatedBy
+	updated.CreatedAt = existing.CreatedAt
+	updated.UpdatedAt = timestamp(time.Now())
+	m.festivals[id] = updated
+
+	return &updated, nil
+}
+
+func (m *MemoryDatabase) DeleteFestival(ctx context.Context, id int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.festivals[id]; !ok {
+		return fmt.Errorf("festival %d: %w", id, ErrNotFound)
+	}
+
+	delete(m.festivals, id)
+	for link := range m.links {
+		if link.FestivalID == id {
+			delete(m.links, link)
+		}
+	}
+	for owner := range m.owners {
+		if owner.FestivalID == id {
+			delete(m.owners, owner)
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) CreateBrewery(ctx context.Context, brewery *BreweryDB) (*BreweryDB, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextBreweryID++
+	created := *brewery
+	created.ID = m.nextBreweryID
+	m.breweries[created.ID] = created
+
+	return &created, nil
+}
+
+func (m *MemoryDatabase) UpdateBrewery(ctx context.Context, id int64, brewery *BreweryDB) (*BreweryDB, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.breweries[id]; !ok {
+		return nil, fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+	}
+
+	updated := *brewery
+	updated.ID = id
+	m.breweries[id] = updated
+
+	return &updated, nil
+}
+
+func (m *MemoryDatabase) DeleteBrewery(ctx context.Context, id int64, cascade bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.breweries[id]; !ok {
+		return fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+	}
+
+	for link := range m.links {
+		if link.BreweryID != id {
+			continue
+		}
+		if !cascade {
+			return fmt.Errorf("brewery %d is linked to festivals: %w", id, ErrConflict)
+		}
+		delete(m.links, link)
+	}
+
+	delete(m.breweries, id)
+	return nil
+}
+
+func (m *MemoryDatabase) AddBreweriesToFestival(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkLineup(festivalID, breweryIDs, true); err != nil {
+		return err
+	}
+
+	for _, breweryID := range breweryIDs {
+		m.links[FestivalBrewery{FestivalID: festivalID, BreweryID: breweryID}] = struct{}{}
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) RemoveBreweryFromFestival(ctx context.Context, festivalID, breweryID int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	link := FestivalBrewery{FestivalID: festivalID, BreweryID: breweryID}
+	if _, ok := m.links[link]; !ok {
+		return fmt.Errorf("brewery %d in festival %d: %w", breweryID, festivalID, ErrNotFound)
+	}
+
+	delete(m.links, link)
+	return nil
+}
+
+func (m *MemoryDatabase) ReplaceFestivalBreweries(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkLineup(festivalID, breweryIDs, false); err != nil {
+		return err
+	}
+
+	for link := range m.links {
+		if link.FestivalID == festivalID {
+			delete(m.links, link)
+		}
+	}
+
+	for _, breweryID := range breweryIDs {
+		m.links[FestivalBrewery{FestivalID: festivalID, BreweryID: breweryID}] = struct{}{}
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) checkLineup(festivalID int64, breweryIDs []int64, rejectLinked bool) error {
+	if _, ok := m.festivals[festivalID]; !ok {
+		return fmt.Errorf("festival %d: %w", festivalID, ErrNotFound)
+	}
+
+	seen := make(map[int64]bool, len(breweryIDs))
+	for _, breweryID := range breweryIDs {
+		if _, ok := m.breweries[breweryID]; !ok {
+			return fmt.Errorf("brewery %d: %w", breweryID, ErrNotFound)
+		}
+
+		_, linked := m.links[FestivalBrewery{FestivalID: festivalID, BreweryID: breweryID}]
+		if seen[breweryID] || (rejectLinked && linked) {
+			return fmt.Errorf("brewery %d is already in festival %d: %w", breweryID, festivalID, ErrConflict)
+		}
+		seen[breweryID] = true
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) GetFestivalOwners(ctx context.Context, festivalID int64) ([]string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if _, ok := m.festivals[festivalID]; !ok {
+		return nil, fmt.Errorf("festival %d: %w", festivalID, ErrNotFound)
+	}
+
+	owners := []string{}
+	for owner := range m.owners {
+		if owner.FestivalID == festivalID {
+			owners = append(owners, owner.UserID)
+		}
+	}
+
+	sort.Strings(owners)
+	return owners, nil
+}
+
+func (m *MemoryDatabase) GetFestivalsByOwner(ctx context.Context, userID string) ([]Festival, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	breweryCounts := make(map[int64]int)
+	for link := range m.links {
+		breweryCounts[link.FestivalID]++
+	}
+
+	festivals := []Festival{}
+	for owner := range m.owners {
+		if owner.UserID == userID {
+			festivals = append(festivals, festivalFromDB(m.festivals[owner.FestivalID], breweryCounts[owner.FestivalID]))
+		}
+	}
+
+	sort.Slice(festivals, func(i, j int) bool { return festivals[i].ID < festivals[j].ID })
+	return festivals, nil
+}
+
+func (m *MemoryDatabase) AddFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkOwners(festivalID, userIDs); err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		m.owners[FestivalOwner{FestivalID: festivalID, UserID: userID}] = struct{}{}
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) ReplaceFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkOwners(festivalID, userIDs); err != nil {
+		return err
+	}
+
+	for owner := range m.owners {
+		if owner.FestivalID == festivalID {
+			delete(m.owners, owner)
+		}
+	}
+
+	for _, userID := range userIDs {
+		m.owners[FestivalOwner{FestivalID: festivalID, UserID: userID}] = struct{}{}
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) checkOwners(festivalID int64, userIDs []string) error {
+	if _, ok := m.festivals[festivalID]; !ok {
+		return fmt.Errorf("festival %d: %w", festivalID, ErrNotFound)
+	}
+
+	for _, userID := range userIDs {
+		if _, ok := m.findUser(userID); !ok {
+			return fmt.Errorf("user %s: %w", userID, ErrNotFound)
+		}
+	}
+
+	return nil
+}
+
+func (m *MemoryDatabase) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[email]
+	if !ok || subtle.ConstantTimeCompare([]byte(user.Password), []byte(password)) != 1 {
+		return nil, fmt.Errorf("authentication failed: invalid credentials")
+	}
+
+	return m.createSession(user.user())
+}
+
+func (m *MemoryDatabase) RefreshSession(ctx context.Context, refreshToken string) (*LoginResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for accessToken, session := range m.sessions {
+		if session.refreshToken != refreshToken || time.Now().After(session.refreshExpiresAt) {
+			continue
+		}
+
+		user, ok := m.findUser(session.userID)
+		if !ok {
+			return nil, fmt.Errorf("unknown user: %w", ErrInvalidToken)
+		}
+
+		delete(m.sessions, accessToken)
+		return m.createSession(user)
+	}
+
+	return nil, fmt.Errorf("unknown refresh token: %w", ErrInvalidToken)
+}
+
+func (m *MemoryDatabase) Logout(ctx context.Context, token string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.sessions[token]; !ok {
+		return fmt.Errorf("unknown session: %w", ErrInvalidToken)
+	}
+
+	delete(m.sessions, token)
+	return nil
+}
+
+func (m *MemoryDatabase) createSession(user User) (*LoginResponse, error) {
+	accessToken, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	response := &LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         user,
+	}
+
+	m.sessions[response.AccessToken] = memorySession{
+		userID:           user.ID,
+		refreshToken:     response.RefreshToken,
+		expiresAt:        now.Add(SessionDuration),
+		refreshExpiresAt: now.Add(RefreshTokenDuration),
+	}
+	return response, nil
+}
+
+func (u SeedUser) user() User {
+	return User{ID: u.ID, Email: u.Email, Roles: normalizeRoles(u.Roles)}
+}
+
+func (m *MemoryDatabase) findUser(id string) (User, bool) {
+	for _, user := range m.users {
+		if user.ID == id {
+			return user.user(), true
+		}
+	}
+	return User{}, false
+}
+
+func (m *MemoryDatabase) VerifyToken(ctx context.Context, token string) (*User, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, ok := m.sessions[token]
+	if !ok || time.Now().After(session.expiresAt) {
+		return nil, fmt.Errorf("unknown or expired session: %w", ErrInvalidToken)
+	}
+
+	user, ok := m.findUser(session.userID)
+	if !ok {
+		return nil, fmt.Errorf("unknown user: %w", ErrInvalidToken)
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,669 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func newTestSeed() *Seed {
+	return &Seed{
+		Festivals: []FestivalDB{
+			{ID: 1, Name: "Lille Beer Fest", StartDate: "2025-10-01", EndDate: "2025-10-03", City: "Lille", Region: "Hauts-de-France", Latitude: 50.63, Longitude: 3.06},
+			{ID: 2, Name: "Rennes Craft", StartDate: "2025-11-01", EndDate: "2025-11-02", City: "Rennes", Region: "Bretagne"},
+		},
+		Breweries: []BreweryDB{
+			{ID: 1, Name: "Brasserie du Nord", City: "Lille"},
+			{ID: 2, Name: "Brasserie de Bretagne", City: "Rennes"},
+		},
+		FestivalsBreweries: []FestivalBrewery{
+			{FestivalID: 1, BreweryID: 1},
+			{FestivalID: 1, BreweryID: 2},
+			{FestivalID: 2, BreweryID: 2},
+		},
+		Users: []SeedUser{
+			{ID: "user-123", Email: "test@example.com", Password: "password123", Roles: []string{RoleOrganizer}},
+		},
+	}
+}
+
+func newTestMemoryDatabase(t *testing.T) *MemoryDatabase {
+	t.Helper()
+
+	db, err := NewMemoryDatabase(newTestSeed())
+	if err != nil {
+		t.Fatalf("Failed to create memory database: %v", err)
+	}
+
+	return db
+}
+
+func TestLoadSeed(t *testing.T) {
+	t.Run("loads the bundled seed file", func(t *testing.T) {
+		seed, err := LoadSeed("seed.json")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(seed.Festivals) == 0 {
+			t.Error("Expected seed festivals")
+		}
+
+		if _, err := NewMemoryDatabase(seed); err != nil {
+			t.Errorf("Expected bundled seed to be valid, got %v", err)
+		}
+	})
+
+	t.Run("returns error for missing file", func(t *testing.T) {
+		_, err := LoadSeed(filepath.Join(t.TempDir(), "missing.json"))
+
+		if err == nil {
+			t.Error("Expected error for missing file, got nil")
+		}
+	})
+
+	t.Run("returns error for invalid JSON", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "seed.json")
+		os.WriteFile(path, []byte("not json"), 0o644)
+
+		_, err := LoadSeed(path)
+
+		if err == nil {
+			t.Error("Expected error for invalid JSON, got nil")
+		}
+	})
+}
+
+func TestNewMemoryDatabase(t *testing.T) {
+	t.Run("rejects duplicate festival ids", func(t *testing.T) {
+		seed := newTestSeed()
+		seed.Festivals = append(seed.Festivals, FestivalDB{ID: 1, Name: "Duplicate"})
+
+		_, err := NewMemoryDatabase(seed)
+
+		if err == nil {
+			t.Error("Expected error for duplicate festival id, got nil")
+		}
+	})
+
+	t.Run("rejects links to unknown breweries", func(t *testing.T) {
+		seed := newTestSeed()
+		seed.FestivalsBreweries = append(seed.FestivalsBreweries, FestivalBrewery{FestivalID: 1, BreweryID: 99})
+
+		_, err := NewMemoryDatabase(seed)
+
+		if err == nil {
+			t.Error("Expected error for unknown brewery link, got nil")
+		}
+	})
+
+	t.Run("rejects owners that are not seeded users", func(t *testing.T) {
+		seed := newTestSeed()
+		seed.FestivalOwners = append(seed.FestivalOwners, FestivalOwner{FestivalID: 1, UserID: "user-999"})
+
+		_, err := NewMemoryDatabase(seed)
+
+		if err == nil {
+			t.Error("Expected error for unknown owner, got nil")
+		}
+	})
+}
+
+func TestMemoryDatabaseFestivals(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns festivals with brewery counts", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		festivals, err := db.GetFestivals(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(festivals) != 2 {
+			t.Fatalf("Expected 2 festivals, got %d", len(festivals))
+		}
+
+		if festivals[0].ID != 1 || festivals[0].BreweryCount != 2 {
+			t.Errorf("Expected festival 1 with 2 breweries, got %+v", festivals[0])
+		}
+
+		if festivals[1].BreweryCount != 1 {
+			t.Errorf("Expected 1 brewery for second festival, got %d", festivals[1].BreweryCount)
+		}
+	})
+
+	t.Run("returns a single festival with its brewery count", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		festival, err := db.GetFestival(ctx, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if festival.Name != "Rennes Craft" || festival.BreweryCount != 1 {
+			t.Errorf("Expected Rennes Craft with 1 brewery, got %+v", festival)
+		}
+	})
+
+	t.Run("returns ErrNotFound for unknown festival", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		_, err := db.GetFestival(ctx, 999)
+
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("creates festival with next id", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		created, err := db.CreateFestival(ctx, &FestivalDB{Name: "New Festival", StartDate: "2025-12-01", EndDate: "2025-12-02"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if created.ID != 3 {
+			t.Errorf("Expected festival ID 3, got %d", created.ID)
+		}
+
+		festivals, _ := db.GetFestivals(ctx)
+		if len(festivals) != 3 {
+			t.Errorf("Expected 3 festivals after creation, got %d", len(festivals))
+		}
+	})
+}
+
+func TestMemoryDatabaseListing(t *testing.T) {
+	ctx := context.Background()
+	db := newTestMemoryDatabase(t)
+
+	t.Run("filters festivals and reports the total", func(t *testing.T) {
+		festivals, total, err := db.ListFestivals(ctx, FestivalQuery{City: "Lille"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if total != 1 || len(festivals) != 1 || festivals[0].ID != 1 || festivals[0].BreweryCount != 2 {
+			t.Errorf("Expected Lille festival only, got %d %+v", total, festivals)
+		}
+	})
+
+	t.Run("sorts and paginates festivals", func(t *testing.T) {
+		festivals, total, _ := db.ListFestivals(ctx, FestivalQuery{Sort: "name", Limit: 1, Offset: 1})
+
+		if total != 2 || len(festivals) != 1 || festivals[0].Name != "Rennes Craft" {
+			t.Errorf("Expected Rennes Craft on the second page, got %d %+v", total, festivals)
+		}
+
+		festivals, _, _ = db.ListFestivals(ctx, FestivalQuery{Offset: 5})
+		if festivals == nil || len(festivals) != 0 {
+			t.Errorf("Expected empty page past the end, got %+v", festivals)
+		}
+	})
+
+	t.Run("filters festivals inside a bounding box", func(t *testing.T) {
+		festivals, total, _ := db.ListFestivals(ctx, FestivalQuery{Bounds: BoundingBox{West: 2, South: 50, East: 4, North: 51}})
+
+		if total != 1 || len(festivals) != 1 || festivals[0].Name != "Lille Beer Fest" {
+			t.Errorf("Expected Lille Beer Fest only, got %d %+v", total, festivals)
+		}
+	})
+
+	t.Run("sorts breweries by id descending", func(t *testing.T) {
+		breweries, total, _ := db.ListBreweries(ctx, BreweryQuery{Sort: "id", Desc: true})
+
+		if total != 2 || breweries[0].ID != 2 {
+			t.Errorf("Expected brewery 2 first, got %+v", breweries)
+		}
+	})
+}
+
+func TestMemoryDatabaseFestivalWrites(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("updates festival", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		_, err := db.UpdateFestival(ctx, 1, &FestivalDB{Name: "Renamed", StartDate: "2025-10-01", EndDate: "2025-10-04"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, 1)
+		if festival.Name != "Renamed" || festival.BreweryCount != 2 {
+			t.Errorf("Expected renamed festival keeping its breweries, got %+v", festival)
+		}
+	})
+
+	t.Run("records creation and update timestamps", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		created, err := db.CreateFestival(ctx, &FestivalDB{Name: "New Fest", StartDate: "2025-12-01", EndDate: "2025-12-02", CreatedAt: "2000-01-01T00:00:00Z"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, created.ID)
+		if festival.CreatedAt.Year() < 2025 || !festival.UpdatedAt.Equal(festival.CreatedAt) {
+			t.Errorf("Expected store-set timestamps, got %v %v", festival.CreatedAt, festival.UpdatedAt)
+		}
+
+		updated, err := db.UpdateFestival(ctx, created.ID, &FestivalDB{Name: "Renamed", StartDate: "2025-12-01", EndDate: "2025-12-02"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if updated.CreatedAt != created.CreatedAt || updated.UpdatedAt == "" {
+			t.Errorf("Expected creation time kept and update time set, got %+v", updated)
+		}
+	})
+
+	t.Run("returns ErrNotFound when updating unknown festival", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		_, err := db.UpdateFestival(ctx, 999, &FestivalDB{Name: "Renamed"})
+
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("deletes festival and its lineup", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		if err := db.DeleteFestival(ctx, 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		brewery, _ := db.GetBrewery(ctx, 1)
+		if brewery.FestivalCount != 0 {
+			t.Errorf("Expected lineup links to be removed, got %d festivals", brewery.FestivalCount)
+		}
+
+		if err := db.DeleteFestival(ctx, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound on second delete, got %v", err)
+		}
+	})
+}
+
+func TestMemoryDatabaseBreweries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns breweries with festival counts", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		breweries, err := db.GetBreweries(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(breweries) != 2 {
+			t.Fatalf("Expected 2 breweries, got %d", len(breweries))
+		}
+
+		if breweries[1].FestivalCount != 2 {
+			t.Errorf("Expected 2 festivals for second brewery, got %d", breweries[1].FestivalCount)
+		}
+	})
+
+	t.Run("returns breweries for a festival", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		breweries, err := db.GetBreweriesByFestival(ctx, 1)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(breweries) != 2 {
+			t.Errorf("Expected 2 breweries, got %d", len(breweries))
+		}
+	})
+
+	t.Run("returns a single brewery with its festival count", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		brewery, err := db.GetBrewery(ctx, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if brewery.FestivalCount != 2 {
+			t.Errorf("Expected 2 festivals, got %d", brewery.FestivalCount)
+		}
+	})
+
+	t.Run("returns ErrNotFound for unknown brewery", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		_, err := db.GetBrewery(ctx, 999)
+
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns festivals attended by a brewery", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		festivals, err := db.GetFestivalsByBrewery(ctx, 1)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(festivals) != 1 || festivals[0].ID != 1 {
+			t.Errorf("Expected festival 1, got %+v", festivals)
+		}
+	})
+
+	t.Run("returns empty list for unknown festival", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		breweries, err := db.GetBreweriesByFestival(ctx, 999)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(breweries) != 0 {
+			t.Errorf("Expected no breweries, got %d", len(breweries))
+		}
+	})
+}
+
+func TestMemoryDatabaseBreweryWrites(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates brewery with next id", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		created, err := db.CreateBrewery(ctx, &BreweryDB{Name: "New Brewery", City: "Lyon"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if created.ID != 3 {
+			t.Errorf("Expected ID 3, got %d", created.ID)
+		}
+	})
+
+	t.Run("updates brewery", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		if _, err := db.UpdateBrewery(ctx, 1, &BreweryDB{Name: "Renamed Brewery", City: "Lille"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		brewery, _ := db.GetBrewery(ctx, 1)
+		if brewery.Name != "Renamed Brewery" || brewery.FestivalCount != 1 {
+			t.Errorf("Expected renamed brewery keeping its festivals, got %+v", brewery)
+		}
+
+		if _, err := db.UpdateBrewery(ctx, 999, &BreweryDB{Name: "Renamed Brewery"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("refuses to delete linked brewery without cascade", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		if err := db.DeleteBrewery(ctx, 2, false); !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, 1)
+		if festival.BreweryCount != 2 {
+			t.Errorf("Expected lineup to be untouched, got %d breweries", festival.BreweryCount)
+		}
+	})
+
+	t.Run("deletes linked brewery with cascade", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		if err := db.DeleteBrewery(ctx, 2, true); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, 1)
+		if festival.BreweryCount != 1 {
+			t.Errorf("Expected one remaining brewery, got %d", festival.BreweryCount)
+		}
+
+		if err := db.DeleteBrewery(ctx, 2, true); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound on second delete, got %v", err)
+		}
+	})
+}
+
+func TestMemoryDatabaseLineups(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("adds breweries and updates counts", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		if err := db.AddBreweriesToFestival(ctx, 2, []int64{1}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, 2)
+		brewery, _ := db.GetBrewery(ctx, 1)
+		if festival.BreweryCount != 2 || brewery.FestivalCount != 2 {
+			t.Errorf("Expected counts of 2, got festival %d and brewery %d", festival.BreweryCount, brewery.FestivalCount)
+		}
+	})
+
+	t.Run("rejects duplicate links", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		if err := db.AddBreweriesToFestival(ctx, 2, []int64{1, 2}); !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, 2)
+		if festival.BreweryCount != 1 {
+			t.Errorf("Expected lineup to be untouched, got %d breweries", festival.BreweryCount)
+		}
+	})
+
+	t.Run("replaces lineup atomically", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		if err := db.ReplaceFestivalBreweries(ctx, 1, []int64{2, 999}); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+
+		if err := db.ReplaceFestivalBreweries(ctx, 1, []int64{2}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		breweries, _ := db.GetBreweriesByFestival(ctx, 1)
+		if len(breweries) != 1 || breweries[0].ID != 2 {
+			t.Errorf("Expected lineup with brewery 2, got %+v", breweries)
+		}
+	})
+
+	t.Run("removes brewery from lineup", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		if err := db.RemoveBreweryFromFestival(ctx, 1, 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if err := db.RemoveBreweryFromFestival(ctx, 1, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound on second removal, got %v", err)
+		}
+	})
+}
+
+func TestMemoryDatabaseOwners(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("makes the creator the first owner", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		created, err := db.CreateFestival(ctx, &FestivalDB{Name: "Owned", StartDate: "2025-12-01", EndDate: "2025-12-02", CreatedBy: "user-123"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		owners, err := db.GetFestivalOwners(ctx, created.ID)
+		if err != nil || !slices.Equal(owners, []string{"user-123"}) {
+			t.Errorf("Expected owner user-123, got %v, %v", owners, err)
+		}
+
+		updated, _ := db.UpdateFestival(ctx, created.ID, &FestivalDB{Name: "Renamed", StartDate: "2025-12-01", EndDate: "2025-12-02"})
+		if updated.CreatedBy != "user-123" {
+			t.Errorf("Expected updates to keep the creator, got %q", updated.CreatedBy)
+		}
+
+		festivals, _ := db.GetFestivalsByOwner(ctx, "user-123")
+		if len(festivals) != 1 || festivals[0].ID != created.ID {
+			t.Errorf("Expected festival %d for user-123, got %+v", created.ID, festivals)
+		}
+	})
+
+	t.Run("adds and replaces owners", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		if err := db.AddFestivalOwners(ctx, 1, []string{"user-999"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for unknown user, got %v", err)
+		}
+		if err := db.AddFestivalOwners(ctx, 999, []string{"user-123"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for unknown festival, got %v", err)
+		}
+
+		if err := db.AddFestivalOwners(ctx, 1, []string{"user-123"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := db.ReplaceFestivalOwners(ctx, 2, []string{"user-123"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		festivals, _ := db.GetFestivalsByOwner(ctx, "user-123")
+		if len(festivals) != 2 {
+			t.Errorf("Expected two festivals for user-123, got %+v", festivals)
+		}
+	})
+
+	t.Run("forgets owners of deleted festivals", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+		db.AddFestivalOwners(ctx, 1, []string{"user-123"})
+
+		if err := db.DeleteFestival(ctx, 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if _, err := db.GetFestivalOwners(ctx, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+		if festivals, _ := db.GetFestivalsByOwner(ctx, "user-123"); len(festivals) != 0 {
+			t.Errorf("Expected no festivals for user-123, got %+v", festivals)
+		}
+	})
+}
+
+func TestMemoryDatabaseAuth(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("logs in seeded user and verifies token", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		loginResp, err := db.Login(ctx, "test@example.com", "password123")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		user, err := db.VerifyToken(ctx, loginResp.AccessToken)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if user.ID != "user-123" {
+			t.Errorf("Expected user-123, got %s", user.ID)
+		}
+
+		if !slices.Equal(loginResp.User.Roles, []string{RoleOrganizer}) || !slices.Equal(user.Roles, []string{RoleOrganizer}) {
+			t.Errorf("Expected the seeded organizer role, got %v and %v", loginResp.User.Roles, user.Roles)
+		}
+	})
+
+	t.Run("rejects wrong password", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		_, err := db.Login(ctx, "test@example.com", "wrongpassword")
+
+		if err == nil {
+			t.Error("Expected error for wrong password, got nil")
+		}
+	})
+
+	t.Run("rejects unknown token", func(t *testing.T) {
+		db := newTestMemoryDatabase(t)
+
+		_, err := db.VerifyToken(ctx, "not-a-token")
+
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken for unknown token, got %v", err)
+		}
+	})
+
+	db := newTestMemoryDatabase(t)
+
+	t.Run("rotates tokens on refresh", func(t *testing.T) {
+		loginResp, err := db.Login(ctx, "test@example.com", "password123")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		refreshed, err := db.RefreshSession(ctx, loginResp.RefreshToken)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if refreshed.AccessToken == loginResp.AccessToken || refreshed.RefreshToken == loginResp.RefreshToken {
+			t.Error("Expected a new token pair")
+		}
+
+		if refreshed.User.ID != "user-123" {
+			t.Errorf("Expected user-123, got %v", refreshed.User)
+		}
+
+		if _, err := db.VerifyToken(ctx, refreshed.AccessToken); err != nil {
+			t.Errorf("Expected refreshed token to verify, got %v", err)
+		}
+
+		if _, err := db.VerifyToken(ctx, loginResp.AccessToken); err == nil {
+			t.Error("Expected the old access token to be revoked")
+		}
+
+		if _, err := db.RefreshSession(ctx, loginResp.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken when reusing a refresh token, got %v", err)
+		}
+	})
+
+	t.Run("revokes the session on logout", func(t *testing.T) {
+		loginResp, err := db.Login(ctx, "test@example.com", "password123")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if err := db.Logout(ctx, loginResp.AccessToken); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if _, err := db.VerifyToken(ctx, loginResp.AccessToken); err == nil {
+			t.Error("Expected the access token to be revoked")
+		}
+
+		if _, err := db.RefreshSession(ctx, loginResp.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken for a revoked refresh token, got %v", err)
+		}
+
+		if err := db.Logout(ctx, loginResp.AccessToken); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken on second logout, got %v", err)
+		}
+	})
+}
@@ -5,13 +5,23 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 )
 
+type contextKey string
+
+const userContextKey contextKey = "user"
+
+var requestIDCounter atomic.Uint64
+
 type ResponseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -42,8 +52,8 @@ func metricsMiddleware(next http.Handler) http.Handler {
 
 		duration := time.Since(start)
 		requestID := r.Context().Value("requestID")
-		log.Printf("method=%s path=%s status=%d duration=%v request_id=%v",
-			r.Method, r.URL.Path, wrapped.statusCode, duration, requestID)
+		log.Printf("method=%s path=%s status=%d duration=%v request_id=%v cache_hits=%d cache_misses=%d",
+			r.Method, r.URL.Path, wrapped.statusCode, duration, requestID, cacheStats.Hits(), cacheStats.Misses())
 	})
 }
 
@@ -61,9 +71,143 @@ func requestIDMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+func timeoutMiddleware(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if timeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func requireAuth(db DatabaseInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "OPTIONS" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, ok := authenticate(w, r, db)
+			if !ok {
+				return
+			}
+
+			ctx := context.WithValue(r.Context(), userContextKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func requirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "OPTIONS" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, ok := userFromContext(r.Context())
+			if !ok || !user.Can(permission) {
+				writeProblem(w, r, http.StatusForbidden, ErrorCodeForbidden, "This action requires the "+permission+" permission")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func requireFestivalOwner(db DatabaseInterface) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "OPTIONS" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			festivalID, valid := parsePathID(w, r, "id", "Festival")
+			if !valid {
+				return
+			}
+
+			user, ok := userFromContext(r.Context())
+			if ok && user.Can(PermissionFestivalsManageAny) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			owners, err := db.GetFestivalOwners(r.Context(), festivalID)
+			if errors.Is(err, ErrNotFound) {
+				writeProblem(w, r, http.StatusNotFound, ErrorCodeFestivalNotFound, "Festival not found")
+				return
+			}
+			if err != nil {
+				log.Printf("Error fetching owners of festival %d: %v", festivalID, err)
+				writeDatabaseError(w, r, err)
+				return
+			}
+
+			if !ok || !slices.Contains(owners, user.ID) {
+				writeProblem(w, r, http.StatusForbidden, ErrorCodeNotFestivalOwner, "This action is restricted to the festival's owners")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func authenticate(w http.ResponseWriter, r *http.Request, db DatabaseInterface) (*User, bool) {
+	authHeader := r.Header.Get("Authorization")
+	if authHeader == "" {
+		writeUnauthorized(w, r, ErrorCodeUnauthorized, "Authorization header required", AuthChallenge)
+		return nil, false
+	}
+
+	token, ok := bearerToken(r)
+	if !ok {
+		writeUnauthorized(w, r, ErrorCodeUnauthorized, "Invalid authorization format", AuthChallenge+`, error="invalid_request"`)
+		return nil, false
+	}
+
+	user, err := db.VerifyToken(r.Context(), token)
+	if err != nil {
+		log.Printf("Token verification failed: %v", err)
+		if isTimeout(err) {
+			writeDatabaseError(w, r, err)
+			return nil, false
+		}
+		writeUnauthorized(w, r, ErrorCodeInvalidToken, "Invalid token", AuthChallenge+`, error="invalid_token"`)
+		return nil, false
+	}
+
+	return user, true
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	authHeader := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(authHeader, "Bearer ")
+	return token, token != authHeader && token != ""
+}
+
+func userFromContext(ctx context.Context) (*User, bool) {
+	user, ok := ctx.Value(userContextKey).(*User)
+	return user, ok && user != nil
+}
+
 func generateRequestID() string {
 	b := make([]byte, 16)
-	rand.Read(b)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x-%x", time.Now().UnixNano(), requestIDCounter.Add(1))
+	}
 	return hex.EncodeToString(b)
 }
 
@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRequestIDMiddleware(t *testing.T) {
@@ -118,6 +119,43 @@ func TestMetricsMiddleware(t *testing.T) {
 	})
 }
 
+func TestTimeoutMiddleware(t *testing.T) {
+	t.Run("sets a deadline on the request context", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			deadline, ok := r.Context().Deadline()
+			if !ok {
+				t.Error("Expected request context to have a deadline")
+			}
+			if time.Until(deadline) > time.Second {
+				t.Errorf("Expected deadline within 1s, got %v", time.Until(deadline))
+			}
+			w.WriteHeader(http.StatusOK)
+		})
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+
+		timeoutMiddleware(time.Second)(handler).ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+
+	t.Run("leaves context untouched when timeout is zero", func(t *testing.T) {
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := r.Context().Deadline(); ok {
+				t.Error("Expected no deadline on request context")
+			}
+		})
+
+		req := httptest.NewRequest("GET", "/test", nil)
+		w := httptest.NewRecorder()
+
+		timeoutMiddleware(0)(handler).ServeHTTP(w, req)
+	})
+}
+
 func TestGzipMiddleware(t *testing.T) {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -225,6 +263,244 @@ func TestChainMiddleware(t *testing.T) {
 	})
 }
 
+func TestRequireAuth(t *testing.T) {
+	mockDB := &MockDatabase{
+		verifyTokenFunc: func(token string) (*User, error) {
+			if token == "valid-token" {
+				return &User{ID: "user-123", Email: "test@example.com"}, nil
+			}
+			return nil, &DatabaseError{Message: "invalid token"}
+		},
+	}
+
+	var seen *User
+	handler := requireAuth(mockDB)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = userFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	t.Run("stores the verified user in the context", func(t *testing.T) {
+		seen = nil
+		req := httptest.NewRequest("DELETE", "/api/v1/festivals/1", nil)
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		if seen == nil || seen.ID != "user-123" {
+			t.Errorf("Expected user-123 in context, got %+v", seen)
+		}
+	})
+
+	tests := []struct {
+		name          string
+		authorization string
+		code          string
+		challenge     string
+	}{
+		{"missing header", "", ErrorCodeUnauthorized, AuthChallenge},
+		{"missing Bearer prefix", "valid-token", ErrorCodeUnauthorized, AuthChallenge + `, error="invalid_request"`},
+		{"empty token", "Bearer ", ErrorCodeUnauthorized, AuthChallenge + `, error="invalid_request"`},
+		{"invalid token", "Bearer invalid-token", ErrorCodeInvalidToken, AuthChallenge + `, error="invalid_token"`},
+	}
+
+	for _, tt := range tests {
+		t.Run("rejects "+tt.name, func(t *testing.T) {
+			seen = nil
+			req := httptest.NewRequest("DELETE", "/api/v1/festivals/1", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("Expected status 401, got %d", w.Code)
+			}
+
+			if challenge := w.Header().Get(HeaderWWWAuthenticate); challenge != tt.challenge {
+				t.Errorf("Expected %s %q, got %q", HeaderWWWAuthenticate, tt.challenge, challenge)
+			}
+
+			if problem := decodeProblem(t, w); problem.Code != tt.code {
+				t.Errorf("Expected code %s, got %s", tt.code, problem.Code)
+			}
+
+			if seen != nil {
+				t.Error("Expected next handler not to be called")
+			}
+		})
+	}
+
+	t.Run("lets preflight requests through", func(t *testing.T) {
+		req := httptest.NewRequest("OPTIONS", "/api/v1/festivals/1", nil)
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+	})
+}
+
+func TestRequirePermission(t *testing.T) {
+	mockDB := &MockDatabase{
+		verifyTokenFunc: func(token string) (*User, error) {
+			return &User{ID: "user-123", Roles: []string{token}}, nil
+		},
+		getFestivalFunc: func(id int64) (*Festival, error) {
+			return &Festival{ID: id, Name: "Test Festival"}, nil
+		},
+		getFestivalOwnersFunc: func(festivalID int64) ([]string, error) {
+			return []string{"user-123"}, nil
+		},
+	}
+	router := newTestRouter(mockDB)
+
+	tests := []struct {
+		role      string
+		method    string
+		path      string
+		forbidden bool
+	}{
+		{RoleViewer, "POST", "/api/v1/festivals", true},
+		{RoleViewer, "POST", "/api/v1/breweries", true},
+		{RoleContributor, "POST", "/api/v1/breweries", false},
+		{RoleContributor, "PATCH", "/api/v1/festivals/1", true},
+		{RoleContributor, "PUT", "/api/v1/festivals/1/breweries", true},
+		{RoleOrganizer, "PATCH", "/api/v1/festivals/1", false},
+		{RoleOrganizer, "DELETE", "/api/v1/festivals/1/breweries/2", false},
+		{RoleOrganizer, "DELETE", "/api/v1/festivals/1", true},
+		{RoleOrganizer, "DELETE", "/api/v1/breweries/1", true},
+		{RoleAdmin, "DELETE", "/api/v1/festivals/1", false},
+		{RoleOrganizer, "POST", "/api/festivals/create", false},
+		{RoleContributor, "POST", "/api/festivals/create", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role+" "+tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{}`))
+			req.Header.Set("Authorization", "Bearer "+tt.role)
+			w := httptest.NewRecorder()
+
+			router(w, req)
+
+			if !tt.forbidden {
+				if w.Code == http.StatusForbidden || w.Code == http.StatusUnauthorized {
+					t.Errorf("Expected %s to be allowed, got %d: %s", tt.role, w.Code, w.Body.String())
+				}
+				return
+			}
+
+			if w.Code != http.StatusForbidden {
+				t.Fatalf("Expected status 403, got %d", w.Code)
+			}
+
+			if problem := decodeProblem(t, w); problem.Code != ErrorCodeForbidden {
+				t.Errorf("Expected code %s, got %s", ErrorCodeForbidden, problem.Code)
+			}
+		})
+	}
+
+	t.Run("sends CORS headers with auth errors", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer " + RoleViewer} {
+			req := httptest.NewRequest("DELETE", "/api/v1/festivals/1", nil)
+			req.Header.Set("Origin", "http://localhost:5173")
+			if authorization != "" {
+				req.Header.Set("Authorization", authorization)
+			}
+			w := httptest.NewRecorder()
+
+			router(w, req)
+
+			if w.Header().Get(HeaderCORSOrigin) == "" {
+				t.Errorf("Expected CORS headers on status %d", w.Code)
+			}
+		}
+	})
+}
+
+func TestRequireFestivalOwner(t *testing.T) {
+	mockDB := &MockDatabase{
+		verifyTokenFunc: func(token string) (*User, error) {
+			id, role, _ := strings.Cut(token, ":")
+			return &User{ID: id, Roles: []string{role}}, nil
+		},
+		getFestivalFunc: func(id int64) (*Festival, error) {
+			return &Festival{ID: id, Name: "Test Festival"}, nil
+		},
+		getFestivalOwnersFunc: func(festivalID int64) ([]string, error) {
+			if festivalID == 999 {
+				return nil, ErrNotFound
+			}
+			return []string{"owner-1", "owner-2"}, nil
+		},
+	}
+	router := newTestRouter(mockDB)
+
+	tests := []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		status int
+		code   string
+	}{
+		{"owner updates", "owner-1:organizer", "PATCH", "/api/v1/festivals/1", http.StatusOK, ""},
+		{"co-owner changes the lineup", "owner-2:organizer", "DELETE", "/api/v1/festivals/1/breweries/2", http.StatusNoContent, ""},
+		{"owner lists owners", "owner-1:viewer", "GET", "/api/v1/festivals/1/owners", http.StatusOK, ""},
+		{"other organizer updates", "other:organizer", "PUT", "/api/v1/festivals/1", http.StatusForbidden, ErrorCodeNotFestivalOwner},
+		{"other organizer changes the lineup", "other:organizer", "POST", "/api/v1/festivals/1/breweries", http.StatusForbidden, ErrorCodeNotFestivalOwner},
+		{"other user lists owners", "other:organizer", "GET", "/api/v1/festivals/1/owners", http.StatusForbidden, ErrorCodeNotFestivalOwner},
+		{"admin updates any festival", "other:admin", "PATCH", "/api/v1/festivals/1", http.StatusOK, ""},
+		{"unknown festival", "other:organizer", "PATCH", "/api/v1/festivals/999", http.StatusNotFound, ErrorCodeFestivalNotFound},
+		{"invalid festival ID", "other:organizer", "PATCH", "/api/v1/festivals/abc", http.StatusBadRequest, ErrorCodeValidation},
+		{"invalid festival ID for an admin", "other:admin", "DELETE", "/api/v1/festivals/0", http.StatusBadRequest, ErrorCodeValidation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(`{}`))
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			router(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+
+			if tt.code != "" {
+				if problem := decodeProblem(t, w); problem.Code != tt.code {
+					t.Errorf("Expected code %s, got %s", tt.code, problem.Code)
+				}
+			}
+		})
+	}
+
+	t.Run("rejects an invalid festival ID without calling the handler", func(t *testing.T) {
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t.Error("Expected the handler not to be called")
+		})
+		req := httptest.NewRequest("PATCH", "/api/v1/festivals/abc", nil)
+		req.SetPathValue("id", "abc")
+		req = req.WithContext(context.WithValue(req.Context(), userContextKey, &User{ID: "other", Roles: []string{RoleOrganizer}}))
+		w := httptest.NewRecorder()
+
+		requireFestivalOwner(mockDB)(next).ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400, got %d", w.Code)
+		}
+	})
+}
+
 func TestResponseWriter(t *testing.T) {
 	t.Run("captures status code", func(t *testing.T) {
 		w := httptest.NewRecorder()
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	OpenAPIVersion        = "3.0.3"
+	OpenAPITitle          = "Beer Festival API"
+	OpenAPISecurityBearer = "bearerAuth"
+	OpenAPISchemaPrefix   = "#/components/schemas/"
+)
+
+type openAPIOperationSpec struct {
+	method      string
+	path        string
+	summary     string
+	description string
+	auth        bool
+	permission  string
+	owned       bool
+	params      []OpenAPIParameter
+	body        any
+	status      int
+	response    any
+	contentType string
+	errors      []int
+}
+
+type openAPIRequestSchema struct {
+	name     string
+	value    any
+	omit     []string
+	required []string
+}
+
+var (
+	festivalInputSchema = openAPIRequestSchema{name: "FestivalInput", value: FestivalDB{},
+		omit: festivalServerFields, required: []string{"name", "start_date", "end_date"}}
+	festivalPatchSchema = openAPIRequestSchema{name: "FestivalPatch", value: FestivalDB{}, omit: festivalServerFields}
+	breweryInputSchema  = openAPIRequestSchema{name: "BreweryInput", value: BreweryDB{},
+		omit: breweryServerFields, required: []string{"name", "city"}}
+	breweryPatchSchema = openAPIRequestSchema{name: "BreweryPatch", value: BreweryDB{}, omit: breweryServerFields}
+
+	festivalServerFields = []string{"id", "created_by", "created_at", "updated_at"}
+	breweryServerFields  = []string{"id"}
+)
+
+var openAPIOperations = []openAPIOperationSpec{
+	{method: "GET", path: FestivalsPath, summary: "List festivals",
+		params: festivalListParams(), response: []Festival{}},
+	{method: "POST", path: FestivalsPath, summary: "Create a festival", permission: PermissionFestivalsWrite,
+		body: festivalInputSchema, status: http.StatusCreated, response: FestivalDB{}},
+	{method: "GET", path: NearbyFestivalsPath, summary: "List festivals near a point, sorted by distance",
+		params: append([]OpenAPIParameter{
+			openAPIQueryParam("lat", "number", true),
+			openAPIQueryParam("lon", "number", true),
+			openAPIQueryParam("radius_km", "number", false),
+		}, festivalListParams()...), response: []NearbyFestival{}},
+	{method: "GET", path: FestivalMapPath, summary: "List festivals inside a bounding box, clustered by zoom",
+		params: append([]OpenAPIParameter{
+			openAPIQueryParam("bbox", "string", true),
+			openAPIQueryParam("zoom", "integer", true),
+		}, festivalFilterParams()...), response: FestivalMap{}},
+	{method: "GET", path: FestivalsGeoJSONPath, summary: "Export located festivals as GeoJSON",
+		params: festivalListParams(), response: GeoJSONFeatureCollection{}, contentType: ContentTypeGeoJSON,
+		description: "X-Total-Count is the number of matching festivals, including unmapped ones that have no feature."},
+	{method: "GET", path: FestivalsICalPath, summary: "Export festivals as an iCalendar feed",
+		params: festivalListParams(), contentType: ContentTypeICal},
+	{method: "GET", path: FestivalPath, summary: "Get a festival",
+		params: []OpenAPIParameter{openAPIPathParam("id")}, response: Festival{}},
+	{method: "PUT", path: FestivalPath, summary: "Replace a festival", permission: PermissionFestivalsWrite, owned: true,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, body: festivalInputSchema, response: FestivalDB{}},
+	{method: "PATCH", path: FestivalPath, summary: "Update the fields sent of a festival", permission: PermissionFestivalsWrite, owned: true,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, body: festivalPatchSchema, response: FestivalDB{}},
+	{method: "DELETE", path: FestivalPath, summary: "Delete a festival and its lineup", permission: PermissionFestivalsDelete, owned: true,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, status: http.StatusNoContent},
+	{method: "GET", path: FestivalsBreweriesPath, summary: "List the breweries attending a festival",
+		params: []OpenAPIParameter{openAPIPathParam("id")}, response: []Brewery{}},
+	{method: "POST", path: FestivalsBreweriesPath, summary: "Add breweries to a festival lineup", permission: PermissionLineupsWrite, owned: true,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, body: LineupRequest{}, status: http.StatusCreated,
+		response: []Brewery{}, errors: []int{http.StatusConflict}},
+	{method: "PUT", path: FestivalsBreweriesPath, summary: "Replace a festival lineup", permission: PermissionLineupsWrite, owned: true,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, body: LineupRequest{}, response: []Brewery{}},
+	{method: "DELETE", path: FestivalBreweryPath, summary: "Remove a brewery from a festival lineup", permission: PermissionLineupsWrite, owned: true,
+		params: []OpenAPIParameter{openAPIPathParam("id"), openAPIPathParam("breweryId")}, status: http.StatusNoContent},
+	{method: "GET", path: FestivalOwnersPath, summary: "List the users who own a festival", owned: true,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, response: FestivalOwners{}},
+	{method: "POST", path: FestivalOwnersPath, summary: "Add co-owners to a festival", permission: PermissionOwnersWrite,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, body: OwnersRequest{}, status: http.StatusCreated,
+		response: FestivalOwners{}},
+	{method: "PUT", path: FestivalOwnersPath, summary: "Transfer a festival to a new set of owners", permission: PermissionOwnersWrite,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, body: OwnersRequest{}, response: FestivalOwners{}},
+	{method: "GET", path: MyFestivalsPath, summary: "List the festivals owned by the current user", auth: true,
+		response: []Festival{}},
+	{method: "GET", path: BreweriesPath, summary: "List breweries",
+		params: append([]OpenAPIParameter{openAPIQueryParam("city", "string", false)},
+			append(openAPISortParams(brewerySortColumns), openAPIPaginationParams()...)...),
+		response: []Brewery{}},
+	{method: "POST", path: BreweriesPath, summary: "Create a brewery", permission: PermissionBreweriesWrite,
+		body: breweryInputSchema, status: http.StatusCreated, response: BreweryDB{}},
+	{method: "GET", path: BreweryPath, summary: "Get a brewery with its upcoming and past festivals",
+		params: []OpenAPIParameter{openAPIPathParam("id")}, response: BreweryDetail{}},
+	{method: "PUT", path: BreweryPath, summary: "Replace a brewery", permission: PermissionBreweriesWrite,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, body: breweryInputSchema, response: BreweryDB{}},
+	{method: "PATCH", path: BreweryPath, summary: "Update the fields sent of a brewery", permission: PermissionBreweriesWrite,
+		params: []OpenAPIParameter{openAPIPathParam("id")}, body: breweryPatchSchema, response: BreweryDB{}},
+	{method: "DELETE", path: BreweryPath, summary: "Delete a brewery", permission: PermissionBreweriesDelete,
+		params: []OpenAPIParameter{openAPIPathParam("id"), openAPIQueryParam("cascade", "boolean", false)},
+		status: http.StatusNoContent, errors: []int{http.StatusConflict}},
+	{method: "GET", path: FeedPath, summary: "Atom or RSS feed of new or upcoming festivals",
+		params: []OpenAPIParameter{openAPIFeedParam()}, contentType: ContentTypeAtom},
+	{method: "GET", path: SearchPath, summary: "Search festivals and breweries",
+		params: append([]OpenAPIParameter{
+			openAPIQueryParam("q", "string", true),
+			openAPIEnumParam("type", []string{SearchTypeFestival, SearchTypeBrewery}),
+		}, openAPIPaginationParams()...), response: []SearchHit{}},
+	{method: "GET", path: SuggestPath, summary: "Suggest cities, regions and breweries by prefix",
+		params: []OpenAPIParameter{
+			openAPIQueryParam("q", "string", false),
+			openAPIEnumParam("category", suggestCategories),
+			openAPIQueryParam("limit", "integer", false),
+		}, response: map[string][]Suggestion{}},
+	{method: "POST", path: LoginPath, summary: "Log in with email and password",
+		body: LoginRequest{}, response: LoginResponse{}, errors: []int{http.StatusUnauthorized}},
+	{method: "POST", path: RefreshPath, summary: "Exchange a refresh token for a new token pair",
+		body: RefreshRequest{}, response: LoginResponse{}, errors: []int{http.StatusUnauthorized}},
+	{method: "POST", path: LogoutPath, summary: "Revoke the session of the bearer token", auth: true,
+		status: http.StatusNoContent},
+	{method: "GET", path: VerifyPath, summary: "Verify a bearer token", auth: true,
+		response: VerifyResponse{}},
+}
+
+func festivalFilterParams() []OpenAPIParameter {
+	return []OpenAPIParameter{
+		openAPIQueryParam("region", "string", false),
+		openAPIQueryParam("city", "string", false),
+		{Name: "from", In: "query", Schema: &OpenAPISchema{Type: "string", Format: "date"}},
+		{Name: "to", In: "query", Schema: &OpenAPISchema{Type: "string", Format: "date"}},
+		openAPIQueryParam("upcoming", "boolean", false),
+		openAPIQueryParam("past", "boolean", false),
+	}
+}
+
+func festivalListParams() []OpenAPIParameter {
+	params := append(festivalFilterParams(), openAPISortParams(festivalSortColumns)...)
+	return append(params, openAPIPaginationParams()...)
+}
+
+func openAPIQueryParam(name, kind string, required bool) OpenAPIParameter {
+	return OpenAPIParameter{Name: name, In: "query", Required: required, Schema: &OpenAPISchema{Type: kind}}
+}
+
+func openAPIEnumParam(name string, values []string) OpenAPIParameter {
+	return OpenAPIParameter{Name: name, In: "query", Schema: &OpenAPISchema{Type: "string", Enum: values}}
+}
+
+func openAPIPathParam(name string) OpenAPIParameter {
+	return OpenAPIParameter{Name: name, In: "path", Required: true, Schema: &OpenAPISchema{Type: "integer", Format: "int64"}}
+}
+
+func openAPISortParams(columns map[string]string) []OpenAPIParameter {
+	names := make([]string, 0, len(columns))
+	for name := range columns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return []OpenAPIParameter{openAPIEnumParam("sort", names), openAPIEnumParam("order", []string{"asc", "desc"})}
+}
+
+func openAPIPaginationParams() []OpenAPIParameter {
+	return []OpenAPIParameter{openAPIQueryParam("limit", "integer", false), openAPIQueryParam("offset", "integer", false)}
+}
+
+func openAPIFeedParam() OpenAPIParameter {
+	var feeds []string
+	for name := range festivalFeeds {
+		feeds = append(feeds, name+".atom", name+".rss")
+	}
+	sort.Strings(feeds)
+
+	return OpenAPIParameter{Name: "feed", In: "path", Required: true, Schema: &OpenAPISchema{Type: "string", Enum: feeds}}
+}
+
+func newOpenAPIDocument() OpenAPIDocument {
+	document := OpenAPIDocument{
+		OpenAPI: OpenAPIVersion,
+		Info:    OpenAPIInfo{Title: OpenAPITitle, Version: AppVersion},
+		Servers: []OpenAPIServer{{URL: APIBasePath}},
+		Paths:   map[string]map[string]*OpenAPIOperation{},
+		Components: OpenAPIComponents{
+			Schemas: map[string]*OpenAPISchema{},
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				OpenAPISecurityBearer: {Type: "http", Scheme: "bearer"},
+			},
+		},
+	}
+
+	for _, spec := range openAPIOperations {
+		if document.Paths[spec.path] == nil {
+			document.Paths[spec.path] = map[string]*OpenAPIOperation{}
+		}
+		document.Paths[spec.path][strings.ToLower(spec.method)] = spec.operation(document.Components.Schemas)
+	}
+
+	return document
+}
+
+func (spec openAPIOperationSpec) operation(schemas map[string]*OpenAPISchema) *OpenAPIOperation {
+	operation := &OpenAPIOperation{
+		Summary:    spec.summary,
+		Parameters: spec.params,
+		Responses:  map[string]OpenAPIResponse{},
+	}
+
+	if spec.body != nil {
+		var schema *OpenAPISchema
+		if input, ok := spec.body.(openAPIRequestSchema); ok {
+			schema = input.schema(schemas)
+		} else {
+			schema = openAPISchema(reflect.TypeOf(spec.body), schemas)
+		}
+		operation.RequestBody = &OpenAPIRequestBody{
+			Required: true,
+			Content:  map[string]OpenAPIMediaType{ContentTypeJSON: {Schema: schema}},
+		}
+	}
+
+	status := spec.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := OpenAPIResponse{Description: http.StatusText(status)}
+	if status != http.StatusNoContent {
+		contentType, _, _ := strings.Cut(spec.contentType, ";")
+		if contentType == "" {
+			contentType = ContentTypeJSON
+		}
+		schema := &OpenAPISchema{Type: "string"}
+		if spec.response != nil {
+			schema = openAPISchema(reflect.TypeOf(spec.response), schemas)
+		}
+		response.Content = map[string]OpenAPIMediaType{contentType: {Schema: schema}}
+		if spec.path == FeedPath {
+			rss, _, _ := strings.Cut(ContentTypeRSS, ";")
+			response.Content[rss] = OpenAPIMediaType{Schema: schema}
+		}
+	}
+	operation.Responses[strconv.Itoa(status)] = response
+
+	errors := spec.errors
+	if len(spec.params) > 0 || spec.body != nil {
+		errors = append(errors, http.StatusBadRequest)
+	}
+	if spec.auth || spec.permission != "" || spec.owned {
+		operation.Security = []map[string][]string{{OpenAPISecurityBearer: {}}}
+		errors = append(errors, http.StatusUnauthorized)
+	}
+	var requirements []string
+	if spec.permission != "" {
+		requirements = append(requirements, "Requires the "+spec.permission+" permission, granted to the "+
+			strings.Join(rolesWithPermission(spec.permission), ", ")+" roles.")
+	}
+	if spec.owned {
+		requirements = append(requirements, "Restricted to the festival's owners unless the caller has the "+
+			PermissionFestivalsManageAny+" permission, granted to the "+
+			strings.Join(rolesWithPermission(PermissionFestivalsManageAny), ", ")+" roles.")
+	}
+	if len(requirements) > 0 {
+		errors = append(errors, http.StatusForbidden)
+	}
+	operation.Description = strings.TrimSpace(spec.description + " " + strings.Join(requirements, " "))
+	if strings.Contains(spec.path, "{") {
+		errors = append(errors, http.StatusNotFound)
+	}
+	problem := map[string]OpenAPIMediaType{ContentTypeProblem: {Schema: openAPISchema(reflect.TypeOf(Problem{}), schemas)}}
+	for _, status := range errors {
+		operation.Responses[strconv.Itoa(status)] = OpenAPIResponse{Description: http.StatusText(status), Content: problem}
+	}
+
+	return operation
+}
+
+func (input openAPIRequestSchema) schema(schemas map[string]*OpenAPISchema) *OpenAPISchema {
+	if _, ok := schemas[input.name]; !ok {
+		schema := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+		schemas[input.name] = schema
+		addOpenAPIProperties(schema, reflect.TypeOf(input.value), schemas)
+		for _, name := range input.omit {
+			delete(schema.Properties, name)
+		}
+		schema.Required = input.required
+	}
+	return &OpenAPISchema{Ref: OpenAPISchemaPrefix + input.name}
+}
+
+func openAPISchema(t reflect.Type, schemas map[string]*OpenAPISchema) *OpenAPISchema {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return &OpenAPISchema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &OpenAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &OpenAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int32:
+		return &OpenAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &OpenAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &OpenAPISchema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &OpenAPISchema{Type: "array", Items: openAPISchema(t.Elem(), schemas)}
+	case reflect.Map:
+		return &OpenAPISchema{Type: "object", AdditionalProperties: openAPISchema(t.Elem(), schemas)}
+	case reflect.Struct:
+		if _, ok := schemas[t.Name()]; !ok {
+			schema := &OpenAPISchema{Type: "object", Properties: map[string]*OpenAPISchema{}}
+			schemas[t.Name()] = schema
+			addOpenAPIProperties(schema, t, schemas)
+		}
+		return &OpenAPISchema{Ref: OpenAPISchemaPrefix + t.Name()}
+	}
+
+	return &OpenAPISchema{}
+}
+
+func addOpenAPIProperties(schema *OpenAPISchema, t reflect.Type, schemas map[string]*OpenAPISchema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Anonymous && name == "" {
+			addOpenAPIProperties(schema, field.Type, schemas)
+			continue
+		}
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema.Properties[name] = openAPISchema(field.Type, schemas)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
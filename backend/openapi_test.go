@@ -0,0 +1,354 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOpenAPIDocumentCoversRoutes(t *testing.T) {
+	document := newOpenAPIDocument()
+
+	registered := 0
+	for _, route := range apiRoutes(&MockDatabase{}, nil, Config{}) {
+		for _, method := range route.methods {
+			registered++
+			operation := document.Paths[route.path][strings.ToLower(method)]
+			if operation == nil {
+				t.Errorf("Route %s %s is missing from the OpenAPI document", method, route.path)
+				continue
+			}
+			if secured := len(operation.Security) > 0; secured != route.auth {
+				t.Errorf("Route %s %s requires auth %v but the OpenAPI document says %v", method, route.path, route.auth, secured)
+			}
+			if _, forbidden := operation.Responses["403"]; forbidden != (route.permission != "" || route.owned) ||
+				!strings.Contains(operation.Description, route.permission) {
+				t.Errorf("Route %s %s requires permission %q but the OpenAPI document says %q", method, route.path, route.permission, operation.Description)
+			}
+			if owned := strings.Contains(operation.Description, "festival's owners"); owned != route.owned {
+				t.Errorf("Route %s %s requires ownership %v but the OpenAPI document says %q", method, route.path, route.owned, operation.Description)
+			}
+		}
+	}
+
+	documented := 0
+	for _, operations := range document.Paths {
+		documented += len(operations)
+	}
+	if documented != registered {
+		t.Errorf("Expected %d documented operations, got %d", registered, documented)
+	}
+}
+
+func TestOpenAPIDocumentSchemas(t *testing.T) {
+	document := newOpenAPIDocument()
+	schemas := document.Components.Schemas
+
+	for _, name := range []string{"Festival", "Brewery", "LoginRequest", "LoginResponse", "VerifyResponse"} {
+		if schemas[name] == nil {
+			t.Errorf("Expected schema %s", name)
+		}
+	}
+
+	festival := schemas["Festival"]
+	for _, name := range []string{"image", "website", "startDate"} {
+		if !slices.Contains(festival.Required, name) {
+			t.Errorf("Expected Festival.%s to be required, got %v", name, festival.Required)
+		}
+	}
+	if festival.Properties["startDate"].Format != "date-time" {
+		t.Errorf("Expected startDate to be a date-time, got %+v", festival.Properties["startDate"])
+	}
+	if festival.Properties["location"].Ref != OpenAPISchemaPrefix+"Location" {
+		t.Errorf("Expected location to reference Location, got %+v", festival.Properties["location"])
+	}
+
+	if slices.Contains(schemas["Problem"].Required, "errors") || !slices.Contains(schemas["Problem"].Required, "code") {
+		t.Errorf("Expected Problem.code to be required and errors optional, got %v", schemas["Problem"].Required)
+	}
+
+	if _, ok := schemas["FestivalInput"].Properties["id"]; ok || !slices.Equal(schemas["FestivalInput"].Required, []string{"name", "start_date", "end_date"}) {
+		t.Errorf("Expected FestivalInput without id requiring name and dates, got %+v", schemas["FestivalInput"])
+	}
+	if len(schemas["FestivalPatch"].Required) != 0 || len(schemas["BreweryPatch"].Required) != 0 {
+		t.Errorf("Expected patch schemas to require nothing, got %v and %v", schemas["FestivalPatch"].Required, schemas["BreweryPatch"].Required)
+	}
+	if body := document.Paths[FestivalPath]["patch"].RequestBody.Content[ContentTypeJSON].Schema; body.Ref != OpenAPISchemaPrefix+"FestivalPatch" {
+		t.Errorf("Expected PATCH %s to take a FestivalPatch, got %+v", FestivalPath, body)
+	}
+
+	if schemas["NearbyFestival"].Properties["name"] == nil || schemas["NearbyFestival"].Properties["distanceKm"] == nil {
+		t.Errorf("Expected NearbyFestival to inline Festival fields, got %+v", schemas["NearbyFestival"].Properties)
+	}
+}
+
+func TestOpenAPIDocumentSecurity(t *testing.T) {
+	document := newOpenAPIDocument()
+
+	tests := []struct {
+		path   string
+		method string
+		auth   bool
+	}{
+		{FestivalsPath, "get", false},
+		{FestivalsPath, "post", true},
+		{FestivalPath, "patch", true},
+		{FestivalBreweryPath, "delete", true},
+		{BreweryPath, "get", false},
+		{LoginPath, "post", false},
+		{VerifyPath, "get", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			operation := document.Paths[tt.path][tt.method]
+			secured := len(operation.Security) > 0
+			if secured != tt.auth {
+				t.Errorf("Expected auth %v, got security %v", tt.auth, operation.Security)
+			}
+
+			unauthorized, ok := operation.Responses["401"]
+			if tt.auth && !ok {
+				t.Errorf("Expected a 401 response, got %v", operation.Responses)
+			}
+			if ok && unauthorized.Content[ContentTypeProblem].Schema.Ref != OpenAPISchemaPrefix+"Problem" {
+				t.Errorf("Expected 401 to return a Problem, got %+v", unauthorized.Content)
+			}
+		})
+	}
+}
+
+func TestOpenAPIHandler(t *testing.T) {
+	router := newRouter(&MockDatabase{}, Config{AllowedOrigins: "*"})
+
+	tests := []struct {
+		path       string
+		deprecated string
+	}{
+		{"/api/v1/openapi.json", ""},
+		{"/api/openapi.json", "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d", w.Code)
+			}
+
+			if contentType := w.Header().Get(HeaderContentType); contentType != ContentTypeJSON {
+				t.Errorf("Expected content type %s, got %s", ContentTypeJSON, contentType)
+			}
+
+			if w.Header().Get(HeaderDeprecation) != tt.deprecated {
+				t.Errorf("Expected %s %q, got %q", HeaderDeprecation, tt.deprecated, w.Header().Get(HeaderDeprecation))
+			}
+
+			var document OpenAPIDocument
+			if err := json.NewDecoder(w.Body).Decode(&document); err != nil {
+				t.Fatalf("Failed to decode OpenAPI document: %v", err)
+			}
+
+			if document.OpenAPI != OpenAPIVersion || document.Servers[0].URL != APIBasePath {
+				t.Errorf("Expected OpenAPI %s served from %s, got %s from %+v", OpenAPIVersion, APIBasePath, document.OpenAPI, document.Servers)
+			}
+
+			if document.Paths[FestivalPath]["get"] == nil {
+				t.Errorf("Expected %s to be documented", FestivalPath)
+			}
+		})
+	}
+}
+
+func TestOpenAPIResponsesMatchHandlers(t *testing.T) {
+	festival := Festival{
+		ID:        1,
+		Name:      "Test Festival",
+		StartDate: time.Now().AddDate(0, 1, 0),
+		EndDate:   time.Now().AddDate(0, 1, 2),
+		City:      "Paris",
+		Region:    "Île-de-France",
+		Location:  Location{Latitude: 48.8566, Longitude: 2.3522},
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	brewery := Brewery{ID: 1, Name: "Test Brewery", City: "Paris", FestivalCount: 1}
+	session := &LoginResponse{AccessToken: "access", RefreshToken: "refresh",
+		User: User{ID: "user-123", Email: "test@example.com", Roles: []string{RoleAdmin}}}
+
+	db := authedMockDB(&MockDatabase{
+		loginFunc:                  func(email, password string) (*LoginResponse, error) { return session, nil },
+		refreshSessionFunc:         func(refreshToken string) (*LoginResponse, error) { return session, nil },
+		getFestivalsFunc:           func() ([]Festival, error) { return []Festival{festival}, nil },
+		listFestivalsFunc:          func(query FestivalQuery) ([]Festival, int, error) { return []Festival{festival}, 1, nil },
+		getFestivalFunc:            func(id int64) (*Festival, error) { return &festival, nil },
+		getFestivalsByOwnerFunc:    func(userID string) ([]Festival, error) { return []Festival{festival}, nil },
+		getFestivalsByBreweryFunc:  func(breweryID int64) ([]Festival, error) { return []Festival{festival}, nil },
+		getBreweriesFunc:           func() ([]Brewery, error) { return []Brewery{brewery}, nil },
+		listBreweriesFunc:          func(query BreweryQuery) ([]Brewery, int, error) { return []Brewery{brewery}, 1, nil },
+		getBreweryFunc:             func(id int64) (*Brewery, error) { return &brewery, nil },
+		getBreweriesByFestivalFunc: func(festivalID int64) ([]Brewery, error) { return []Brewery{brewery}, nil },
+		getFestivalOwnersFunc:      func(festivalID int64) ([]string, error) { return []string{"user-123"}, nil },
+		createFestivalFunc: func(festival *FestivalDB) (*FestivalDB, error) {
+			created := *festival
+			created.ID = 1
+			created.CreatedAt = time.Now().Format(time.RFC3339)
+			created.UpdatedAt = created.CreatedAt
+			return &created, nil
+		},
+		updateFestivalFunc: func(id int64, festival *FestivalDB) (*FestivalDB, error) {
+			updated := *festival
+			updated.ID = id
+			return &updated, nil
+		},
+		createBreweryFunc: func(brewery *BreweryDB) (*BreweryDB, error) {
+			created := *brewery
+			created.ID = 1
+			return &created, nil
+		},
+		updateBreweryFunc: func(id int64, brewery *BreweryDB) (*BreweryDB, error) {
+			updated := *brewery
+			updated.ID = id
+			return &updated, nil
+		},
+	})
+	router := newTestRouter(db)
+	document := newOpenAPIDocument()
+
+	festivalBody := `{"name":"Test Festival","start_date":"2030-10-01","end_date":"2030-10-03","city":"Paris"}`
+	breweryBody := `{"name":"Test Brewery","city":"Paris"}`
+	tests := []struct {
+		method string
+		path   string
+		target string
+		body   string
+	}{
+		{"GET", FestivalsPath, "/festivals", ""},
+		{"POST", FestivalsPath, "/festivals", festivalBody},
+		{"GET", NearbyFestivalsPath, "/festivals/nearby?lat=48.85&lon=2.35", ""},
+		{"GET", FestivalMapPath, "/festivals/map?bbox=-180,-90,180,90&zoom=18", ""},
+		{"GET", FestivalsGeoJSONPath, "/festivals.geojson", ""},
+		{"GET", FestivalPath, "/festivals/1", ""},
+		{"PUT", FestivalPath, "/festivals/1", festivalBody},
+		{"PATCH", FestivalPath, "/festivals/1", `{"name":"Renamed"}`},
+		{"GET", FestivalsBreweriesPath, "/festivals/1/breweries", ""},
+		{"POST", FestivalsBreweriesPath, "/festivals/1/breweries", `{"brewery_ids":[1]}`},
+		{"PUT", FestivalsBreweriesPath, "/festivals/1/breweries", `{"brewery_ids":[1]}`},
+		{"GET", FestivalOwnersPath, "/festivals/1/owners", ""},
+		{"POST", FestivalOwnersPath, "/festivals/1/owners", `{"user_ids":["user-456"]}`},
+		{"PUT", FestivalOwnersPath, "/festivals/1/owners", `{"user_ids":["user-456"]}`},
+		{"GET", MyFestivalsPath, "/me/festivals", ""},
+		{"GET", BreweriesPath, "/breweries", ""},
+		{"POST", BreweriesPath, "/breweries", breweryBody},
+		{"GET", BreweryPath, "/breweries/1", ""},
+		{"PUT", BreweryPath, "/breweries/1", breweryBody},
+		{"PATCH", BreweryPath, "/breweries/1", `{"city":"Lyon"}`},
+		{"GET", SearchPath, "/search?q=test", ""},
+		{"GET", SuggestPath, "/suggest?q=pa", ""},
+		{"POST", LoginPath, "/auth/login", `{"email":"test@example.com","password":"secret"}`},
+		{"POST", RefreshPath, "/auth/refresh", `{"refreshToken":"refresh"}`},
+		{"GET", VerifyPath, "/auth/verify", ""},
+	}
+
+	covered := map[string]bool{}
+	for _, tt := range tests {
+		covered[tt.method+" "+tt.path] = true
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, APIBasePath+tt.target, strings.NewReader(tt.body))
+			req.Header.Set("Authorization", "Bearer valid-token")
+			w := httptest.NewRecorder()
+
+			router(w, req)
+
+			response, ok := document.Paths[tt.path][strings.ToLower(tt.method)].Responses[strconv.Itoa(w.Code)]
+			if !ok {
+				t.Fatalf("Status %d is not documented: %s", w.Code, w.Body.String())
+			}
+			contentType, _, _ := strings.Cut(w.Header().Get(HeaderContentType), ";")
+			media, ok := response.Content[contentType]
+			if !ok {
+				t.Fatalf("Content type %s is not documented for status %d", contentType, w.Code)
+			}
+
+			var body any
+			if err := json.NewDecoder(w.Body).Decode(&body); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			checkOpenAPISchema(t, document, media.Schema, body, "response")
+		})
+	}
+
+	for _, spec := range openAPIOperations {
+		if spec.status == http.StatusNoContent || spec.response == nil {
+			continue
+		}
+		if !covered[spec.method+" "+spec.path] {
+			t.Errorf("Operation %s %s is not checked against its handler", spec.method, spec.path)
+		}
+	}
+}
+
+func checkOpenAPISchema(t *testing.T, document OpenAPIDocument, schema *OpenAPISchema, value any, at string) {
+	t.Helper()
+
+	if schema.Ref != "" {
+		schema = document.Components.Schemas[strings.TrimPrefix(schema.Ref, OpenAPISchemaPrefix)]
+	}
+
+	switch schema.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			t.Errorf("Expected %s to be an object, got %v", at, value)
+			return
+		}
+		for _, name := range schema.Required {
+			if _, ok := object[name]; !ok {
+				t.Errorf("Expected %s to have required property %s", at, name)
+			}
+		}
+		for name, property := range object {
+			if schema.AdditionalProperties != nil {
+				checkOpenAPISchema(t, document, schema.AdditionalProperties, property, at+"."+name)
+				continue
+			}
+			propertySchema, ok := schema.Properties[name]
+			if !ok {
+				t.Errorf("Property %s.%s is not documented", at, name)
+				continue
+			}
+			checkOpenAPISchema(t, document, propertySchema, property, at+"."+name)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			t.Errorf("Expected %s to be an array, got %v", at, value)
+			return
+		}
+		for i, item := range items {
+			checkOpenAPISchema(t, document, schema.Items, item, fmt.Sprintf("%s[%d]", at, i))
+		}
+	case "string":
+		if _, ok := value.(string); !ok {
+			t.Errorf("Expected %s to be a string, got %v", at, value)
+		}
+	case "integer", "number":
+		if _, ok := value.(float64); !ok {
+			t.Errorf("Expected %s to be a number, got %v", at, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			t.Errorf("Expected %s to be a boolean, got %v", at, value)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+)
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		messages[i] = field.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+func (e *ValidationError) Add(field, code, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+func (e *ValidationError) Err() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+func fieldError(field, code, message string) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Code: code, Message: message}}}
+}
+
+func requestID(r *http.Request) string {
+	id, _ := r.Context().Value("requestID").(string)
+	return id
+}
+
+func newProblem(r *http.Request, status int, code, detail string) Problem {
+	return Problem{
+		Type:      ProblemTypeDefault,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: requestID(r),
+	}
+}
+
+func sendProblem(w http.ResponseWriter, problem Problem) {
+	w.Header().Del("Content-Length")
+	w.Header().Set(HeaderContentType, ContentTypeProblem)
+	w.WriteHeader(problem.Status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		log.Printf("Error encoding problem: %v", err)
+	}
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	sendProblem(w, newProblem(r, status, code, detail))
+}
+
+func writeInvalidRequest(w http.ResponseWriter, r *http.Request, err error) {
+	var validation *ValidationError
+	if !errors.As(err, &validation) {
+		writeProblem(w, r, http.StatusBadRequest, ErrorCodeInvalidRequest, err.Error())
+		return
+	}
+
+	problem := newProblem(r, http.StatusBadRequest, ErrorCodeValidation, validation.Error())
+	problem.Errors = validation.Fields
+	sendProblem(w, problem)
+}
+
+func writeUnauthorized(w http.ResponseWriter, r *http.Request, code, detail, challenge string) {
+	w.Header().Set(HeaderWWWAuthenticate, challenge)
+	writeProblem(w, r, http.StatusUnauthorized, code, detail)
+}
+
+func writeInternalError(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusInternalServerError, ErrorCodeInternal, DefaultErrorMessage)
+}
+
+func writeDatabaseError(w http.ResponseWriter, r *http.Request, err error) {
+	if isTimeout(err) {
+		writeProblem(w, r, http.StatusGatewayTimeout, ErrorCodeTimeout, TimeoutErrorMessage)
+		return
+	}
+	writeInternalError(w, r)
+}
+
+type problemResponseWriter struct {
+	http.ResponseWriter
+	request *http.Request
+	written bool
+}
+
+func (pw *problemResponseWriter) WriteHeader(status int) {
+	if status < http.StatusBadRequest {
+		pw.ResponseWriter.WriteHeader(status)
+		return
+	}
+
+	code := ErrorCodeNotFound
+	if status == http.StatusMethodNotAllowed {
+		code = ErrorCodeMethodNotAllowed
+	}
+	writeProblem(pw.ResponseWriter, pw.request, status, code, "")
+	pw.written = true
+}
+
+func (pw *problemResponseWriter) Write(b []byte) (int, error) {
+	if pw.written {
+		return len(b), nil
+	}
+	return pw.ResponseWriter.Write(b)
+}
+
+func problemFallback(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := mux.Handler(r); pattern == "" {
+			mux.ServeHTTP(&problemResponseWriter{ResponseWriter: w, request: r}, r)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func decodeProblem(t *testing.T, w *httptest.ResponseRecorder) Problem {
+	t.Helper()
+
+	if contentType := w.Header().Get(HeaderContentType); contentType != ContentTypeProblem {
+		t.Errorf("Expected content type %s, got %s", ContentTypeProblem, contentType)
+	}
+
+	var problem Problem
+	if err := json.NewDecoder(w.Body).Decode(&problem); err != nil {
+		t.Fatalf("Failed to decode problem: %v", err)
+	}
+
+	if problem.Status != w.Code {
+		t.Errorf("Expected problem status %d, got %d", w.Code, problem.Status)
+	}
+	return problem
+}
+
+func TestWriteInvalidRequest(t *testing.T) {
+	t.Run("lists validation errors per field", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v1/festivals", nil)
+		req = req.WithContext(context.WithValue(req.Context(), "requestID", "req-42"))
+		w := httptest.NewRecorder()
+
+		writeInvalidRequest(w, req, validateFestival(&FestivalDB{StartDate: "2025-10-03", EndDate: "2025-10-01", Latitude: 91}))
+
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("Expected status 400, got %d", w.Code)
+		}
+
+		problem := decodeProblem(t, w)
+		if problem.Code != ErrorCodeValidation || problem.RequestID != "req-42" || problem.Instance != "/api/v1/festivals" {
+			t.Errorf("Expected validation problem for req-42 on /api/v1/festivals, got %+v", problem)
+		}
+
+		expected := []FieldError{
+			{Field: "name", Code: FieldCodeRequired},
+			{Field: "end_date", Code: FieldCodeOutOfRange},
+			{Field: "latitude", Code: FieldCodeOutOfRange},
+		}
+		if len(problem.Errors) != len(expected) {
+			t.Fatalf("Expected %d field errors, got %+v", len(expected), problem.Errors)
+		}
+		for i, fieldError := range problem.Errors {
+			if fieldError.Field != expected[i].Field || fieldError.Code != expected[i].Code || fieldError.Message == "" {
+				t.Errorf("Expected %s %s, got %+v", expected[i].Field, expected[i].Code, fieldError)
+			}
+		}
+	})
+
+	t.Run("reports other errors as invalid requests", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/festivals", nil)
+		w := httptest.NewRecorder()
+
+		writeInvalidRequest(w, req, errors.New("bad input"))
+
+		problem := decodeProblem(t, w)
+		if problem.Code != ErrorCodeInvalidRequest || problem.Detail != "bad input" || len(problem.Errors) != 0 {
+			t.Errorf("Expected invalid request problem, got %+v", problem)
+		}
+	})
+}
+
+func TestWriteDatabaseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"timeout", context.DeadlineExceeded, http.StatusGatewayTimeout, ErrorCodeTimeout},
+		{"failure", errors.New("connection refused"), http.StatusInternalServerError, ErrorCodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/v1/festivals", nil)
+			w := httptest.NewRecorder()
+
+			writeDatabaseError(w, req, tt.err)
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d", tt.status, w.Code)
+			}
+
+			if problem := decodeProblem(t, w); problem.Code != tt.code {
+				t.Errorf("Expected code %s, got %s", tt.code, problem.Code)
+			}
+		})
+	}
+}
+
+func TestRouterProblems(t *testing.T) {
+	router := requestIDMiddleware(newRouter(&MockDatabase{}, Config{AllowedOrigins: "*"}))
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		status int
+		code   string
+	}{
+		{"unknown path", "GET", "/api/v1/unknown", http.StatusNotFound, ErrorCodeNotFound},
+		{"unsupported method", "POST", "/api/v1/festivals.ics", http.StatusMethodNotAllowed, ErrorCodeMethodNotAllowed},
+		{"invalid festival ID", "GET", "/api/v1/festivals/abc", http.StatusBadRequest, ErrorCodeValidation},
+		{"missing token", "DELETE", "/api/v1/festivals/1", http.StatusUnauthorized, ErrorCodeUnauthorized},
+		{"unknown feed", "GET", "/api/v1/feeds/old.atom", http.StatusNotFound, ErrorCodeFeedNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req.Header.Set("X-Request-ID", "req-7")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("Expected status %d, got %d: %s", tt.status, w.Code, w.Body.String())
+			}
+
+			problem := decodeProblem(t, w)
+			if problem.Code != tt.code || problem.RequestID != "req-7" || problem.Instance != tt.path {
+				t.Errorf("Expected %s problem for req-7 on %s, got %+v", tt.code, tt.path, problem)
+			}
+		})
+	}
+
+	t.Run("keeps the Allow header on 405", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v1/festivals.ics", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if allow := w.Header().Get("Allow"); allow == "" {
+			t.Error("Expected an Allow header")
+		}
+	})
+}
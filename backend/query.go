@@ -0,0 +1,196 @@
+package main
+
+import (
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var festivalSortColumns = map[string]string{
+	"id":        "id",
+	"name":      "name",
+	"startDate": "start_date",
+	"endDate":   "end_date",
+	"city":      "city",
+	"region":    "region",
+	"createdAt": "created_at",
+	"updatedAt": "updated_at",
+}
+
+var brewerySortColumns = map[string]string{
+	"id":   "id",
+	"name": "name",
+	"city": "city",
+}
+
+func parseFestivalQuery(values url.Values) (FestivalQuery, error) {
+	query := FestivalQuery{
+		Region: values.Get("region"),
+		City:   values.Get("city"),
+		From:   values.Get("from"),
+		To:     values.Get("to"),
+	}
+
+	if query.From != "" {
+		if _, err := ConvertTime(query.From); err != nil {
+			return query, fieldError("from", FieldCodeInvalid, "Invalid from date. Expected YYYY-MM-DD")
+		}
+	}
+
+	if query.To != "" {
+		if _, err := ConvertTime(query.To); err != nil {
+			return query, fieldError("to", FieldCodeInvalid, "Invalid to date. Expected YYYY-MM-DD")
+		}
+	}
+
+	if query.From != "" && query.To != "" && query.To < query.From {
+		return query, fieldError("to", FieldCodeOutOfRange, "to must not be before from")
+	}
+
+	var err error
+	if query.Upcoming, err = parseBoolParam(values, "upcoming"); err != nil {
+		return query, err
+	}
+	if query.Past, err = parseBoolParam(values, "past"); err != nil {
+		return query, err
+	}
+	if query.Upcoming && query.Past {
+		return query, fieldError("past", FieldCodeInvalid, "upcoming and past cannot be combined")
+	}
+
+	if query.Sort, query.Desc, err = parseSort(values, festivalSortColumns); err != nil {
+		return query, err
+	}
+
+	query.Limit, query.Offset, err = parsePagination(values)
+	return query, err
+}
+
+func parseBreweryQuery(values url.Values) (BreweryQuery, error) {
+	query := BreweryQuery{City: values.Get("city")}
+
+	var err error
+	if query.Sort, query.Desc, err = parseSort(values, brewerySortColumns); err != nil {
+		return query, err
+	}
+
+	query.Limit, query.Offset, err = parsePagination(values)
+	return query, err
+}
+
+func parseBoolParam(values url.Values, name string) (bool, error) {
+	value := values.Get(name)
+	if value == "" {
+		return false, nil
+	}
+
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fieldError(name, FieldCodeInvalid, "Invalid "+name+" value. Expected true or false")
+	}
+	return parsed, nil
+}
+
+func parseSort(values url.Values, columns map[string]string) (string, bool, error) {
+	sort := values.Get("sort")
+	if sort == "" {
+		sort = "id"
+	}
+
+	column, ok := columns[sort]
+	if !ok {
+		return "", false, fieldError("sort", FieldCodeInvalid, "Invalid sort field: "+sort)
+	}
+
+	switch values.Get("order") {
+	case "", "asc":
+		return column, false, nil
+	case "desc":
+		return column, true, nil
+	default:
+		return "", false, fieldError("order", FieldCodeInvalid, "Invalid order. Expected asc or desc")
+	}
+}
+
+func parsePagination(values url.Values) (int, int, error) {
+	limit, offset := 0, 0
+
+	if value := values.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > MaxPageSize {
+			return 0, 0, fieldError("limit", FieldCodeOutOfRange, "Invalid limit. Expected a number between 1 and "+strconv.Itoa(MaxPageSize))
+		}
+		limit = parsed
+	}
+
+	if value := values.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			return 0, 0, fieldError("offset", FieldCodeOutOfRange, "Invalid offset. Expected a non-negative number")
+		}
+		offset = parsed
+	}
+
+	return limit, offset, nil
+}
+
+func parseBoundingBox(value string) (BoundingBox, error) {
+	invalid := fieldError("bbox", FieldCodeInvalid, "Invalid bbox. Expected west,south,east,north in degrees")
+
+	parts := strings.Split(value, ",")
+	if len(parts) != 4 {
+		return BoundingBox{}, invalid
+	}
+
+	var coordinates [4]float64
+	for i, part := range parts {
+		parsed, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return BoundingBox{}, invalid
+		}
+		coordinates[i] = parsed
+	}
+
+	bounds := BoundingBox{West: coordinates[0], South: coordinates[1], East: coordinates[2], North: coordinates[3]}
+	if bounds.West < -180 || bounds.East > 180 || bounds.South < -90 || bounds.North > 90 ||
+		bounds.West > bounds.East || bounds.South > bounds.North {
+		return BoundingBox{}, invalid
+	}
+
+	return bounds, nil
+}
+
+func parseSearchQuery(values url.Values) (SearchQuery, error) {
+	query := SearchQuery{Text: strings.TrimSpace(values.Get("q")), Type: values.Get("type")}
+
+	if len([]rune(query.Text)) < SearchMinQueryLength {
+		return query, fieldError("q", FieldCodeInvalid, "q must be at least "+strconv.Itoa(SearchMinQueryLength)+" characters")
+	}
+
+	if query.Type != "" && query.Type != SearchTypeFestival && query.Type != SearchTypeBrewery {
+		return query, fieldError("type", FieldCodeInvalid, "Invalid type. Expected festival or brewery")
+	}
+
+	var err error
+	query.Limit, query.Offset, err = parsePagination(values)
+	if query.Limit == 0 {
+		query.Limit = SearchDefaultLimit
+	}
+	return query, err
+}
+
+func parseSuggestQuery(values url.Values) (SuggestQuery, error) {
+	query := SuggestQuery{Prefix: values.Get("q"), Category: values.Get("category")}
+
+	if query.Category != "" && query.Category != SuggestCategoryCity &&
+		query.Category != SuggestCategoryRegion && query.Category != SuggestCategoryBrewery {
+		return query, fieldError("category", FieldCodeInvalid, "Invalid category. Expected city, region or brewery")
+	}
+
+	var err error
+	query.Limit, _, err = parsePagination(values)
+	if query.Limit == 0 {
+		query.Limit = SuggestDefaultLimit
+	}
+	return query, err
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseFestivalQuery(t *testing.T) {
+	t.Run("defaults to id order without pagination", func(t *testing.T) {
+		query, err := parseFestivalQuery(url.Values{})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if query != (FestivalQuery{Sort: "id"}) {
+			t.Errorf("Expected default query, got %+v", query)
+		}
+	})
+
+	t.Run("maps sort fields to columns", func(t *testing.T) {
+		query, err := parseFestivalQuery(url.Values{"sort": {"endDate"}, "order": {"desc"}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if query.Sort != "end_date" || !query.Desc {
+			t.Errorf("Expected end_date descending, got %+v", query)
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		cases := map[string]url.Values{
+			"invalid from":       {"from": {"01/01/2025"}},
+			"invalid to":         {"to": {"tomorrow"}},
+			"to before from":     {"from": {"2025-06-01"}, "to": {"2025-05-01"}},
+			"invalid upcoming":   {"upcoming": {"soon"}},
+			"upcoming and past":  {"upcoming": {"true"}, "past": {"true"}},
+			"unknown sort":       {"sort": {"population"}},
+			"invalid order":      {"order": {"up"}},
+			"zero limit":         {"limit": {"0"}},
+			"limit above max":    {"limit": {"101"}},
+			"negative offset":    {"offset": {"-1"}},
+			"non numeric offset": {"offset": {"two"}},
+		}
+
+		for name, values := range cases {
+			if _, err := parseFestivalQuery(values); err == nil {
+				t.Errorf("%s: Expected error, got nil", name)
+			}
+		}
+	})
+}
+
+func TestParseBreweryQuery(t *testing.T) {
+	t.Run("parses filters, sort and pagination", func(t *testing.T) {
+		query, err := parseBreweryQuery(url.Values{"city": {"Lille"}, "sort": {"name"}, "limit": {"10"}, "offset": {"5"}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := BreweryQuery{City: "Lille", Sort: "name", Limit: 10, Offset: 5}
+		if query != expected {
+			t.Errorf("Expected %+v, got %+v", expected, query)
+		}
+	})
+
+	t.Run("rejects festival-only sort fields", func(t *testing.T) {
+		if _, err := parseBreweryQuery(url.Values{"sort": {"startDate"}}); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestParseBoundingBox(t *testing.T) {
+	t.Run("parses west,south,east,north", func(t *testing.T) {
+		bounds, err := parseBoundingBox("2.0, 48.5,3.5,51")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := BoundingBox{West: 2.0, South: 48.5, East: 3.5, North: 51}
+		if bounds != expected {
+			t.Errorf("Expected %+v, got %+v", expected, bounds)
+		}
+	})
+
+	t.Run("rejects invalid boxes", func(t *testing.T) {
+		for _, value := range []string{"", "1,2,3", "a,48,3,51", "-181,48,3,51", "2,-91,3,51", "3,48,2,51", "2,51,3,48"} {
+			if _, err := parseBoundingBox(value); err == nil {
+				t.Errorf("%q: Expected error, got nil", value)
+			}
+		}
+	})
+}
+
+func TestParseSearchQuery(t *testing.T) {
+	t.Run("parses text, type and pagination", func(t *testing.T) {
+		query, err := parseSearchQuery(url.Values{"q": {" bière "}, "type": {"brewery"}, "offset": {"5"}})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		expected := SearchQuery{Text: "bière", Type: SearchTypeBrewery, Limit: SearchDefaultLimit, Offset: 5}
+		if query != expected {
+			t.Errorf("Expected %+v, got %+v", expected, query)
+		}
+	})
+
+	t.Run("rejects invalid parameters", func(t *testing.T) {
+		cases := map[string]url.Values{
+			"missing q":    {},
+			"short q":      {"q": {"b"}},
+			"unknown type": {"q": {"biere"}, "type": {"beer"}},
+			"bad limit":    {"q": {"biere"}, "limit": {"0"}},
+		}
+
+		for name, values := range cases {
+			if _, err := parseSearchQuery(values); err == nil {
+				t.Errorf("%s: Expected error, got nil", name)
+			}
+		}
+	})
+}
+
+func TestParseSuggestQuery(t *testing.T) {
+	query, err := parseSuggestQuery(url.Values{"q": {"lil"}, "category": {"city"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := SuggestQuery{Prefix: "lil", Category: SuggestCategoryCity, Limit: SuggestDefaultLimit}
+	if query != expected {
+		t.Errorf("Expected %+v, got %+v", expected, query)
+	}
+
+	if _, err := parseSuggestQuery(url.Values{"category": {"country"}}); err == nil {
+		t.Error("Expected error for unknown category, got nil")
+	}
+}
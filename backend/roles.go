@@ -0,0 +1,60 @@
+package main
+
+import (
+	"slices"
+	"sort"
+)
+
+var rolePermissions = map[string][]string{
+	RoleAdmin: {
+		PermissionFestivalsWrite,
+		PermissionFestivalsDelete,
+		PermissionFestivalsManageAny,
+		PermissionOwnersWrite,
+		PermissionLineupsWrite,
+		PermissionBreweriesWrite,
+		PermissionBreweriesDelete,
+	},
+	RoleOrganizer:   {PermissionFestivalsWrite, PermissionLineupsWrite, PermissionBreweriesWrite},
+	RoleContributor: {PermissionBreweriesWrite},
+	RoleViewer:      {},
+}
+
+func normalizeRoles(roles []string) []string {
+	normalized := []string{}
+	for _, role := range roles {
+		if _, known := rolePermissions[role]; known && !slices.Contains(normalized, role) {
+			normalized = append(normalized, role)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized
+}
+
+func rolesWithPermission(permission string) []string {
+	var roles []string
+	for role, permissions := range rolePermissions {
+		if slices.Contains(permissions, permission) {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+func (u *User) Permissions() []string {
+	permissions := []string{}
+	for _, role := range u.Roles {
+		for _, permission := range rolePermissions[role] {
+			if !slices.Contains(permissions, permission) {
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+	return permissions
+}
+
+func (u *User) Can(permission string) bool {
+	return slices.Contains(u.Permissions(), permission)
+}
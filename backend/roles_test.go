@@ -0,0 +1,57 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNormalizeRoles(t *testing.T) {
+	roles := normalizeRoles([]string{"organizer", "superuser", "admin", "organizer"})
+
+	if !slices.Equal(roles, []string{RoleAdmin, RoleOrganizer}) {
+		t.Errorf("Expected [admin organizer], got %v", roles)
+	}
+
+	if roles := normalizeRoles(nil); roles == nil || len(roles) != 0 {
+		t.Errorf("Expected an empty role list, got %#v", roles)
+	}
+}
+
+func TestUserPermissions(t *testing.T) {
+	tests := []struct {
+		role    string
+		allowed []string
+		denied  []string
+	}{
+		{RoleAdmin, []string{PermissionFestivalsWrite, PermissionFestivalsDelete, PermissionLineupsWrite, PermissionBreweriesWrite, PermissionBreweriesDelete}, nil},
+		{RoleOrganizer, []string{PermissionFestivalsWrite, PermissionLineupsWrite, PermissionBreweriesWrite}, []string{PermissionFestivalsDelete, PermissionBreweriesDelete}},
+		{RoleContributor, []string{PermissionBreweriesWrite}, []string{PermissionFestivalsWrite, PermissionLineupsWrite, PermissionBreweriesDelete}},
+		{RoleViewer, nil, []string{PermissionFestivalsWrite, PermissionBreweriesWrite}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role, func(t *testing.T) {
+			user := &User{ID: "user-123", Roles: []string{tt.role}}
+
+			for _, permission := range tt.allowed {
+				if !user.Can(permission) {
+					t.Errorf("Expected %s to have %s", tt.role, permission)
+				}
+			}
+			for _, permission := range tt.denied {
+				if user.Can(permission) {
+					t.Errorf("Expected %s not to have %s", tt.role, permission)
+				}
+			}
+		})
+	}
+
+	t.Run("combines the permissions of several roles", func(t *testing.T) {
+		user := &User{Roles: []string{RoleContributor, RoleOrganizer}}
+
+		expected := []string{PermissionBreweriesWrite, PermissionFestivalsWrite, PermissionLineupsWrite}
+		if permissions := user.Permissions(); !slices.Equal(permissions, expected) {
+			t.Errorf("Expected %v, got %v", expected, permissions)
+		}
+	})
+}
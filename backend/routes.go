@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+type route struct {
+	path       string
+	methods    []string
+	handler    http.HandlerFunc
+	auth       bool
+	permission string
+	owned      bool
+}
+
+func apiRoutes(db DatabaseInterface, searchIndex *SearchIndex, config Config) []route {
+	origins := config.AllowedOrigins
+	return []route{
+		{path: FestivalsPath, methods: []string{"GET"}, handler: makeFestivalsHandler(db, origins)},
+		{path: FestivalsPath, methods: []string{"POST"}, handler: makeCreateFestivalHandler(db, origins), auth: true, permission: PermissionFestivalsWrite},
+		{path: NearbyFestivalsPath, methods: []string{"GET"}, handler: makeNearbyFestivalsHandler(db, origins)},
+		{path: FestivalMapPath, methods: []string{"GET"}, handler: makeFestivalMapHandler(db, origins)},
+		{path: FestivalsGeoJSONPath, methods: []string{"GET"}, handler: makeFestivalsGeoJSONHandler(db, origins)},
+		{path: FestivalsICalPath, methods: []string{"GET"}, handler: makeFestivalsICalHandler(db, origins)},
+		{path: FestivalPath, methods: []string{"GET"}, handler: makeFestivalHandler(db, origins)},
+		{path: FestivalPath, methods: []string{"PUT", "PATCH"}, handler: makeUpdateFestivalHandler(db), auth: true, permission: PermissionFestivalsWrite, owned: true},
+		{path: FestivalPath, methods: []string{"DELETE"}, handler: makeDeleteFestivalHandler(db), auth: true, permission: PermissionFestivalsDelete, owned: true},
+		{path: FestivalsBreweriesPath, methods: []string{"GET"}, handler: makeFestivalBreweriesHandler(db, origins)},
+		{path: FestivalsBreweriesPath, methods: []string{"POST", "PUT"}, handler: makeUpdateLineupHandler(db), auth: true, permission: PermissionLineupsWrite, owned: true},
+		{path: FestivalBreweryPath, methods: []string{"DELETE"}, handler: makeFestivalBreweryHandler(db, origins), auth: true, permission: PermissionLineupsWrite, owned: true},
+		{path: FestivalOwnersPath, methods: []string{"GET"}, handler: makeFestivalOwnersHandler(db, origins), auth: true, owned: true},
+		{path: FestivalOwnersPath, methods: []string{"POST", "PUT"}, handler: makeUpdateFestivalOwnersHandler(db), auth: true, permission: PermissionOwnersWrite},
+		{path: MyFestivalsPath, methods: []string{"GET"}, handler: makeMyFestivalsHandler(db, origins), auth: true},
+		{path: BreweriesPath, methods: []string{"GET"}, handler: makeBreweriesHandler(db, origins)},
+		{path: BreweriesPath, methods: []string{"POST"}, handler: makeCreateBreweryHandler(db), auth: true, permission: PermissionBreweriesWrite},
+		{path: BreweryPath, methods: []string{"GET"}, handler: makeBreweryHandler(db, origins)},
+		{path: BreweryPath, methods: []string{"PUT", "PATCH"}, handler: makeUpdateBreweryHandler(db), auth: true, permission: PermissionBreweriesWrite},
+		{path: BreweryPath, methods: []string{"DELETE"}, handler: makeDeleteBreweryHandler(db), auth: true, permission: PermissionBreweriesDelete},
+		{path: FeedPath, methods: []string{"GET"}, handler: makeFeedHandler(db, origins, config.FrontendURL)},
+		{path: SearchPath, methods: []string{"GET"}, handler: makeSearchHandler(searchIndex, origins)},
+		{path: SuggestPath, methods: []string{"GET"}, handler: makeSuggestHandler(searchIndex, origins)},
+		{path: LoginPath, methods: []string{"POST"}, handler: makeLoginHandler(db, origins)},
+		{path: RefreshPath, methods: []string{"POST"}, handler: makeRefreshHandler(db, origins)},
+		{path: LogoutPath, methods: []string{"POST"}, handler: makeLogoutHandler(db, origins), auth: true},
+		{path: VerifyPath, methods: []string{"GET"}, handler: makeVerifyHandler(origins), auth: true},
+	}
+}
+
+func newRouter(db DatabaseInterface, config Config) http.Handler {
+	searchIndex := NewSearchIndex(db)
+	routes := apiRoutes(searchIndex, searchIndex, config)
+	protect := func(permission string, owned bool, next http.Handler) http.HandlerFunc {
+		if owned {
+			next = requireFestivalOwner(searchIndex)(next)
+		}
+		if permission != "" {
+			next = requirePermission(permission)(next)
+		}
+		next = requireAuth(searchIndex)(next)
+		return func(w http.ResponseWriter, r *http.Request) {
+			enableCORS(w, r, config.AllowedOrigins)
+			next.ServeHTTP(w, r)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+HealthPath, healthCheckHandler)
+
+	preflight := map[string]bool{}
+	for _, route := range routes {
+		handler := route.handler
+		if route.auth {
+			handler = protect(route.permission, route.owned, handler)
+		}
+
+		for _, method := range route.methods {
+			mux.HandleFunc(method+" "+APIBasePath+route.path, handler)
+			mux.HandleFunc(method+" "+LegacyAPIBasePath+route.path, deprecated(APIBasePath+route.path, handler))
+		}
+
+		if !preflight[route.path] {
+			preflight[route.path] = true
+			mux.HandleFunc("OPTIONS "+APIBasePath+route.path, handler)
+			mux.HandleFunc("OPTIONS "+LegacyAPIBasePath+route.path, deprecated(APIBasePath+route.path, handler))
+		}
+	}
+
+	createFestivalHandler := deprecated(APIBasePath+FestivalsPath, protect(PermissionFestivalsWrite, false, makeCreateFestivalHandler(searchIndex, config.AllowedOrigins)))
+	mux.HandleFunc("POST "+LegacyAPIBasePath+CreateFestivalPath, createFestivalHandler)
+	mux.HandleFunc("OPTIONS "+LegacyAPIBasePath+CreateFestivalPath, createFestivalHandler)
+
+	openAPIHandler := makeOpenAPIHandler(newOpenAPIDocument(), config.AllowedOrigins)
+	legacyOpenAPIHandler := deprecated(APIBasePath+OpenAPIPath, openAPIHandler)
+	for _, method := range []string{"GET", "OPTIONS"} {
+		mux.HandleFunc(method+" "+APIBasePath+OpenAPIPath, openAPIHandler)
+		mux.HandleFunc(method+" "+LegacyAPIBasePath+OpenAPIPath, legacyOpenAPIHandler)
+	}
+
+	return problemFallback(mux)
+}
+
+func deprecated(successor string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HeaderDeprecation, "true")
+		w.Header().Set(HeaderLink, "<"+expandPath(successor, r)+`>; rel="successor-version"`)
+		next(w, r)
+	}
+}
+
+func expandPath(pattern string, r *http.Request) string {
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+			segments[i] = r.PathValue(strings.Trim(segment, "{}"))
+		}
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRoutesDeclarePermissions(t *testing.T) {
+	for _, route := range apiRoutes(&MockDatabase{}, nil, Config{}) {
+		if route.permission != "" && !route.auth {
+			t.Errorf("Route %v %s requires %s without authentication", route.methods, route.path, route.permission)
+		}
+
+		mutating := !slices.Contains(route.methods, "GET")
+		session := route.path == LoginPath || route.path == RefreshPath || route.path == LogoutPath
+		if mutating && !session && route.permission == "" {
+			t.Errorf("Route %v %s does not declare a permission", route.methods, route.path)
+		}
+	}
+}
+
+func TestRouter(t *testing.T) {
+	calls := 0
+	mockDB := &MockDatabase{
+		getFestivalFunc: func(id int64) (*Festival, error) {
+			calls++
+			return &Festival{ID: id, Name: "Test Festival"}, nil
+		},
+		verifyTokenFunc: func(token string) (*User, error) {
+			return &User{ID: "user-123", Roles: []string{RoleAdmin}}, nil
+		},
+		createFestivalFunc: func(festival *FestivalDB) (*FestivalDB, error) {
+			festival.ID = 1
+			return festival, nil
+		},
+	}
+	router := newRouter(mockDB, Config{AllowedOrigins: "*"})
+
+	t.Run("serves versioned routes without deprecation", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/festivals/7", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"id":7`) {
+			t.Fatalf("Expected festival 7, got %d: %s", w.Code, w.Body.String())
+		}
+
+		if w.Header().Get(HeaderDeprecation) != "" {
+			t.Errorf("Expected no %s header, got %s", HeaderDeprecation, w.Header().Get(HeaderDeprecation))
+		}
+	})
+
+	t.Run("marks legacy aliases as deprecated", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/festivals/7", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		if w.Header().Get(HeaderDeprecation) != "true" {
+			t.Errorf("Expected %s true, got %q", HeaderDeprecation, w.Header().Get(HeaderDeprecation))
+		}
+
+		if link := w.Header().Get(HeaderLink); link != `</api/v1/festivals/7>; rel="successor-version"` {
+			t.Errorf("Expected successor link, got %q", link)
+		}
+	})
+
+	t.Run("keeps the legacy create path", func(t *testing.T) {
+		body := []byte(`{"name":"Test Festival","start_date":"2025-10-01","end_date":"2025-10-03"}`)
+		req := httptest.NewRequest("POST", "/api/festivals/create", bytes.NewBuffer(body))
+		req.Header.Set("Authorization", "Bearer valid-token")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusCreated {
+			t.Errorf("Expected status 201, got %d", w.Code)
+		}
+
+		if link := w.Header().Get(HeaderLink); link != `</api/v1/festivals>; rel="successor-version"` {
+			t.Errorf("Expected successor link to the festivals collection, got %q", link)
+		}
+	})
+
+	t.Run("answers 405 with the allowed methods", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v1/breweries/1", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusMethodNotAllowed {
+			t.Fatalf("Expected status 405, got %d", w.Code)
+		}
+
+		allow := w.Header().Get("Allow")
+		for _, method := range []string{"GET", "PUT", "PATCH", "DELETE", "OPTIONS"} {
+			if !strings.Contains(allow, method) {
+				t.Errorf("Expected Allow to list %s, got %q", method, allow)
+			}
+		}
+	})
+
+	t.Run("answers preflight requests", func(t *testing.T) {
+		req := httptest.NewRequest("OPTIONS", "/api/v1/festivals/7/breweries", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK || w.Header().Get(HeaderCORSOrigin) != "*" {
+			t.Errorf("Expected CORS preflight response, got %d %v", w.Code, w.Header())
+		}
+	})
+
+	t.Run("does not reach the store for unknown paths", func(t *testing.T) {
+		before := calls
+		for _, target := range []string{"/api/festivals/abc/whatever", "/api/v1/festivals/7/unknown", "/api/v2/festivals/7"} {
+			req := httptest.NewRequest("GET", target, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != http.StatusNotFound {
+				t.Errorf("%s: Expected status 404, got %d", target, w.Code)
+			}
+		}
+
+		if calls != before {
+			t.Errorf("Expected no store calls, got %d", calls-before)
+		}
+	})
+}
@@ -0,0 +1,480 @@
+package main
+
+import (
+	"context"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+var suggestCategories = []string{SuggestCategoryCity, SuggestCategoryRegion, SuggestCategoryBrewery}
+
+var accentFolds = map[rune]string{
+	'à': "a", 'á': "a", 'â': "a", 'ã': "a", 'ä': "a", 'å': "a", 'æ': "ae",
+	'ç': "c",
+	'è': "e", 'é': "e", 'ê': "e", 'ë': "e",
+	'ì': "i", 'í': "i", 'î': "i", 'ï': "i",
+	'ñ': "n",
+	'ò': "o", 'ó': "o", 'ô': "o", 'õ': "o", 'ö': "o", 'ø': "o", 'œ': "oe",
+	'ù': "u", 'ú': "u", 'û': "u", 'ü': "u",
+	'ý': "y", 'ÿ': "y",
+}
+
+type searchToken struct {
+	text       string
+	start, end int
+}
+
+type searchField struct {
+	text   string
+	weight float64
+	tokens []searchToken
+}
+
+type searchDocument struct {
+	hit    SearchHit
+	fields []searchField
+}
+
+type suggestionEntry struct {
+	Suggestion
+	folded string
+	tokens []searchToken
+}
+
+type searchSnapshot struct {
+	documents   []searchDocument
+	suggestions map[string][]suggestionEntry
+	builtAt     time.Time
+	generation  uint64
+}
+
+type SearchIndex struct {
+	DatabaseInterface
+	mu         sync.RWMutex
+	snapshot   *searchSnapshot
+	generation uint64
+}
+
+func NewSearchIndex(db DatabaseInterface) *SearchIndex {
+	return &SearchIndex{DatabaseInterface: db}
+}
+
+func foldText(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if folded, ok := accentFolds[r]; ok {
+			b.WriteString(folded)
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func tokenize(text string) []searchToken {
+	var tokens []searchToken
+	start := -1
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if isWord && start < 0 {
+			start = i
+		}
+		if !isWord && start >= 0 {
+			tokens = append(tokens, searchToken{text: foldText(text[start:i]), start: start, end: i})
+			start = -1
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, searchToken{text: foldText(text[start:]), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+func newSearchField(text string, weight float64) searchField {
+	return searchField{text: text, weight: weight, tokens: tokenize(text)}
+}
+
+func festivalDocument(festival Festival) searchDocument {
+	indexed := festival
+	return searchDocument{
+		hit: SearchHit{Type: SearchTypeFestival, ID: festival.ID, Name: festival.Name, Festival: &indexed},
+		fields: []searchField{
+			newSearchField(festival.Name, SearchWeightName),
+			newSearchField(festival.City, SearchWeightPlace),
+			newSearchField(festival.Region, SearchWeightPlace),
+			newSearchField(festival.Description, SearchWeightDescription),
+		},
+	}
+}
+
+func breweryDocument(brewery Brewery) searchDocument {
+	indexed := brewery
+	return searchDocument{
+		hit: SearchHit{Type: SearchTypeBrewery, ID: brewery.ID, Name: brewery.Name, Brewery: &indexed},
+		fields: []searchField{
+			newSearchField(brewery.Name, SearchWeightName),
+			newSearchField(brewery.City, SearchWeightPlace),
+			newSearchField(brewery.Description, SearchWeightDescription),
+		},
+	}
+}
+
+func (s *SearchIndex) current(ctx context.Context) (*searchSnapshot, error) {
+	s.mu.RLock()
+	snapshot, generation := s.snapshot, s.generation
+	s.mu.RUnlock()
+
+	if snapshot != nil && snapshot.generation == generation && time.Since(snapshot.builtAt) < SearchIndexMaxAge {
+		return snapshot, nil
+	}
+
+	snapshot, err := s.build(ctx, generation)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if stored := s.snapshot; stored == nil || stored.generation < snapshot.generation ||
+		(stored.generation == snapshot.generation && stored.builtAt.Before(snapshot.builtAt)) {
+		s.snapshot = snapshot
+	}
+	s.mu.Unlock()
+	return snapshot, nil
+}
+
+func (s *SearchIndex) build(ctx context.Context, generation uint64) (*searchSnapshot, error) {
+	festivals, err := s.DatabaseInterface.GetFestivals(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	breweries, err := s.DatabaseInterface.GetBreweries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	documents := make([]searchDocument, 0, len(festivals)+len(breweries))
+	for _, festival := range festivals {
+		documents = append(documents, festivalDocument(festival))
+	}
+	for _, brewery := range breweries {
+		documents = append(documents, breweryDocument(brewery))
+	}
+
+	values := map[string][]string{}
+	for _, festival := range festivals {
+		values[SuggestCategoryCity] = append(values[SuggestCategoryCity], festival.City)
+		values[SuggestCategoryRegion] = append(values[SuggestCategoryRegion], festival.Region)
+	}
+	for _, brewery := range breweries {
+		values[SuggestCategoryCity] = append(values[SuggestCategoryCity], brewery.City)
+		values[SuggestCategoryBrewery] = append(values[SuggestCategoryBrewery], brewery.Name)
+	}
+
+	suggestions := make(map[string][]suggestionEntry, len(suggestCategories))
+	for _, category := range suggestCategories {
+		suggestions[category] = countSuggestions(values[category])
+	}
+
+	return &searchSnapshot{
+		documents:   documents,
+		suggestions: suggestions,
+		builtAt:     time.Now(),
+		generation:  generation,
+	}, nil
+}
+
+func (s *SearchIndex) invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+}
+
+func (s *SearchIndex) Search(ctx context.Context, query SearchQuery) ([]SearchHit, error) {
+	snapshot, err := s.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := tokenize(query.Text)
+	hits := []SearchHit{}
+	for _, document := range snapshot.documents {
+		if query.Type != "" && document.hit.Type != query.Type {
+			continue
+		}
+		if hit, ok := document.match(terms); ok {
+			hits = append(hits, hit)
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Type != hits[j].Type {
+			return hits[i].Type == SearchTypeFestival
+		}
+		return hits[i].ID < hits[j].ID
+	})
+	return hits, nil
+}
+
+func countSuggestions(values []string) []suggestionEntry {
+	positions := map[string]int{}
+	entries := []suggestionEntry{}
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		folded := foldText(value)
+		if i, ok := positions[folded]; ok {
+			entries[i].Count++
+			continue
+		}
+		positions[folded] = len(entries)
+		entries = append(entries, suggestionEntry{
+			Suggestion: Suggestion{Value: value, Count: 1},
+			folded:     folded,
+			tokens:     tokenize(value),
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].folded < entries[j].folded
+	})
+	return entries
+}
+
+func (s *SearchIndex) Suggest(ctx context.Context, query SuggestQuery) (map[string][]Suggestion, error) {
+	snapshot, err := s.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := foldText(strings.TrimSpace(query.Prefix))
+	result := map[string][]Suggestion{}
+	for _, category := range suggestCategories {
+		if query.Category != "" && query.Category != category {
+			continue
+		}
+
+		var leading, inner []Suggestion
+		for _, entry := range snapshot.suggestions[category] {
+			if strings.HasPrefix(entry.folded, prefix) {
+				leading = append(leading, entry.Suggestion)
+				continue
+			}
+			for _, token := range entry.tokens {
+				if strings.HasPrefix(token.text, prefix) {
+					inner = append(inner, entry.Suggestion)
+					break
+				}
+			}
+		}
+
+		result[category] = paginate(append(append([]Suggestion{}, leading...), inner...), query.Limit, 0)
+	}
+	return result, nil
+}
+
+func (d searchDocument) match(terms []searchToken) (SearchHit, bool) {
+	if len(terms) == 0 {
+		return SearchHit{}, false
+	}
+
+	matched := make([][]searchToken, len(d.fields))
+	score := 0.0
+	for _, term := range terms {
+		best := 0.0
+		for i, field := range d.fields {
+			for _, token := range field.tokens {
+				var weight float64
+				switch {
+				case token.text == term.text:
+					weight = field.weight
+				case strings.HasPrefix(token.text, term.text):
+					weight = field.weight * SearchPrefixFactor
+				default:
+					continue
+				}
+				matched[i] = append(matched[i], token)
+				best = max(best, weight)
+			}
+		}
+		if best == 0 {
+			return SearchHit{}, false
+		}
+		score += best
+	}
+
+	snippetField := -1
+	for i, field := range d.fields {
+		if len(matched[i]) == 0 {
+			continue
+		}
+		if field.weight == SearchWeightDescription {
+			snippetField = i
+			break
+		}
+		if snippetField < 0 || field.weight > d.fields[snippetField].weight {
+			snippetField = i
+		}
+	}
+
+	hit := d.hit
+	hit.Score = score
+	hit.Snippet = highlight(d.fields[snippetField].text, matched[snippetField])
+	return hit, true
+}
+
+func highlight(text string, matches []searchToken) string {
+	sort.Slice(matches, func(i, j int) bool { return matches[i].start < matches[j].start })
+
+	start, end := snippetBounds(text, matches[0].start)
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	cursor := start
+	for _, match := range matches {
+		if match.start < cursor || match.end > end {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[cursor:match.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[match.start:match.end]))
+		b.WriteString("</mark>")
+		cursor = match.end
+	}
+	b.WriteString(html.EscapeString(text[cursor:end]))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+func snippetBounds(text string, focus int) (int, int) {
+	start := focus
+	for runes := 0; start > 0 && runes < SearchSnippetRadius; runes++ {
+		start--
+		for start > 0 && text[start]&0xC0 == 0x80 {
+			start--
+		}
+	}
+	if start > 0 {
+		if space := strings.IndexByte(text[start:focus], ' '); space >= 0 {
+			start += space + 1
+		}
+	}
+
+	end := focus
+	for runes := 0; end < len(text) && runes < 2*SearchSnippetRadius; runes++ {
+		end++
+		for end < len(text) && text[end]&0xC0 == 0x80 {
+			end++
+		}
+	}
+	if end < len(text) {
+		if space := strings.LastIndexByte(text[focus:end], ' '); space > 0 {
+			end = focus + space
+		}
+	}
+
+	return start, end
+}
+
+func (s *SearchIndex) CreateFestival(ctx context.Context, festival *FestivalDB) (*FestivalDB, error) {
+	created, err := s.DatabaseInterface.CreateFestival(ctx, festival)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return created, nil
+}
+
+func (s *SearchIndex) UpdateFestival(ctx context.Context, id int64, festival *FestivalDB) (*FestivalDB, error) {
+	updated, err := s.DatabaseInterface.UpdateFestival(ctx, id, festival)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return updated, nil
+}
+
+func (s *SearchIndex) DeleteFestival(ctx context.Context, id int64) error {
+	if err := s.DatabaseInterface.DeleteFestival(ctx, id); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *SearchIndex) CreateBrewery(ctx context.Context, brewery *BreweryDB) (*BreweryDB, error) {
+	created, err := s.DatabaseInterface.CreateBrewery(ctx, brewery)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return created, nil
+}
+
+func (s *SearchIndex) UpdateBrewery(ctx context.Context, id int64, brewery *BreweryDB) (*BreweryDB, error) {
+	updated, err := s.DatabaseInterface.UpdateBrewery(ctx, id, brewery)
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidate()
+	return updated, nil
+}
+
+func (s *SearchIndex) DeleteBrewery(ctx context.Context, id int64, cascade bool) error {
+	if err := s.DatabaseInterface.DeleteBrewery(ctx, id, cascade); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *SearchIndex) AddBreweriesToFestival(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	if err := s.DatabaseInterface.AddBreweriesToFestival(ctx, festivalID, breweryIDs); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *SearchIndex) RemoveBreweryFromFestival(ctx context.Context, festivalID, breweryID int64) error {
+	if err := s.DatabaseInterface.RemoveBreweryFromFestival(ctx, festivalID, breweryID); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
+
+func (s *SearchIndex) ReplaceFestivalBreweries(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	if err := s.DatabaseInterface.ReplaceFestivalBreweries(ctx, festivalID, breweryIDs); err != nil {
+		return err
+	}
+
+	s.invalidate()
+	return nil
+}
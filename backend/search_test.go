@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestSearchIndex(t *testing.T) *SearchIndex {
+	t.Helper()
+
+	seed := newTestSeed()
+	seed.Festivals[0].Description = "La grande fête de la Bière artisanale du Nord"
+	seed.Breweries[0].Description = "Brasserie du Nord, bières de garde"
+	db, err := NewMemoryDatabase(seed)
+	if err != nil {
+		t.Fatalf("Failed to create memory database: %v", err)
+	}
+
+	return NewSearchIndex(db)
+}
+
+func TestFoldText(t *testing.T) {
+	if folded := foldText("Bière Île-de-France Œuvre ÇA"); folded != "biere ile-de-france oeuvre ca" {
+		t.Errorf("Expected accents and case folded, got %q", folded)
+	}
+}
+
+func TestSearchIndex(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("matches without accents and highlights the snippet", func(t *testing.T) {
+		index := newTestSearchIndex(t)
+
+		hits, err := index.Search(ctx, SearchQuery{Text: "biere"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(hits) != 2 || hits[0].Type != SearchTypeFestival || hits[1].Type != SearchTypeBrewery {
+			t.Fatalf("Expected festival then brewery, got %+v", hits)
+		}
+
+		if hits[0].Snippet != "La grande fête de la <mark>Bière</mark> artisanale du Nord" {
+			t.Errorf("Expected highlighted description, got %q", hits[0].Snippet)
+		}
+
+		if hits[0].Festival == nil || hits[0].Festival.BreweryCount != 2 {
+			t.Errorf("Expected festival payload, got %+v", hits[0].Festival)
+		}
+	})
+
+	t.Run("ranks name matches above description matches", func(t *testing.T) {
+		index := newTestSearchIndex(t)
+
+		hits, _ := index.Search(ctx, SearchQuery{Text: "nord"})
+
+		if len(hits) != 2 || hits[0].Name != "Brasserie du Nord" || hits[0].Score <= hits[1].Score {
+			t.Fatalf("Expected Brasserie du Nord first, got %+v", hits)
+		}
+
+		hits, _ = index.Search(ctx, SearchQuery{Text: "brasserie du"})
+		if len(hits) != 1 || hits[0].Snippet != "<mark>Brasserie</mark> <mark>du</mark> Nord, bières de garde" {
+			t.Errorf("Expected both terms highlighted, got %+v", hits)
+		}
+	})
+
+	t.Run("requires every term and filters by type", func(t *testing.T) {
+		index := newTestSearchIndex(t)
+
+		if hits, _ := index.Search(ctx, SearchQuery{Text: "brasserie strasbourg"}); len(hits) != 0 {
+			t.Errorf("Expected no hit with both terms, got %+v", hits)
+		}
+
+		hits, _ := index.Search(ctx, SearchQuery{Text: "rennes", Type: SearchTypeBrewery})
+		if len(hits) != 1 || hits[0].Name != "Brasserie de Bretagne" {
+			t.Errorf("Expected only the Rennes brewery, got %+v", hits)
+		}
+	})
+
+	t.Run("reindexes after writes", func(t *testing.T) {
+		index := newTestSearchIndex(t)
+
+		if hits, _ := index.Search(ctx, SearchQuery{Text: "strasbourg"}); len(hits) != 0 {
+			t.Fatalf("Expected no hit before creation, got %+v", hits)
+		}
+
+		_, err := index.CreateFestival(ctx, &FestivalDB{Name: "Fête de la Bière", City: "Strasbourg", StartDate: "2025-12-01", EndDate: "2025-12-02"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		hits, _ := index.Search(ctx, SearchQuery{Text: "strasbourg"})
+		if len(hits) != 1 || hits[0].Snippet != "<mark>Strasbourg</mark>" {
+			t.Errorf("Expected the new festival, got %+v", hits)
+		}
+
+		if err := index.DeleteBrewery(ctx, 1, true); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if hits, _ := index.Search(ctx, SearchQuery{Text: "garde"}); len(hits) != 0 {
+			t.Errorf("Expected deleted brewery to be gone, got %+v", hits)
+		}
+	})
+
+	t.Run("surfaces store errors", func(t *testing.T) {
+		index := NewSearchIndex(&MockDatabase{
+			getFestivalsFunc: func() ([]Festival, error) { return nil, errors.New("store down") },
+		})
+
+		if _, err := index.Search(ctx, SearchQuery{Text: "biere"}); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+
+	t.Run("serves searches and writes while a rebuild waits on the store", func(t *testing.T) {
+		var calls atomic.Int32
+		blocked := make(chan struct{})
+		release := make(chan struct{})
+		index := NewSearchIndex(&MockDatabase{
+			getFestivalsFunc: func() ([]Festival, error) {
+				if calls.Add(1) == 2 {
+					close(blocked)
+					<-release
+				}
+				return []Festival{{ID: 1, Name: "Fête de la Bière"}}, nil
+			},
+		})
+
+		index.Search(ctx, SearchQuery{Text: "biere"})
+		index.invalidate()
+
+		slow := make(chan struct{})
+		go func() {
+			index.Search(ctx, SearchQuery{Text: "biere"})
+			close(slow)
+		}()
+		<-blocked
+
+		done := make(chan []SearchHit)
+		go func() {
+			index.invalidate()
+			hits, _ := index.Search(ctx, SearchQuery{Text: "biere"})
+			done <- hits
+		}()
+
+		select {
+		case hits := <-done:
+			if len(hits) != 1 {
+				t.Errorf("Expected one hit, got %+v", hits)
+			}
+		case <-time.After(time.Second):
+			t.Error("Expected search and invalidation not to wait for the pending rebuild")
+		}
+
+		close(release)
+		<-slow
+	})
+}
+
+func TestHighlight(t *testing.T) {
+	text := "Une sélection de plus de cinquante brasseries <indépendantes> venues de toute la France et de Belgique pour trois jours de dégustation"
+	var matches []searchToken
+	for _, token := range tokenize(text) {
+		if token.text == "independantes" {
+			matches = append(matches, token)
+		}
+	}
+
+	snippet := highlight(text, matches)
+
+	if snippet != "…de plus de cinquante brasseries &lt;<mark>indépendantes</mark>&gt; venues de toute la France et de Belgique pour trois jours de…" {
+		t.Errorf("Unexpected snippet %q", snippet)
+	}
+}
+
+func TestSearchIndexSuggest(t *testing.T) {
+	ctx := context.Background()
+	index := newTestSearchIndex(t)
+
+	t.Run("returns distinct values per category with their frequency", func(t *testing.T) {
+		suggestions, err := index.Suggest(ctx, SuggestQuery{Prefix: "", Limit: 5})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		cities := suggestions[SuggestCategoryCity]
+		if len(cities) != 2 || cities[0] != (Suggestion{Value: "Lille", Count: 2}) || cities[1] != (Suggestion{Value: "Rennes", Count: 2}) {
+			t.Errorf("Expected Lille and Rennes twice each, got %+v", cities)
+		}
+
+		if len(suggestions[SuggestCategoryRegion]) != 2 || len(suggestions[SuggestCategoryBrewery]) != 2 {
+			t.Errorf("Expected regions and breweries, got %+v", suggestions)
+		}
+	})
+
+	t.Run("matches accent-insensitive prefixes of words", func(t *testing.T) {
+		suggestions, _ := index.Suggest(ctx, SuggestQuery{Prefix: "BRE", Category: SuggestCategoryRegion, Limit: 5})
+
+		if len(suggestions) != 1 || len(suggestions[SuggestCategoryRegion]) != 1 || suggestions[SuggestCategoryRegion][0].Value != "Bretagne" {
+			t.Errorf("Expected only Bretagne, got %+v", suggestions)
+		}
+
+		suggestions, _ = index.Suggest(ctx, SuggestQuery{Prefix: "bre", Category: SuggestCategoryBrewery, Limit: 1})
+		if breweries := suggestions[SuggestCategoryBrewery]; len(breweries) != 1 || breweries[0].Value != "Brasserie de Bretagne" {
+			t.Errorf("Expected the word match limited to one, got %+v", breweries)
+		}
+	})
+
+	t.Run("reflects writes", func(t *testing.T) {
+		index.CreateFestival(ctx, &FestivalDB{Name: "Fête", City: "Évry", StartDate: "2025-12-01", EndDate: "2025-12-01"})
+
+		suggestions, _ := index.Suggest(ctx, SuggestQuery{Prefix: "ev", Category: SuggestCategoryCity, Limit: 5})
+		if cities := suggestions[SuggestCategoryCity]; len(cities) != 1 || cities[0].Value != "Évry" {
+			t.Errorf("Expected the new city, got %+v", cities)
+		}
+	})
+}
@@ -0,0 +1,874 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+	_ "modernc.org/sqlite"
+)
+
+var sqliteMigrations = []string{
+	sqliteSchema,
+	`ALTER TABLE festivals ADD COLUMN cancelled INTEGER NOT NULL DEFAULT 0`,
+	`ALTER TABLE festivals ADD COLUMN created_at TEXT NOT NULL DEFAULT '';
+	ALTER TABLE festivals ADD COLUMN updated_at TEXT NOT NULL DEFAULT '';
+	UPDATE festivals SET created_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now'), updated_at = strftime('%Y-%m-%dT%H:%M:%SZ', 'now');`,
+	`ALTER TABLE sessions ADD COLUMN refresh_expires_at INTEGER NOT NULL DEFAULT 0;
+	UPDATE sessions SET refresh_expires_at = expires_at;`,
+	`CREATE TABLE user_roles (
+		user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		role    TEXT NOT NULL,
+		PRIMARY KEY (user_id, role)
+	);
+	INSERT INTO user_roles (user_id, role) SELECT id, 'admin' FROM users;`,
+	`ALTER TABLE festivals ADD COLUMN created_by TEXT NOT NULL DEFAULT '';
+	CREATE TABLE festival_owners (
+		festival_id INTEGER NOT NULL REFERENCES festivals(id) ON DELETE CASCADE,
+		user_id     TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		PRIMARY KEY (festival_id, user_id)
+	);`,
+}
+
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS festivals (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	name        TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	start_date  TEXT NOT NULL,
+	end_date    TEXT NOT NULL,
+	city        TEXT NOT NULL DEFAULT '',
+	region      TEXT NOT NULL DEFAULT '',
+	latitude    REAL NOT NULL DEFAULT 0,
+	longitude   REAL NOT NULL DEFAULT 0,
+	image       TEXT NOT NULL DEFAULT '',
+	website     TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS breweries (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	name        TEXT NOT NULL,
+	description TEXT NOT NULL DEFAULT '',
+	city        TEXT NOT NULL DEFAULT '',
+	website     TEXT NOT NULL DEFAULT '',
+	logo        TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS festivals_breweries (
+	festival_id INTEGER NOT NULL REFERENCES festivals(id) ON DELETE CASCADE,
+	brewery_id  INTEGER NOT NULL REFERENCES breweries(id) ON DELETE CASCADE,
+	PRIMARY KEY (festival_id, brewery_id)
+);
+
+CREATE TABLE IF NOT EXISTS users (
+	id            TEXT PRIMARY KEY,
+	email         TEXT NOT NULL UNIQUE,
+	password_hash TEXT NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS sessions (
+	access_token  TEXT PRIMARY KEY,
+	refresh_token TEXT NOT NULL UNIQUE,
+	user_id       TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	expires_at    INTEGER NOT NULL
+);
+`
+
+const festivalColumns = "f.id, f.name, f.description, f.start_date, f.end_date, f.city, f.region, f.latitude, f.longitude, f.image, f.website, f.cancelled, f.created_by, f.created_at, f.updated_at"
+
+const breweryColumns = "b.id, b.name, b.description, b.city, b.website, b.logo"
+
+type SQLiteDatabase struct {
+	db *sql.DB
+}
+
+type sqlExecer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+type sqlQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+func NewSQLiteDatabase(path string) (*SQLiteDatabase, error) {
+	if path == "" {
+		return nil, fmt.Errorf("SQLITE_PATH is required for the sqlite driver")
+	}
+
+	conn, err := sql.Open("sqlite", "file:"+path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	if err := migrateSQLite(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &SQLiteDatabase{db: conn}, nil
+}
+
+func migrateSQLite(conn *sql.DB) error {
+	var version int
+	if err := conn.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return fmt.Errorf("failed to read sqlite schema version: %w", err)
+	}
+
+	for ; version < len(sqliteMigrations); version++ {
+		tx, err := conn.Begin()
+		if err != nil {
+			return fmt.Errorf("failed to start sqlite migration: %w", err)
+		}
+
+		if _, err := tx.Exec(sqliteMigrations[version]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to apply sqlite migration %d: %w", version+1, err)
+		}
+
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", version+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("failed to record sqlite migration %d: %w", version+1, err)
+		}
+
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit sqlite migration %d: %w", version+1, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLiteDatabase) Close() error {
+	return s.db.Close()
+}
+
+func (s *SQLiteDatabase) GetFestivals(ctx context.Context) ([]Festival, error) {
+	return s.queryFestivals(ctx, "", "f.id")
+}
+
+func (s *SQLiteDatabase) ListFestivals(ctx context.Context, query FestivalQuery) ([]Festival, int, error) {
+	var conditions []string
+	var args []any
+	if query.Region != "" {
+		conditions = append(conditions, "f.region = ?")
+		args = append(args, query.Region)
+	}
+	if query.City != "" {
+		conditions = append(conditions, "f.city = ?")
+		args = append(args, query.City)
+	}
+	if query.From != "" {
+		conditions = append(conditions, "f.end_date >= ?")
+		args = append(args, query.From)
+	}
+	if query.To != "" {
+		conditions = append(conditions, "f.start_date <= ?")
+		args = append(args, query.To)
+	}
+
+	today := time.Now().UTC().Format(DefaultTimeFormat)
+	if query.Upcoming {
+		conditions = append(conditions, "f.end_date >= ?")
+		args = append(args, today)
+	}
+	if query.Past {
+		conditions = append(conditions, "f.end_date < ?")
+		args = append(args, today)
+	}
+	if query.Bounds != (BoundingBox{}) {
+		conditions = append(conditions, "f.latitude BETWEEN ? AND ?", "f.longitude BETWEEN ? AND ?")
+		args = append(args, query.Bounds.South, query.Bounds.North, query.Bounds.West, query.Bounds.East)
+	}
+
+	where := sqliteWhere(conditions)
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM festivals f "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count festivals: %w", err)
+	}
+
+	festivals, err := s.queryFestivals(ctx, where, sqliteOrderBy("f", query.Sort, query.Desc, query.Limit, query.Offset), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return festivals, total, nil
+}
+
+func sqliteWhere(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(conditions, " AND ")
+}
+
+func sqliteOrderBy(alias, column string, desc bool, limit, offset int) string {
+	if column == "" {
+		column = "id"
+	}
+
+	orderBy := alias + "." + column
+	if desc {
+		orderBy += " DESC"
+	}
+	if column != "id" {
+		orderBy += ", " + alias + ".id"
+	}
+
+	if limit > 0 || offset > 0 {
+		if limit == 0 {
+			limit = -1
+		}
+		orderBy += fmt.Sprintf(" LIMIT %d OFFSET %d", limit, offset)
+	}
+
+	return orderBy
+}
+
+func (s *SQLiteDatabase) GetFestival(ctx context.Context, id int64) (*Festival, error) {
+	festivals, err := s.queryFestivals(ctx, "WHERE f.id = ?", "f.id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(festivals) == 0 {
+		return nil, fmt.Errorf("festival %d: %w", id, ErrNotFound)
+	}
+
+	return &festivals[0], nil
+}
+
+func (s *SQLiteDatabase) GetFestivalsByBrewery(ctx context.Context, breweryID int64) ([]Festival, error) {
+	return s.queryFestivals(ctx, "WHERE f.id IN (SELECT festival_id FROM festivals_breweries WHERE brewery_id = ?)", "f.id", breweryID)
+}
+
+func (s *SQLiteDatabase) queryFestivals(ctx context.Context, where, orderBy string, args ...any) ([]Festival, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+festivalColumns+`, COUNT(fb.brewery_id)
+		FROM festivals f
+		LEFT JOIN festivals_breweries fb ON fb.festival_id = f.id
+		`+where+`
+		GROUP BY f.id
+		ORDER BY `+orderBy, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch festivals: %w", err)
+	}
+	defer rows.Close()
+
+	festivals := []Festival{}
+	for rows.Next() {
+		var fdb FestivalDB
+		var breweryCount int
+		if err := rows.Scan(&fdb.ID, &fdb.Name, &fdb.Description, &fdb.StartDate, &fdb.EndDate,
+			&fdb.City, &fdb.Region, &fdb.Latitude, &fdb.Longitude, &fdb.Image, &fdb.Website, &fdb.Cancelled, &fdb.CreatedBy, &fdb.CreatedAt, &fdb.UpdatedAt, &breweryCount); err != nil {
+			return nil, fmt.Errorf("failed to scan festival: %w", err)
+		}
+		festivals = append(festivals, festivalFromDB(fdb, breweryCount))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch festivals: %w", err)
+	}
+
+	return festivals, nil
+}
+
+func (s *SQLiteDatabase) GetBreweries(ctx context.Context) ([]Brewery, error) {
+	return s.queryBreweries(ctx, "", "b.id")
+}
+
+func (s *SQLiteDatabase) ListBreweries(ctx context.Context, query BreweryQuery) ([]Brewery, int, error) {
+	var conditions []string
+	var args []any
+	if query.City != "" {
+		conditions = append(conditions, "b.city = ?")
+		args = append(args, query.City)
+	}
+
+	where := sqliteWhere(conditions)
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM breweries b "+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count breweries: %w", err)
+	}
+
+	breweries, err := s.queryBreweries(ctx, where, sqliteOrderBy("b", query.Sort, query.Desc, query.Limit, query.Offset), args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return breweries, total, nil
+}
+
+func (s *SQLiteDatabase) GetBrewery(ctx context.Context, id int64) (*Brewery, error) {
+	breweries, err := s.queryBreweries(ctx, "WHERE b.id = ?", "b.id", id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(breweries) == 0 {
+		return nil, fmt.Errorf("brewery %d: %w", id, ErrNotFound)
+	}
+
+	return &breweries[0], nil
+}
+
+func (s *SQLiteDatabase) queryBreweries(ctx context.Context, where, orderBy string, args ...any) ([]Brewery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+breweryColumns+`, COUNT(fb.festival_id)
+		FROM breweries b
+		LEFT JOIN festivals_breweries fb ON fb.brewery_id = b.id
+		`+where+`
+		GROUP BY b.id
+		ORDER BY `+orderBy, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch breweries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBreweries(rows, true)
+}
+
+func (s *SQLiteDatabase) GetBreweriesByFestival(ctx context.Context, festivalID int64) ([]Brewery, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+breweryColumns+`
+		FROM festivals_breweries fb
+		JOIN breweries b ON b.id = fb.brewery_id
+		WHERE fb.festival_id = ?
+		ORDER BY b.id`, festivalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch breweries: %w", err)
+	}
+	defer rows.Close()
+
+	return scanBreweries(rows, false)
+}
+
+func scanBreweries(rows *sql.Rows, withCount bool) ([]Brewery, error) {
+	breweries := []Brewery{}
+	for rows.Next() {
+		var bdb BreweryDB
+		var festivalCount int
+		dest := []any{&bdb.ID, &bdb.Name, &bdb.Description, &bdb.City, &bdb.Website, &bdb.Logo}
+		if withCount {
+			dest = append(dest, &festivalCount)
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("failed to scan brewery: %w", err)
+		}
+		breweries = append(breweries, breweryFromDB(bdb, festivalCount))
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch breweries: %w", err)
+	}
+
+	return breweries, nil
+}
+
+func (s *SQLiteDatabase) CreateFestival(ctx context.Context, festival *FestivalDB) (*FestivalDB, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create festival: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := timestamp(time.Now())
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO festivals (name, description, start_date, end_date, city, region, latitude, longitude, image, website, cancelled, created_by, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		festival.Name, festival.Description, festival.StartDate, festival.EndDate, festival.City,
+		festival.Region, festival.Latitude, festival.Longitude, festival.Image, festival.Website, festival.Cancelled, festival.CreatedBy, now, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create festival: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read created festival id: %w", err)
+	}
+
+	if festival.CreatedBy != "" {
+		if err := insertOwners(ctx, tx, id, []string{festival.CreatedBy}); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create festival: %w", err)
+	}
+
+	created := *festival
+	created.ID = id
+	created.CreatedAt = now
+	created.UpdatedAt = now
+	return &created, nil
+}
+
+func (s *SQLiteDatabase) UpdateFestival(ctx context.Context, id int64, festival *FestivalDB) (*FestivalDB, error) {
+	updated := *festival
+	updated.ID = id
+	updated.UpdatedAt = timestamp(time.Now())
+
+	err := s.db.QueryRowContext(ctx, `
+		UPDATE festivals
+		SET name = ?, description = ?, start_date = ?, end_date = ?, city = ?, region = ?,
+			latitude = ?, longitude = ?, image = ?, website = ?, cancelled = ?, updated_at = ?
+		WHERE id = ?
+		RETURNING created_by, created_at`,
+		festival.Name, festival.Description, festival.StartDate, festival.EndDate, festival.City, festival.Region,
+		festival.Latitude, festival.Longitude, festival.Image, festival.Website, festival.Cancelled, updated.UpdatedAt, id).
+		Scan(&updated.CreatedBy, &updated.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("festival %d: %w", id, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update festival %d: %w", id, err)
+	}
+
+	return &updated, nil
+}
+
+func (s *SQLiteDatabase) DeleteFestival(ctx context.Context, id int64) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM festivals WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete festival %d: %w", id, err)
+	}
+
+	return expectAffected(result, "festival", id)
+}
+
+func (s *SQLiteDatabase) CreateBrewery(ctx context.Context, brewery *BreweryDB) (*BreweryDB, error) {
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO breweries (name, description, city, website, logo)
+		VALUES (?, ?, ?, ?, ?)`,
+		brewery.Name, brewery.Description, brewery.City, brewery.Website, brewery.Logo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create brewery: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read created brewery id: %w", err)
+	}
+
+	created := *brewery
+	created.ID = id
+	return &created, nil
+}
+
+func (s *SQLiteDatabase) UpdateBrewery(ctx context.Context, id int64, brewery *BreweryDB) (*BreweryDB, error) {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE breweries
+		SET name = ?, description = ?, city = ?, website = ?, logo = ?
+		WHERE id = ?`,
+		brewery.Name, brewery.Description, brewery.City, brewery.Website, brewery.Logo, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update brewery %d: %w", id, err)
+	}
+
+	if err := expectAffected(result, "brewery", id); err != nil {
+		return nil, err
+	}
+
+	updated := *brewery
+	updated.ID = id
+	return &updated, nil
+}
+
+func (s *SQLiteDatabase) DeleteBrewery(ctx context.Context, id int64, cascade bool) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete brewery %d: %w", id, err)
+	}
+	defer tx.Rollback()
+
+	if !cascade {
+		var linked bool
+		err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM festivals_breweries WHERE brewery_id = ?)", id).Scan(&linked)
+		if err != nil {
+			return fmt.Errorf("failed to check festivals for brewery %d: %w", id, err)
+		}
+
+		if linked {
+			return fmt.Errorf("brewery %d is linked to festivals: %w", id, ErrConflict)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM breweries WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete brewery %d: %w", id, err)
+	}
+
+	if err := expectAffected(result, "brewery", id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to delete brewery %d: %w", id, err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteDatabase) AddBreweriesToFestival(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to add breweries to festival %d: %w", festivalID, err)
+	}
+	defer tx.Rollback()
+
+	if err := insertLineup(ctx, tx, festivalID, breweryIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to add breweries to festival %d: %w", festivalID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteDatabase) RemoveBreweryFromFestival(ctx context.Context, festivalID, breweryID int64) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM festivals_breweries WHERE festival_id = ? AND brewery_id = ?", festivalID, breweryID)
+	if err != nil {
+		return fmt.Errorf("failed to remove brewery %d from festival %d: %w", breweryID, festivalID, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("brewery %d in festival %d: %w", breweryID, festivalID, ErrNotFound)
+	}
+
+	return nil
+}
+
+func (s *SQLiteDatabase) ReplaceFestivalBreweries(ctx context.Context, festivalID int64, breweryIDs []int64) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to replace breweries of festival %d: %w", festivalID, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM festivals_breweries WHERE festival_id = ?", festivalID); err != nil {
+		return fmt.Errorf("failed to replace breweries of festival %d: %w", festivalID, err)
+	}
+
+	if err := insertLineup(ctx, tx, festivalID, breweryIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to replace breweries of festival %d: %w", festivalID, err)
+	}
+
+	return nil
+}
+
+func insertLineup(ctx context.Context, tx *sql.Tx, festivalID int64, breweryIDs []int64) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM festivals WHERE id = ?)", festivalID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check festival %d: %w", festivalID, err)
+	}
+	if !exists {
+		return fmt.Errorf("festival %d: %w", festivalID, ErrNotFound)
+	}
+
+	for _, breweryID := range breweryIDs {
+		var linked bool
+		err := tx.QueryRowContext(ctx, `
+			SELECT EXISTS (SELECT 1 FROM breweries WHERE id = ?),
+				EXISTS (SELECT 1 FROM festivals_breweries WHERE festival_id = ? AND brewery_id = ?)`,
+			breweryID, festivalID, breweryID).Scan(&exists, &linked)
+		if err != nil {
+			return fmt.Errorf("failed to check brewery %d: %w", breweryID, err)
+		}
+		if !exists {
+			return fmt.Errorf("brewery %d: %w", breweryID, ErrNotFound)
+		}
+		if linked {
+			return fmt.Errorf("brewery %d is already in festival %d: %w", breweryID, festivalID, ErrConflict)
+		}
+
+		if _, err := tx.ExecContext(ctx, "INSERT INTO festivals_breweries (festival_id, brewery_id) VALUES (?, ?)", festivalID, breweryID); err != nil {
+			return fmt.Errorf("failed to add brewery %d to festival %d: %w", breweryID, festivalID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SQLiteDatabase) GetFestivalOwners(ctx context.Context, festivalID int64) ([]string, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT o.user_id
+		FROM festivals f
+		LEFT JOIN festival_owners o ON o.festival_id = f.id
+		WHERE f.id = ?
+		ORDER BY o.user_id`, festivalID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch owners of festival %d: %w", festivalID, err)
+	}
+	defer rows.Close()
+
+	found := false
+	owners := []string{}
+	for rows.Next() {
+		found = true
+		var userID sql.NullString
+		if err := rows.Scan(&userID); err != nil {
+			return nil, fmt.Errorf("failed to scan owner: %w", err)
+		}
+		if userID.Valid {
+			owners = append(owners, userID.String)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to fetch owners of festival %d: %w", festivalID, err)
+	}
+
+	if !found {
+		return nil, fmt.Errorf("festival %d: %w", festivalID, ErrNotFound)
+	}
+
+	return owners, nil
+}
+
+func (s *SQLiteDatabase) GetFestivalsByOwner(ctx context.Context, userID string) ([]Festival, error) {
+	return s.queryFestivals(ctx, "WHERE f.id IN (SELECT festival_id FROM festival_owners WHERE user_id = ?)", "f.id", userID)
+}
+
+func (s *SQLiteDatabase) AddFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to add owners to festival %d: %w", festivalID, err)
+	}
+	defer tx.Rollback()
+
+	if err := insertOwners(ctx, tx, festivalID, userIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to add owners to festival %d: %w", festivalID, err)
+	}
+
+	return nil
+}
+
+func (s *SQLiteDatabase) ReplaceFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to replace owners of festival %d: %w", festivalID, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM festival_owners WHERE festival_id = ?", festivalID); err != nil {
+		return fmt.Errorf("failed to replace owners of festival %d: %w", festivalID, err)
+	}
+
+	if err := insertOwners(ctx, tx, festivalID, userIDs); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to replace owners of festival %d: %w", festivalID, err)
+	}
+
+	return nil
+}
+
+func insertOwners(ctx context.Context, tx *sql.Tx, festivalID int64, userIDs []string) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM festivals WHERE id = ?)", festivalID).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check festival %d: %w", festivalID, err)
+	}
+	if !exists {
+		return fmt.Errorf("festival %d: %w", festivalID, ErrNotFound)
+	}
+
+	for _, userID := range userIDs {
+		if err := tx.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM users WHERE id = ?)", userID).Scan(&exists); err != nil {
+			return fmt.Errorf("failed to check user %s: %w", userID, err)
+		}
+		if !exists {
+			return fmt.Errorf("user %s: %w", userID, ErrNotFound)
+		}
+
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO festival_owners (festival_id, user_id) VALUES (?, ?)", festivalID, userID); err != nil {
+			return fmt.Errorf("failed to add owner %s to festival %d: %w", userID, festivalID, err)
+		}
+	}
+
+	return nil
+}
+
+func expectAffected(result sql.Result, resource string, id int64) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("%s %d: %w", resource, id, ErrNotFound)
+	}
+
+	return nil
+}
+
+func (s *SQLiteDatabase) Login(ctx context.Context, email, password string) (*LoginResponse, error) {
+	var user User
+	var passwordHash string
+	err := s.db.QueryRowContext(ctx, "SELECT id, email, password_hash FROM users WHERE email = ?", email).
+		Scan(&user.ID, &user.Email, &passwordHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("authentication failed: unknown user")
+		}
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	if user.Roles, err = userRoles(ctx, s.db, user.ID); err != nil {
+		return nil, fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return createSession(ctx, s.db, user)
+}
+
+func (s *SQLiteDatabase) RefreshSession(ctx context.Context, refreshToken string) (*LoginResponse, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+	defer tx.Rollback()
+
+	var user User
+	err = tx.QueryRowContext(ctx, `
+		SELECT u.id, u.email
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.refresh_token = ? AND s.refresh_expires_at > ?`, refreshToken, time.Now().Unix()).
+		Scan(&user.ID, &user.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("unknown refresh token: %w", ErrInvalidToken)
+		}
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+
+	if user.Roles, err = userRoles(ctx, tx, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM sessions WHERE refresh_token = ?", refreshToken); err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+
+	session, err := createSession(ctx, tx, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to refresh session: %w", err)
+	}
+
+	return session, nil
+}
+
+func (s *SQLiteDatabase) Logout(ctx context.Context, token string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM sessions WHERE access_token = ?", token)
+	if err != nil {
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to read affected rows: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("unknown session: %w", ErrInvalidToken)
+	}
+
+	return nil
+}
+
+func userRoles(ctx context.Context, conn sqlQueryer, userID string) ([]string, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT role FROM user_roles WHERE user_id = ?", userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load roles of user %s: %w", userID, err)
+	}
+	defer rows.Close()
+
+	var roles []string
+	for rows.Next() {
+		var role string
+		if err := rows.Scan(&role); err != nil {
+			return nil, fmt.Errorf("failed to scan role: %w", err)
+		}
+		roles = append(roles, role)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to load roles of user %s: %w", userID, err)
+	}
+
+	return normalizeRoles(roles), nil
+}
+
+func createSession(ctx context.Context, conn sqlExecer, user User) (*LoginResponse, error) {
+	accessToken, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := generateToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+
+	_, err = conn.ExecContext(ctx, "INSERT INTO sessions (access_token, refresh_token, user_id, expires_at, refresh_expires_at) VALUES (?, ?, ?, ?, ?)",
+		accessToken, refreshToken, user.ID, now.Add(SessionDuration).Unix(), now.Add(RefreshTokenDuration).Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	return &LoginResponse{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		User:         user,
+	}, nil
+}
+
+func (s *SQLiteDatabase) VerifyToken(ctx context.Context, token string) (*User, error) {
+	var user User
+	err := s.db.QueryRowContext(ctx, `
+		SELECT u.id, u.email
+		FROM sessions s
+		JOIN users u ON u.id = s.user_id
+		WHERE s.access_token = ? AND s.expires_at > ?`, token, time.Now().Unix()).
+		Scan(&user.ID, &user.Email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("unknown or expired session: %w", ErrInvalidToken)
+		}
+		return nil, fmt.Errorf("failed to verify token: %w", err)
+	}
+
+	if user.Roles, err = userRoles(ctx, s.db, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to verify token: %w", err)
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,810 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestSQLiteDatabase(t *testing.T) *SQLiteDatabase {
+	t.Helper()
+
+	db, err := NewSQLiteDatabase(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("Failed to open sqlite database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func seedSQLiteDatabase(t *testing.T, db *SQLiteDatabase) {
+	t.Helper()
+
+	statements := []string{
+		`INSERT INTO festivals (id, name, start_date, end_date, city, region, latitude, longitude)
+		 VALUES (1, 'Lille Beer Fest', '2025-10-01', '2025-10-03', 'Lille', 'Hauts-de-France', 50.63, 3.06)`,
+		`INSERT INTO festivals (id, name, start_date, end_date, city, region)
+		 VALUES (2, 'Rennes Craft', '2025-11-01', '2025-11-02', 'Rennes', 'Bretagne')`,
+		`INSERT INTO breweries (id, name, city) VALUES (1, 'Brasserie du Nord', 'Lille')`,
+		`INSERT INTO breweries (id, name, city) VALUES (2, 'Brasserie de Bretagne', 'Rennes')`,
+		`INSERT INTO festivals_breweries (festival_id, brewery_id) VALUES (1, 1), (1, 2), (2, 2)`,
+	}
+	for _, statement := range statements {
+		if _, err := db.db.Exec(statement); err != nil {
+			t.Fatalf("Failed to seed sqlite database: %v", err)
+		}
+	}
+}
+
+func TestNewSQLiteDatabase(t *testing.T) {
+	t.Run("returns error when path is empty", func(t *testing.T) {
+		_, err := NewSQLiteDatabase("")
+
+		if err == nil {
+			t.Error("Expected error when path is empty, got nil")
+		}
+	})
+
+	t.Run("migrates databases created before schema versioning", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.db")
+
+		legacy, err := sql.Open("sqlite", path)
+		if err != nil {
+			t.Fatalf("Failed to open legacy database: %v", err)
+		}
+		if _, err := legacy.Exec(sqliteSchema); err != nil {
+			t.Fatalf("Failed to create legacy schema: %v", err)
+		}
+		legacy.Exec(`INSERT INTO festivals (name, start_date, end_date) VALUES ('Legacy', '2025-10-01', '2025-10-02')`)
+		legacy.Exec(`INSERT INTO users (id, email, password_hash) VALUES ('user-1', 'legacy@example.com', '')`)
+		legacy.Close()
+
+		db, err := NewSQLiteDatabase(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		defer db.Close()
+
+		festivals, err := db.GetFestivals(context.Background())
+		if err != nil || len(festivals) != 1 || festivals[0].Cancelled {
+			t.Errorf("Expected legacy festival to survive migration, got %+v (%v)", festivals, err)
+		}
+
+		roles, err := userRoles(context.Background(), db.db, "user-1")
+		if err != nil || !slices.Equal(roles, []string{RoleAdmin}) {
+			t.Errorf("Expected existing users to become admins, got %v (%v)", roles, err)
+		}
+	})
+
+	t.Run("can be reopened on an existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "test.db")
+
+		first, err := NewSQLiteDatabase(path)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		first.Close()
+
+		second, err := NewSQLiteDatabase(path)
+		if err != nil {
+			t.Fatalf("Expected no error on reopen, got %v", err)
+		}
+		second.Close()
+	})
+}
+
+func TestSQLiteDatabaseFestivals(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns festivals with brewery counts", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		festivals, err := db.GetFestivals(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(festivals) != 2 {
+			t.Fatalf("Expected 2 festivals, got %d", len(festivals))
+		}
+
+		if festivals[0].BreweryCount != 2 {
+			t.Errorf("Expected 2 breweries for first festival, got %d", festivals[0].BreweryCount)
+		}
+
+		if festivals[1].BreweryCount != 1 {
+			t.Errorf("Expected 1 brewery for second festival, got %d", festivals[1].BreweryCount)
+		}
+
+		if festivals[0].Location.Latitude != 50.63 {
+			t.Errorf("Expected latitude 50.63, got %f", festivals[0].Location.Latitude)
+		}
+	})
+
+	t.Run("returns empty list when there are no festivals", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		festivals, err := db.GetFestivals(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if festivals == nil || len(festivals) != 0 {
+			t.Errorf("Expected empty festival list, got %v", festivals)
+		}
+	})
+
+	t.Run("returns a single festival with its brewery count", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		festival, err := db.GetFestival(ctx, 1)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if festival.Name != "Lille Beer Fest" || festival.BreweryCount != 2 {
+			t.Errorf("Expected Lille Beer Fest with 2 breweries, got %+v", festival)
+		}
+	})
+
+	t.Run("returns ErrNotFound for unknown festival", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		_, err := db.GetFestival(ctx, 999)
+
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("creates festival", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		created, err := db.CreateFestival(ctx, &FestivalDB{
+			Name:      "New Festival",
+			StartDate: "2025-12-01",
+			EndDate:   "2025-12-02",
+			City:      "Paris",
+		})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if created.ID == 0 {
+			t.Error("Expected created festival to have an ID")
+		}
+
+		festivals, _ := db.GetFestivals(ctx)
+		if len(festivals) != 1 || festivals[0].Name != "New Festival" {
+			t.Errorf("Expected created festival to be listed, got %v", festivals)
+		}
+	})
+}
+
+func TestSQLiteDatabaseListing(t *testing.T) {
+	ctx := context.Background()
+	db := newTestSQLiteDatabase(t)
+	seedSQLiteDatabase(t, db)
+
+	t.Run("filters festivals and reports the total", func(t *testing.T) {
+		festivals, total, err := db.ListFestivals(ctx, FestivalQuery{Region: "Bretagne"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if total != 1 || len(festivals) != 1 || festivals[0].Name != "Rennes Craft" || festivals[0].BreweryCount != 1 {
+			t.Errorf("Expected Rennes Craft only, got %d %+v", total, festivals)
+		}
+	})
+
+	t.Run("filters festivals by date range", func(t *testing.T) {
+		festivals, _, _ := db.ListFestivals(ctx, FestivalQuery{From: "2025-10-02", To: "2025-10-31"})
+
+		if len(festivals) != 1 || festivals[0].ID != 1 {
+			t.Errorf("Expected festival overlapping the range, got %+v", festivals)
+		}
+	})
+
+	t.Run("filters festivals inside a bounding box", func(t *testing.T) {
+		festivals, total, _ := db.ListFestivals(ctx, FestivalQuery{Bounds: BoundingBox{West: 2, South: 50, East: 4, North: 51}})
+
+		if total != 1 || len(festivals) != 1 || festivals[0].Name != "Lille Beer Fest" {
+			t.Errorf("Expected Lille Beer Fest only, got %d %+v", total, festivals)
+		}
+	})
+
+	t.Run("sorts and paginates festivals", func(t *testing.T) {
+		festivals, total, _ := db.ListFestivals(ctx, FestivalQuery{Sort: "start_date", Desc: true, Limit: 1})
+
+		if total != 2 || len(festivals) != 1 || festivals[0].ID != 2 {
+			t.Errorf("Expected latest festival first with total 2, got %d %+v", total, festivals)
+		}
+
+		festivals, _, _ = db.ListFestivals(ctx, FestivalQuery{Sort: "start_date", Desc: true, Limit: 1, Offset: 1})
+		if len(festivals) != 1 || festivals[0].ID != 1 {
+			t.Errorf("Expected second page to hold festival 1, got %+v", festivals)
+		}
+	})
+
+	t.Run("splits upcoming and past festivals", func(t *testing.T) {
+		_, upcoming, _ := db.ListFestivals(ctx, FestivalQuery{Upcoming: true})
+		_, past, _ := db.ListFestivals(ctx, FestivalQuery{Past: true})
+
+		if upcoming+past != 2 {
+			t.Errorf("Expected upcoming and past to cover both festivals, got %d and %d", upcoming, past)
+		}
+	})
+
+	t.Run("filters and sorts breweries", func(t *testing.T) {
+		breweries, total, err := db.ListBreweries(ctx, BreweryQuery{Sort: "name"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if total != 2 || breweries[0].Name != "Brasserie de Bretagne" || breweries[0].FestivalCount != 2 {
+			t.Errorf("Expected breweries sorted by name with counts, got %+v", breweries)
+		}
+
+		breweries, total, _ = db.ListBreweries(ctx, BreweryQuery{City: "Lille"})
+		if total != 1 || len(breweries) != 1 || breweries[0].ID != 1 {
+			t.Errorf("Expected Lille brewery only, got %d %+v", total, breweries)
+		}
+	})
+}
+
+func TestSQLiteDatabaseFestivalWrites(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("updates festival", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		_, err := db.UpdateFestival(ctx, 1, &FestivalDB{Name: "Renamed", StartDate: "2025-10-01", EndDate: "2025-10-04", Cancelled: true})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, 1)
+		if festival.Name != "Renamed" || !festival.Cancelled || festival.BreweryCount != 2 {
+			t.Errorf("Expected renamed cancelled festival keeping its breweries, got %+v", festival)
+		}
+	})
+
+	t.Run("records creation and update timestamps", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		created, err := db.CreateFestival(ctx, &FestivalDB{Name: "New Fest", StartDate: "2025-12-01", EndDate: "2025-12-02", CreatedAt: "2000-01-01T00:00:00Z"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, created.ID)
+		if festival.CreatedAt.Year() < 2025 || !festival.UpdatedAt.Equal(festival.CreatedAt) {
+			t.Errorf("Expected store-set timestamps, got %v %v", festival.CreatedAt, festival.UpdatedAt)
+		}
+
+		updated, err := db.UpdateFestival(ctx, created.ID, &FestivalDB{Name: "Renamed", StartDate: "2025-12-01", EndDate: "2025-12-02"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if updated.CreatedAt != created.CreatedAt || updated.UpdatedAt == "" {
+			t.Errorf("Expected creation time kept and update time set, got %+v", updated)
+		}
+	})
+
+	t.Run("returns ErrNotFound when updating unknown festival", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		_, err := db.UpdateFestival(ctx, 999, &FestivalDB{Name: "Renamed", StartDate: "2025-10-01", EndDate: "2025-10-04"})
+
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("deletes festival and its lineup", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		if err := db.DeleteFestival(ctx, 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if _, err := db.GetFestival(ctx, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected deleted festival to be gone, got %v", err)
+		}
+
+		brewery, _ := db.GetBrewery(ctx, 1)
+		if brewery.FestivalCount != 0 {
+			t.Errorf("Expected lineup links to be removed, got %d festivals", brewery.FestivalCount)
+		}
+	})
+
+	t.Run("returns ErrNotFound when deleting unknown festival", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		if err := db.DeleteFestival(ctx, 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestSQLiteDatabaseBreweries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("returns breweries with festival counts", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		breweries, err := db.GetBreweries(ctx)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(breweries) != 2 {
+			t.Fatalf("Expected 2 breweries, got %d", len(breweries))
+		}
+
+		if breweries[0].FestivalCount != 1 {
+			t.Errorf("Expected 1 festival for first brewery, got %d", breweries[0].FestivalCount)
+		}
+
+		if breweries[1].FestivalCount != 2 {
+			t.Errorf("Expected 2 festivals for second brewery, got %d", breweries[1].FestivalCount)
+		}
+	})
+
+	t.Run("returns breweries for a festival", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		breweries, err := db.GetBreweriesByFestival(ctx, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(breweries) != 1 || breweries[0].Name != "Brasserie de Bretagne" {
+			t.Errorf("Expected Brasserie de Bretagne, got %v", breweries)
+		}
+	})
+
+	t.Run("returns a single brewery with its festival count", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		brewery, err := db.GetBrewery(ctx, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if brewery.Name != "Brasserie de Bretagne" || brewery.FestivalCount != 2 {
+			t.Errorf("Expected Brasserie de Bretagne with 2 festivals, got %+v", brewery)
+		}
+	})
+
+	t.Run("returns ErrNotFound for unknown brewery", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		_, err := db.GetBrewery(ctx, 999)
+
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("returns festivals attended by a brewery", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		festivals, err := db.GetFestivalsByBrewery(ctx, 2)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(festivals) != 2 || festivals[0].BreweryCount != 2 {
+			t.Errorf("Expected 2 festivals with brewery counts, got %+v", festivals)
+		}
+	})
+
+	t.Run("returns empty list for unknown festival", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		breweries, err := db.GetBreweriesByFestival(ctx, 999)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(breweries) != 0 {
+			t.Errorf("Expected no breweries, got %d", len(breweries))
+		}
+	})
+}
+
+func TestSQLiteDatabaseBreweryWrites(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("creates and updates brewery", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		created, err := db.CreateBrewery(ctx, &BreweryDB{Name: "New Brewery", City: "Lyon"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		_, err = db.UpdateBrewery(ctx, created.ID, &BreweryDB{Name: "Renamed Brewery", City: "Lyon", Website: "https://renamed.example.com"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		brewery, _ := db.GetBrewery(ctx, created.ID)
+		if brewery.Name != "Renamed Brewery" || brewery.Website != "https://renamed.example.com" {
+			t.Errorf("Expected renamed brewery, got %+v", brewery)
+		}
+	})
+
+	t.Run("returns ErrNotFound when updating unknown brewery", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		_, err := db.UpdateBrewery(ctx, 999, &BreweryDB{Name: "Renamed Brewery", City: "Lyon"})
+
+		if !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("refuses to delete linked brewery without cascade", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		if err := db.DeleteBrewery(ctx, 1, false); !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+
+		if _, err := db.GetBrewery(ctx, 1); err != nil {
+			t.Errorf("Expected brewery to be kept, got %v", err)
+		}
+	})
+
+	t.Run("deletes linked brewery with cascade", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		if err := db.DeleteBrewery(ctx, 2, true); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, 2)
+		if festival.BreweryCount != 0 {
+			t.Errorf("Expected lineup links to be removed, got %d breweries", festival.BreweryCount)
+		}
+	})
+
+	t.Run("returns ErrNotFound when deleting unknown brewery", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+
+		if err := db.DeleteBrewery(ctx, 999, false); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+}
+
+func TestSQLiteDatabaseLineups(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("adds breweries and updates counts", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		if err := db.AddBreweriesToFestival(ctx, 2, []int64{1}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, 2)
+		brewery, _ := db.GetBrewery(ctx, 1)
+		if festival.BreweryCount != 2 || brewery.FestivalCount != 2 {
+			t.Errorf("Expected counts of 2, got festival %d and brewery %d", festival.BreweryCount, brewery.FestivalCount)
+		}
+	})
+
+	t.Run("rejects duplicate links without partial writes", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+		extra, _ := db.CreateBrewery(ctx, &BreweryDB{Name: "Extra", City: "Lyon"})
+
+		err := db.AddBreweriesToFestival(ctx, 2, []int64{extra.ID, 2})
+
+		if !errors.Is(err, ErrConflict) {
+			t.Errorf("Expected ErrConflict, got %v", err)
+		}
+
+		festival, _ := db.GetFestival(ctx, 2)
+		if festival.BreweryCount != 1 {
+			t.Errorf("Expected lineup to be untouched, got %d breweries", festival.BreweryCount)
+		}
+	})
+
+	t.Run("returns ErrNotFound for unknown festival or brewery", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		if err := db.AddBreweriesToFestival(ctx, 999, []int64{1}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for unknown festival, got %v", err)
+		}
+
+		if err := db.AddBreweriesToFestival(ctx, 2, []int64{999}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for unknown brewery, got %v", err)
+		}
+	})
+
+	t.Run("replaces lineup atomically", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		if err := db.ReplaceFestivalBreweries(ctx, 1, []int64{2, 999}); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+
+		breweries, _ := db.GetBreweriesByFestival(ctx, 1)
+		if len(breweries) != 2 {
+			t.Errorf("Expected failed replacement to keep the lineup, got %d breweries", len(breweries))
+		}
+
+		if err := db.ReplaceFestivalBreweries(ctx, 1, []int64{2}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		breweries, _ = db.GetBreweriesByFestival(ctx, 1)
+		if len(breweries) != 1 || breweries[0].ID != 2 {
+			t.Errorf("Expected lineup with brewery 2, got %+v", breweries)
+		}
+	})
+
+	t.Run("removes brewery from lineup", func(t *testing.T) {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+
+		if err := db.RemoveBreweryFromFestival(ctx, 1, 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if err := db.RemoveBreweryFromFestival(ctx, 1, 1); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound on second removal, got %v", err)
+		}
+	})
+}
+
+func TestSQLiteDatabaseOwners(t *testing.T) {
+	ctx := context.Background()
+
+	newOwnersDatabase := func(t *testing.T) *SQLiteDatabase {
+		db := newTestSQLiteDatabase(t)
+		seedSQLiteDatabase(t, db)
+		if _, err := db.db.Exec("INSERT INTO users (id, email, password_hash) VALUES ('user-123', 'a@example.com', ''), ('user-456', 'b@example.com', '')"); err != nil {
+			t.Fatalf("Failed to seed users: %v", err)
+		}
+		return db
+	}
+
+	t.Run("makes the creator the first owner", func(t *testing.T) {
+		db := newOwnersDatabase(t)
+
+		created, err := db.CreateFestival(ctx, &FestivalDB{Name: "Owned", StartDate: "2025-12-01", EndDate: "2025-12-02", CreatedBy: "user-123"})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		owners, err := db.GetFestivalOwners(ctx, created.ID)
+		if err != nil || !slices.Equal(owners, []string{"user-123"}) {
+			t.Errorf("Expected owner user-123, got %v, %v", owners, err)
+		}
+
+		updated, err := db.UpdateFestival(ctx, created.ID, &FestivalDB{Name: "Renamed", StartDate: "2025-12-01", EndDate: "2025-12-02", CreatedBy: "user-456"})
+		if err != nil || updated.CreatedBy != "user-123" {
+			t.Errorf("Expected updates to keep the creator, got %+v, %v", updated, err)
+		}
+
+		festival, _ := db.GetFestival(ctx, created.ID)
+		if festival.CreatedBy != "user-123" {
+			t.Errorf("Expected festival created by user-123, got %q", festival.CreatedBy)
+		}
+	})
+
+	t.Run("does not create festivals for unknown users", func(t *testing.T) {
+		db := newOwnersDatabase(t)
+
+		if _, err := db.CreateFestival(ctx, &FestivalDB{Name: "Orphan", StartDate: "2025-12-01", EndDate: "2025-12-02", CreatedBy: "user-999"}); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+
+		if festivals, _ := db.GetFestivals(ctx); len(festivals) != 2 {
+			t.Errorf("Expected the festival to be rolled back, got %d festivals", len(festivals))
+		}
+	})
+
+	t.Run("returns no owners for festivals created before ownership", func(t *testing.T) {
+		db := newOwnersDatabase(t)
+
+		owners, err := db.GetFestivalOwners(ctx, 1)
+		if err != nil || owners == nil || len(owners) != 0 {
+			t.Errorf("Expected no owners, got %v, %v", owners, err)
+		}
+
+		if _, err := db.GetFestivalOwners(ctx, 999); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("adds and replaces owners", func(t *testing.T) {
+		db := newOwnersDatabase(t)
+
+		if err := db.AddFestivalOwners(ctx, 1, []string{"user-123", "user-999"}); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Expected ErrNotFound for unknown user, got %v", err)
+		}
+		if owners, _ := db.GetFestivalOwners(ctx, 1); len(owners) != 0 {
+			t.Errorf("Expected no partial writes, got %v", owners)
+		}
+
+		if err := db.AddFestivalOwners(ctx, 1, []string{"user-456", "user-123"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if err := db.AddFestivalOwners(ctx, 1, []string{"user-123"}); err != nil {
+			t.Fatalf("Expected existing owners to be ignored, got %v", err)
+		}
+		if owners, _ := db.GetFestivalOwners(ctx, 1); !slices.Equal(owners, []string{"user-123", "user-456"}) {
+			t.Errorf("Expected owners user-123 and user-456, got %v", owners)
+		}
+
+		if err := db.ReplaceFestivalOwners(ctx, 1, []string{"user-456"}); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if owners, _ := db.GetFestivalOwners(ctx, 1); !slices.Equal(owners, []string{"user-456"}) {
+			t.Errorf("Expected owner user-456, got %v", owners)
+		}
+
+		festivals, _ := db.GetFestivalsByOwner(ctx, "user-456")
+		if len(festivals) != 1 || festivals[0].ID != 1 || festivals[0].BreweryCount != 2 {
+			t.Errorf("Expected festival 1 with two breweries for user-456, got %+v", festivals)
+		}
+	})
+
+	t.Run("drops owners with the festival", func(t *testing.T) {
+		db := newOwnersDatabase(t)
+		db.AddFestivalOwners(ctx, 1, []string{"user-123"})
+
+		if err := db.DeleteFestival(ctx, 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if festivals, _ := db.GetFestivalsByOwner(ctx, "user-123"); len(festivals) != 0 {
+			t.Errorf("Expected no festivals for user-123, got %+v", festivals)
+		}
+	})
+}
+
+func TestSQLiteDatabaseAuth(t *testing.T) {
+	ctx := context.Background()
+
+	db := newTestSQLiteDatabase(t)
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("Failed to hash password: %v", err)
+	}
+	if _, err := db.db.Exec("INSERT INTO users (id, email, password_hash) VALUES (?, ?, ?)",
+		"user-123", "test@example.com", string(hash)); err != nil {
+		t.Fatalf("Failed to seed user: %v", err)
+	}
+	if _, err := db.db.Exec("INSERT INTO user_roles (user_id, role) VALUES ('user-123', 'contributor'), ('user-123', 'unknown')"); err != nil {
+		t.Fatalf("Failed to seed roles: %v", err)
+	}
+
+	t.Run("logs in and verifies the issued token", func(t *testing.T) {
+		loginResp, err := db.Login(ctx, "test@example.com", "password123")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if loginResp.AccessToken == "" || loginResp.RefreshToken == "" {
+			t.Error("Expected access and refresh tokens")
+		}
+
+		user, err := db.VerifyToken(ctx, loginResp.AccessToken)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if user.ID != "user-123" || user.Email != "test@example.com" {
+			t.Errorf("Expected user-123, got %v", user)
+		}
+
+		if !slices.Equal(loginResp.User.Roles, []string{RoleContributor}) || !slices.Equal(user.Roles, []string{RoleContributor}) {
+			t.Errorf("Expected the contributor role, got %v and %v", loginResp.User.Roles, user.Roles)
+		}
+	})
+
+	t.Run("rejects wrong password", func(t *testing.T) {
+		_, err := db.Login(ctx, "test@example.com", "wrongpassword")
+		if err == nil {
+			t.Error("Expected error for wrong password, got nil")
+		}
+	})
+
+	t.Run("rejects unknown user", func(t *testing.T) {
+		_, err := db.Login(ctx, "nobody@example.com", "password123")
+		if err == nil {
+			t.Error("Expected error for unknown user, got nil")
+		}
+	})
+
+	t.Run("rejects unknown token", func(t *testing.T) {
+		_, err := db.VerifyToken(ctx, "not-a-token")
+		if !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken for unknown token, got %v", err)
+		}
+	})
+
+	t.Run("rotates tokens on refresh", func(t *testing.T) {
+		loginResp, err := db.Login(ctx, "test@example.com", "password123")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		refreshed, err := db.RefreshSession(ctx, loginResp.RefreshToken)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if refreshed.AccessToken == loginResp.AccessToken || refreshed.RefreshToken == loginResp.RefreshToken {
+			t.Error("Expected a new token pair")
+		}
+
+		if refreshed.User.ID != "user-123" {
+			t.Errorf("Expected user-123, got %v", refreshed.User)
+		}
+
+		if _, err := db.VerifyToken(ctx, refreshed.AccessToken); err != nil {
+			t.Errorf("Expected refreshed token to verify, got %v", err)
+		}
+
+		if _, err := db.VerifyToken(ctx, loginResp.AccessToken); err == nil {
+			t.Error("Expected the old access token to be revoked")
+		}
+
+		if _, err := db.RefreshSession(ctx, loginResp.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken when reusing a refresh token, got %v", err)
+		}
+	})
+
+	t.Run("revokes the session on logout", func(t *testing.T) {
+		loginResp, err := db.Login(ctx, "test@example.com", "password123")
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if err := db.Logout(ctx, loginResp.AccessToken); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if _, err := db.VerifyToken(ctx, loginResp.AccessToken); err == nil {
+			t.Error("Expected the access token to be revoked")
+		}
+
+		if _, err := db.RefreshSession(ctx, loginResp.RefreshToken); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken for a revoked refresh token, got %v", err)
+		}
+
+		if err := db.Logout(ctx, loginResp.AccessToken); !errors.Is(err, ErrInvalidToken) {
+			t.Errorf("Expected ErrInvalidToken on second logout, got %v", err)
+		}
+	})
+}
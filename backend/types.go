@@ -1,6 +1,10 @@
 package main
 
-import "time"
+import (
+	"context"
+	"encoding/xml"
+	"time"
+)
 
 type Location struct {
 	Latitude  float64 `json:"latitude"`
@@ -19,6 +23,15 @@ type Festival struct {
 	Image        string    `json:"image"`
 	Website      string    `json:"website"`
 	BreweryCount int       `json:"breweryCount"`
+	Cancelled    bool      `json:"cancelled"`
+	CreatedBy    string    `json:"createdBy,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+type NearbyFestival struct {
+	Festival
+	DistanceKm float64 `json:"distanceKm"`
 }
 
 type FestivalDB struct {
@@ -33,6 +46,10 @@ type FestivalDB struct {
 	Longitude   float64 `json:"longitude"`
 	Image       string  `json:"image"`
 	Website     string  `json:"website"`
+	Cancelled   bool    `json:"cancelled"`
+	CreatedBy   string  `json:"created_by,omitempty"`
+	CreatedAt   string  `json:"created_at,omitempty"`
+	UpdatedAt   string  `json:"updated_at,omitempty"`
 }
 
 type FestivalBrewery struct {
@@ -40,6 +57,177 @@ type FestivalBrewery struct {
 	BreweryID  int64 `json:"brewery_id"`
 }
 
+type FestivalOwner struct {
+	FestivalID int64  `json:"festival_id"`
+	UserID     string `json:"user_id"`
+}
+
+type FestivalOwners struct {
+	FestivalID int64    `json:"festivalId"`
+	Owners     []string `json:"owners"`
+}
+
+type BoundingBox struct {
+	South float64 `json:"south"`
+	West  float64 `json:"west"`
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+}
+
+type FestivalCluster struct {
+	Location Location    `json:"location"`
+	Count    int         `json:"count"`
+	Bounds   BoundingBox `json:"bounds"`
+}
+
+type FestivalMap struct {
+	Festivals []Festival        `json:"festivals"`
+	Clusters  []FestivalCluster `json:"clusters"`
+}
+
+type GeoJSONGeometry struct {
+	Type        string    `json:"type"`
+	Coordinates []float64 `json:"coordinates"`
+}
+
+type FestivalProperties struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	StartDate    string `json:"startDate"`
+	EndDate      string `json:"endDate"`
+	City         string `json:"city"`
+	Region       string `json:"region"`
+	Image        string `json:"image"`
+	Website      string `json:"website"`
+	BreweryCount int    `json:"breweryCount"`
+	Cancelled    bool   `json:"cancelled"`
+}
+
+type GeoJSONFeature struct {
+	Type       string             `json:"type"`
+	Geometry   GeoJSONGeometry    `json:"geometry"`
+	Properties FestivalProperties `json:"properties"`
+}
+
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+type AtomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+}
+
+type AtomPerson struct {
+	Name string `xml:"name"`
+}
+
+type AtomEntry struct {
+	ID        string   `xml:"id"`
+	Title     string   `xml:"title"`
+	Link      AtomLink `xml:"link"`
+	Published string   `xml:"published"`
+	Updated   string   `xml:"updated"`
+	Summary   string   `xml:"summary,omitempty"`
+}
+
+type AtomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Link    AtomLink    `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  AtomPerson  `xml:"author"`
+	Entries []AtomEntry `xml:"entry"`
+}
+
+type RSSGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+type RSSItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link"`
+	GUID        RSSGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+	Description string  `xml:"description,omitempty"`
+}
+
+type RSSChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []RSSItem `xml:"item"`
+}
+
+type RSSFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel RSSChannel `xml:"channel"`
+}
+
+type SearchHit struct {
+	Type     string    `json:"type"`
+	ID       int64     `json:"id"`
+	Name     string    `json:"name"`
+	Score    float64   `json:"score"`
+	Snippet  string    `json:"snippet"`
+	Festival *Festival `json:"festival,omitempty"`
+	Brewery  *Brewery  `json:"brewery,omitempty"`
+}
+
+type SearchQuery struct {
+	Text   string
+	Type   string
+	Limit  int
+	Offset int
+}
+
+type Suggestion struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+type SuggestQuery struct {
+	Prefix   string
+	Category string
+	Limit    int
+}
+
+type FestivalQuery struct {
+	Region   string
+	City     string
+	From     string
+	To       string
+	Upcoming bool
+	Past     bool
+	Bounds   BoundingBox
+	Sort     string
+	Desc     bool
+	Limit    int
+	Offset   int
+}
+
+type BreweryQuery struct {
+	City   string
+	Sort   string
+	Desc   bool
+	Limit  int
+	Offset int
+}
+
+type LineupRequest struct {
+	BreweryIDs []int64 `json:"brewery_ids"`
+}
+
+type OwnersRequest struct {
+	UserIDs []string `json:"user_ids"`
+}
+
 type Brewery struct {
 	ID          int64  `json:"id"`
 	Name        string `json:"name"`
@@ -50,8 +238,14 @@ type Brewery struct {
 	FestivalCount int `json:"festivalCount"`
 }
 
+type BreweryDetail struct {
+	Brewery
+	UpcomingFestivals []Festival `json:"upcomingFestivals"`
+	PastFestivals     []Festival `json:"pastFestivals"`
+}
+
 type BreweryDB struct {
-	ID          int64  `json:"id"`
+	ID          int64  `json:"id,omitempty"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 	City        string `json:"city"`
@@ -59,11 +253,38 @@ type BreweryDB struct {
 	Logo        string `json:"logo"`
 }
 
+type SeedUser struct {
+	ID       string   `json:"id"`
+	Email    string   `json:"email"`
+	Password string   `json:"password"`
+	Roles    []string `json:"roles"`
+}
+
+type Seed struct {
+	Festivals          []FestivalDB      `json:"festivals"`
+	Breweries          []BreweryDB       `json:"breweries"`
+	FestivalsBreweries []FestivalBrewery `json:"festivals_breweries"`
+	FestivalOwners     []FestivalOwner   `json:"festival_owners"`
+	Users              []SeedUser        `json:"users"`
+}
+
 type Config struct {
-	Port           string
-	AllowedOrigins string
-	SupabaseURL    string
-	SupabaseKey    string
+	Port              string
+	AllowedOrigins    string
+	SupabaseURL       string
+	SupabaseKey       string
+	DatabaseDriver    string
+	SQLitePath        string
+	SeedPath          string
+	CacheTTL          time.Duration
+	RequestTimeout    time.Duration
+	FrontendURL       string
+	JWTSecret         string
+	JWKSSource        string
+	JWTAudience       string
+	JWTIssuer         string
+	JWTRemoteFallback bool
+	RolesSource       string
 }
 
 type LoginRequest struct {
@@ -71,6 +292,10 @@ type LoginRequest struct {
 	Password string `json:"password"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
 type LoginResponse struct {
 	AccessToken  string `json:"accessToken"`
 	RefreshToken string `json:"refreshToken"`
@@ -78,21 +303,130 @@ type LoginResponse struct {
 }
 
 type User struct {
-	ID    string `json:"id"`
-	Email string `json:"email"`
+	ID    string   `json:"id"`
+	Email string   `json:"email"`
+	Roles []string `json:"roles"`
 }
 
 type VerifyResponse struct {
-	Valid bool   `json:"valid"`
-	User  *User  `json:"user,omitempty"`
-	Error string `json:"error,omitempty"`
+	Valid       bool     `json:"valid"`
+	User        *User    `json:"user"`
+	Permissions []string `json:"permissions"`
+}
+
+type Problem struct {
+	Type      string       `json:"type"`
+	Title     string       `json:"title"`
+	Status    int          `json:"status"`
+	Detail    string       `json:"detail,omitempty"`
+	Instance  string       `json:"instance,omitempty"`
+	Code      string       `json:"code"`
+	RequestID string       `json:"requestId,omitempty"`
+	Errors    []FieldError `json:"errors,omitempty"`
+}
+
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type ValidationError struct {
+	Fields []FieldError
+}
+
+type OpenAPIDocument struct {
+	OpenAPI    string                                  `json:"openapi"`
+	Info       OpenAPIInfo                             `json:"info"`
+	Servers    []OpenAPIServer                         `json:"servers"`
+	Paths      map[string]map[string]*OpenAPIOperation `json:"paths"`
+	Components OpenAPIComponents                       `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	URL string `json:"url"`
+}
+
+type OpenAPIComponents struct {
+	Schemas         map[string]*OpenAPISchema        `json:"schemas"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+type OpenAPISecurityScheme struct {
+	Type   string `json:"type"`
+	Scheme string `json:"scheme"`
+}
+
+type OpenAPIOperation struct {
+	Summary     string                     `json:"summary"`
+	Description string                     `json:"description,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+	Security    []map[string][]string      `json:"security,omitempty"`
+}
+
+type OpenAPIParameter struct {
+	Name     string         `json:"name"`
+	In       string         `json:"in"`
+	Required bool           `json:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIMediaType struct {
+	Schema *OpenAPISchema `json:"schema"`
+}
+
+type OpenAPIResponse struct {
+	Description string                      `json:"description"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Enum                 []string                  `json:"enum,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty"`
+	AdditionalProperties *OpenAPISchema            `json:"additionalProperties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
 }
 
 type DatabaseInterface interface {
-	Login(email, password string) (*LoginResponse, error)
-	VerifyToken(token string) (*User, error)
-	GetFestivals() ([]Festival, error)
-	GetBreweries() ([]Brewery, error)
-	GetBreweriesByFestival(festivalID string) ([]Brewery, error)
-	CreateFestival(festival *FestivalDB) (*FestivalDB, error)
+	Login(ctx context.Context, email, password string) (*LoginResponse, error)
+	VerifyToken(ctx context.Context, token string) (*User, error)
+	RefreshSession(ctx context.Context, refreshToken string) (*LoginResponse, error)
+	Logout(ctx context.Context, token string) error
+	GetFestivals(ctx context.Context) ([]Festival, error)
+	ListFestivals(ctx context.Context, query FestivalQuery) ([]Festival, int, error)
+	GetFestival(ctx context.Context, id int64) (*Festival, error)
+	GetBreweries(ctx context.Context) ([]Brewery, error)
+	ListBreweries(ctx context.Context, query BreweryQuery) ([]Brewery, int, error)
+	GetBreweriesByFestival(ctx context.Context, festivalID int64) ([]Brewery, error)
+	GetBrewery(ctx context.Context, id int64) (*Brewery, error)
+	GetFestivalsByBrewery(ctx context.Context, breweryID int64) ([]Festival, error)
+	CreateFestival(ctx context.Context, festival *FestivalDB) (*FestivalDB, error)
+	UpdateFestival(ctx context.Context, id int64, festival *FestivalDB) (*FestivalDB, error)
+	DeleteFestival(ctx context.Context, id int64) error
+	CreateBrewery(ctx context.Context, brewery *BreweryDB) (*BreweryDB, error)
+	UpdateBrewery(ctx context.Context, id int64, brewery *BreweryDB) (*BreweryDB, error)
+	DeleteBrewery(ctx context.Context, id int64, cascade bool) error
+	AddBreweriesToFestival(ctx context.Context, festivalID int64, breweryIDs []int64) error
+	RemoveBreweryFromFestival(ctx context.Context, festivalID, breweryID int64) error
+	ReplaceFestivalBreweries(ctx context.Context, festivalID int64, breweryIDs []int64) error
+	GetFestivalOwners(ctx context.Context, festivalID int64) ([]string, error)
+	GetFestivalsByOwner(ctx context.Context, userID string) ([]Festival, error)
+	AddFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error
+	ReplaceFestivalOwners(ctx context.Context, festivalID int64, userIDs []string) error
 }
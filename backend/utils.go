@@ -1,9 +1,209 @@
 package main
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"sort"
 	"time"
 )
 
 func ConvertTime(date string) (time.Time, error) {
 	return time.Parse(DefaultTimeFormat, date)
 }
+
+func splitFestivalsByDate(festivals []Festival, now time.Time) ([]Festival, []Festival) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	upcoming := []Festival{}
+	past := []Festival{}
+
+	for _, festival := range festivals {
+		if festival.EndDate.Before(today) {
+			past = append(past, festival)
+		} else {
+			upcoming = append(upcoming, festival)
+		}
+	}
+
+	sort.Slice(upcoming, func(i, j int) bool { return upcoming[i].StartDate.Before(upcoming[j].StartDate) })
+	sort.Slice(past, func(i, j int) bool { return past[i].StartDate.After(past[j].StartDate) })
+
+	return upcoming, past
+}
+
+func timestamp(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())
+}
+
+func generateToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func isHTTPURL(value string) bool {
+	parsed, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+	return (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+func distanceKm(from, to Location) float64 {
+	lat1 := from.Latitude * math.Pi / 180
+	lat2 := to.Latitude * math.Pi / 180
+	deltaLat := lat2 - lat1
+	deltaLon := (to.Longitude - from.Longitude) * math.Pi / 180
+
+	a := math.Sin(deltaLat/2)*math.Sin(deltaLat/2) +
+		math.Cos(lat1)*math.Cos(lat2)*math.Sin(deltaLon/2)*math.Sin(deltaLon/2)
+	return 2 * EarthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
+
+func hasLocation(location Location) bool {
+	return location.Latitude != 0 || location.Longitude != 0
+}
+
+func isValidLocation(location Location) bool {
+	return location.Latitude >= -90 && location.Latitude <= 90 &&
+		location.Longitude >= -180 && location.Longitude <= 180
+}
+
+func festivalsNear(festivals []Festival, origin Location, radiusKm float64) []NearbyFestival {
+	nearby := []NearbyFestival{}
+	for _, festival := range festivals {
+		if !hasLocation(festival.Location) {
+			continue
+		}
+
+		distance := distanceKm(origin, festival.Location)
+		if distance <= radiusKm {
+			nearby = append(nearby, NearbyFestival{Festival: festival, DistanceKm: math.Round(distance*10) / 10})
+		}
+	}
+
+	sort.SliceStable(nearby, func(i, j int) bool {
+		return nearby[i].DistanceKm < nearby[j].DistanceKm
+	})
+	return nearby
+}
+
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func (b BoundingBox) Contains(location Location) bool {
+	return location.Latitude >= b.South && location.Latitude <= b.North &&
+		location.Longitude >= b.West && location.Longitude <= b.East
+}
+
+func mercatorPoint(location Location) (float64, float64) {
+	x := (location.Longitude + 180) / 360
+	sinLat := math.Sin(location.Latitude * math.Pi / 180)
+	y := 0.5 - math.Log((1+sinLat)/(1-sinLat))/(4*math.Pi)
+	return x, y
+}
+
+func clusterFestivals(festivals []Festival, zoom int) FestivalMap {
+	result := FestivalMap{Festivals: []Festival{}, Clusters: []FestivalCluster{}}
+
+	located := []Festival{}
+	for _, festival := range festivals {
+		if hasLocation(festival.Location) {
+			located = append(located, festival)
+		}
+	}
+
+	if zoom >= ClusterMaxZoom {
+		result.Festivals = located
+		return result
+	}
+
+	type cell struct{ x, y int }
+	cellSize := float64(ClusterCellPixels) / (float64(MapTileSize) * math.Pow(2, float64(zoom)))
+	cells := map[cell][]Festival{}
+	var order []cell
+	for _, festival := range located {
+		x, y := mercatorPoint(festival.Location)
+		key := cell{int(math.Floor(x / cellSize)), int(math.Floor(y / cellSize))}
+		if _, ok := cells[key]; !ok {
+			order = append(order, key)
+		}
+		cells[key] = append(cells[key], festival)
+	}
+
+	for _, key := range order {
+		members := cells[key]
+		if len(members) == 1 {
+			result.Festivals = append(result.Festivals, members[0])
+			continue
+		}
+
+		cluster := FestivalCluster{
+			Count:  len(members),
+			Bounds: BoundingBox{South: 90, West: 180, North: -90, East: -180},
+		}
+		for _, festival := range members {
+			cluster.Location.Latitude += festival.Location.Latitude / float64(len(members))
+			cluster.Location.Longitude += festival.Location.Longitude / float64(len(members))
+			cluster.Bounds.South = math.Min(cluster.Bounds.South, festival.Location.Latitude)
+			cluster.Bounds.North = math.Max(cluster.Bounds.North, festival.Location.Latitude)
+			cluster.Bounds.West = math.Min(cluster.Bounds.West, festival.Location.Longitude)
+			cluster.Bounds.East = math.Max(cluster.Bounds.East, festival.Location.Longitude)
+		}
+		result.Clusters = append(result.Clusters, cluster)
+	}
+
+	return result
+}
+
+func festivalsToGeoJSON(festivals []Festival) GeoJSONFeatureCollection {
+	collection := GeoJSONFeatureCollection{Type: "FeatureCollection", Features: []GeoJSONFeature{}}
+	for _, festival := range festivals {
+		if !hasLocation(festival.Location) || !isValidLocation(festival.Location) {
+			continue
+		}
+
+		collection.Features = append(collection.Features, GeoJSONFeature{
+			Type: "Feature",
+			Geometry: GeoJSONGeometry{
+				Type:        "Point",
+				Coordinates: []float64{festival.Location.Longitude, festival.Location.Latitude},
+			},
+			Properties: FestivalProperties{
+				ID:           festival.ID,
+				Name:         festival.Name,
+				Description:  festival.Description,
+				StartDate:    festival.StartDate.Format(DefaultTimeFormat),
+				EndDate:      festival.EndDate.Format(DefaultTimeFormat),
+				City:         festival.City,
+				Region:       festival.Region,
+				Image:        festival.Image,
+				Website:      festival.Website,
+				BreweryCount: festival.BreweryCount,
+				Cancelled:    festival.Cancelled,
+			},
+		})
+	}
+	return collection
+}
@@ -1,10 +1,34 @@
 package main
 
 import (
+	"math"
 	"testing"
 	"time"
 )
 
+func TestSplitFestivalsByDate(t *testing.T) {
+	now := time.Date(2025, 10, 2, 15, 0, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2025, 10, d, 0, 0, 0, 0, time.UTC) }
+
+	festivals := []Festival{
+		{ID: 1, StartDate: day(20), EndDate: day(21)},
+		{ID: 2, StartDate: day(1), EndDate: day(2)},
+		{ID: 3, StartDate: day(5), EndDate: day(6)},
+		{ID: 4, StartDate: day(1), EndDate: day(1)},
+		{ID: 5, StartDate: time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC), EndDate: time.Date(2025, 9, 2, 0, 0, 0, 0, time.UTC)},
+	}
+
+	upcoming, past := splitFestivalsByDate(festivals, now)
+
+	if len(upcoming) != 3 || upcoming[0].ID != 2 || upcoming[1].ID != 3 || upcoming[2].ID != 1 {
+		t.Errorf("Expected upcoming festivals 2, 3, 1 (ongoing first), got %+v", upcoming)
+	}
+
+	if len(past) != 2 || past[0].ID != 4 || past[1].ID != 5 {
+		t.Errorf("Expected past festivals 4, 5 (most recent first), got %+v", past)
+	}
+}
+
 func TestConvertTime(t *testing.T) {
 	t.Run("converts valid date string to time.Time", func(t *testing.T) {
 		dateStr := "2025-10-01"
@@ -70,3 +94,96 @@ func TestConvertTime(t *testing.T) {
 		}
 	})
 }
+
+func TestDistanceKm(t *testing.T) {
+	lille := Location{Latitude: 50.6292, Longitude: 3.0573}
+	paris := Location{Latitude: 48.8566, Longitude: 2.3522}
+
+	distance := distanceKm(lille, paris)
+
+	if math.Abs(distance-204) > 2 {
+		t.Errorf("Expected Lille to Paris to be about 204 km, got %.1f", distance)
+	}
+
+	if distanceKm(paris, paris) != 0 {
+		t.Errorf("Expected zero distance to itself, got %f", distanceKm(paris, paris))
+	}
+}
+
+func TestFestivalsNear(t *testing.T) {
+	lille := Location{Latitude: 50.6292, Longitude: 3.0573}
+	festivals := []Festival{
+		{ID: 1, Location: Location{Latitude: 48.8566, Longitude: 2.3522}},
+		{ID: 2, Location: Location{Latitude: 50.6927, Longitude: 3.1778}},
+		{ID: 3, Location: Location{}},
+		{ID: 4, Location: Location{Latitude: 43.6047, Longitude: 1.4442}},
+	}
+
+	nearby := festivalsNear(festivals, lille, 250)
+
+	if len(nearby) != 2 || nearby[0].ID != 2 || nearby[1].ID != 1 {
+		t.Fatalf("Expected festivals 2 then 1, got %+v", nearby)
+	}
+
+	if nearby[0].DistanceKm <= 0 || nearby[0].DistanceKm > 15 {
+		t.Errorf("Expected Roubaix to be within 15 km, got %.1f", nearby[0].DistanceKm)
+	}
+}
+
+func TestClusterFestivals(t *testing.T) {
+	festivals := []Festival{
+		{ID: 1, Location: Location{Latitude: 50.6292, Longitude: 3.0573}},
+		{ID: 2, Location: Location{Latitude: 50.6927, Longitude: 3.1778}},
+		{ID: 3, Location: Location{Latitude: 43.6047, Longitude: 1.4442}},
+		{ID: 4, Location: Location{}},
+	}
+
+	t.Run("groups nearby festivals at low zoom", func(t *testing.T) {
+		result := clusterFestivals(festivals, 5)
+
+		if len(result.Clusters) != 1 || len(result.Festivals) != 1 || result.Festivals[0].ID != 3 {
+			t.Fatalf("Expected one cluster and festival 3, got %+v", result)
+		}
+
+		cluster := result.Clusters[0]
+		if cluster.Count != 2 {
+			t.Errorf("Expected cluster of 2, got %d", cluster.Count)
+		}
+		if math.Abs(cluster.Location.Latitude-50.66095) > 1e-9 || math.Abs(cluster.Location.Longitude-3.11755) > 1e-9 {
+			t.Errorf("Expected centroid between Lille and Roubaix, got %+v", cluster.Location)
+		}
+		if cluster.Bounds.South != 50.6292 || cluster.Bounds.North != 50.6927 || cluster.Bounds.West != 3.0573 || cluster.Bounds.East != 3.1778 {
+			t.Errorf("Expected bounds around members, got %+v", cluster.Bounds)
+		}
+	})
+
+	t.Run("returns individual festivals at high zoom", func(t *testing.T) {
+		result := clusterFestivals(festivals, ClusterMaxZoom)
+
+		if len(result.Clusters) != 0 || len(result.Festivals) != 3 {
+			t.Errorf("Expected three located festivals and no clusters, got %+v", result)
+		}
+	})
+}
+
+func TestFestivalsToGeoJSON(t *testing.T) {
+	festivals := []Festival{
+		{ID: 1, Location: Location{Latitude: 48.8566, Longitude: 2.3522}},
+		{ID: 2, Location: Location{}},
+		{ID: 3, Location: Location{Latitude: 120, Longitude: 2.3522}},
+	}
+
+	collection := festivalsToGeoJSON(festivals)
+
+	if len(collection.Features) != 1 || collection.Features[0].Properties.ID != 1 {
+		t.Fatalf("Expected only festival 1, got %+v", collection.Features)
+	}
+
+	if coordinates := collection.Features[0].Geometry.Coordinates; coordinates[0] != 2.3522 || coordinates[1] != 48.8566 {
+		t.Errorf("Expected longitude first, got %v", coordinates)
+	}
+
+	if empty := festivalsToGeoJSON(nil); empty.Features == nil {
+		t.Error("Expected empty features array, got nil")
+	}
+}
@@ -2,9 +2,21 @@ import { ref } from 'vue'
 
 const API_URL = import.meta.env.VITE_API_URL
 
+export type Role = 'admin' | 'organizer' | 'contributor' | 'viewer'
+
+export type Permission =
+  | 'festivals:write'
+  | 'festivals:delete'
+  | 'festivals:manage_any'
+  | 'owners:write'
+  | 'lineups:write'
+  | 'breweries:write'
+  | 'breweries:delete'
+
 export interface User {
   id: string
   email: string
+  roles: Role[]
 }
 
 export interface LoginResponse {
@@ -15,6 +27,25 @@ export interface LoginResponse {
 
 const accessToken = ref<string | null>(localStorage.getItem('accessToken'))
 const currentUser = ref<User | null>(null)
+const permissions = ref<Permission[]>([])
+
+const storeSession = (data: LoginResponse) => {
+  accessToken.value = data.accessToken
+  currentUser.value = data.user
+
+  localStorage.setItem('accessToken', data.accessToken)
+  localStorage.setItem('refreshToken', data.refreshToken)
+  localStorage.setItem('user', JSON.stringify(data.user))
+}
+
+const clearSession = () => {
+  accessToken.value = null
+  currentUser.value = null
+  permissions.value = []
+  localStorage.removeItem('accessToken')
+  localStorage.removeItem('refreshToken')
+  localStorage.removeItem('user')
+}
 
 const storedUser = localStorage.getItem('user')
 if (storedUser) {
@@ -27,7 +58,7 @@ if (storedUser) {
 
 export const useAuth = () => {
   const login = async (email: string, password: string): Promise<void> => {
-    const response = await fetch(`${API_URL}/api/auth/login`, {
+    const response = await fetch(`${API_URL}/api/v1/auth/login`, {
       method: 'POST',
       headers: {
         'Content-Type': 'application/json',
@@ -39,45 +70,72 @@ export const useAuth = () => {
       throw new Error('Invalid credentials')
     }
 
-    const data: LoginResponse = await response.json()
-    accessToken.value = data.accessToken
-    currentUser.value = data.user
+    storeSession(await response.json())
+  }
+
+  const refresh = async (): Promise<boolean> => {
+    const refreshToken = localStorage.getItem('refreshToken')
+    if (!refreshToken) {
+      return false
+    }
+
+    const response = await fetch(`${API_URL}/api/v1/auth/refresh`, {
+      method: 'POST',
+      headers: {
+        'Content-Type': 'application/json',
+      },
+      body: JSON.stringify({ refreshToken }),
+    })
+
+    if (!response.ok) {
+      return false
+    }
 
-    localStorage.setItem('accessToken', data.accessToken)
-    localStorage.setItem('refreshToken', data.refreshToken)
-    localStorage.setItem('user', JSON.stringify(data.user))
+    storeSession(await response.json())
+    return true
   }
 
-  const logout = () => {
-    accessToken.value = null
-    currentUser.value = null
-    localStorage.removeItem('accessToken')
-    localStorage.removeItem('refreshToken')
-    localStorage.removeItem('user')
+  const logout = async (): Promise<void> => {
+    const token = accessToken.value
+    clearSession()
+
+    if (token) {
+      await fetch(`${API_URL}/api/v1/auth/logout`, {
+        method: 'POST',
+        headers: {
+          Authorization: `Bearer ${token}`,
+        },
+      }).catch(() => undefined)
+    }
   }
 
-  const verify = async (): Promise<boolean> => {
+  const verify = async (retry = true): Promise<boolean> => {
     if (!accessToken.value) {
       return false
     }
 
     try {
-      const response = await fetch(`${API_URL}/api/auth/verify`, {
+      const response = await fetch(`${API_URL}/api/v1/auth/verify`, {
         headers: {
           Authorization: `Bearer ${accessToken.value}`,
         },
       })
 
+      if (response.status === 401 && retry && (await refresh())) {
+        return verify(false)
+      }
+
       const data = await response.json()
       if (data.valid && data.user) {
         currentUser.value = data.user
+        permissions.value = data.permissions ?? []
         return true
       }
 
-      logout()
+      clearSession()
       return false
     } catch {
-      logout()
+      clearSession()
       return false
     }
   }
@@ -86,11 +144,17 @@ export const useAuth = () => {
     return !!accessToken.value && !!currentUser.value
   }
 
+  const can = (permission: Permission) => {
+    return permissions.value.includes(permission)
+  }
+
   return {
     login,
     logout,
+    refresh,
     verify,
     isAuthenticated,
+    can,
     user: currentUser,
     token: accessToken,
   }
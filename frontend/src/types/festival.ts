@@ -13,4 +13,5 @@ export interface Festival {
   image?: string
   website?: string
   breweryCount?: number
+  createdBy?: string
 }
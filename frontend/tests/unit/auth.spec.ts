@@ -0,0 +1,172 @@
+import { describe, it, expect, vi, beforeEach } from 'vitest'
+
+const session = {
+  accessToken: 'access-1',
+  refreshToken: 'refresh-1',
+  user: { id: 'user-1', email: 'organisateur@festival-biere.fr', roles: ['organizer'] },
+}
+
+const jsonResponse = (status: number, body: unknown) =>
+  ({
+    ok: status >= 200 && status < 300,
+    status,
+    json: () => Promise.resolve(body),
+  }) as Response
+
+const storeSession = () => {
+  localStorage.setItem('accessToken', session.accessToken)
+  localStorage.setItem('refreshToken', session.refreshToken)
+  localStorage.setItem('user', JSON.stringify(session.user))
+}
+
+const loadAuth = async () => {
+  vi.resetModules()
+  const { useAuth } = await import('@/services/auth')
+  return useAuth()
+}
+
+describe('useAuth', () => {
+  beforeEach(() => {
+    vi.clearAllMocks()
+    localStorage.clear()
+    global.fetch = vi.fn()
+  })
+
+  describe('refresh', () => {
+    it('should return false without a refresh token', async () => {
+      const { refresh } = await loadAuth()
+
+      expect(await refresh()).toBe(false)
+      expect(global.fetch).not.toHaveBeenCalled()
+    })
+
+    it('should store the rotated session', async () => {
+      localStorage.setItem('refreshToken', 'refresh-0')
+      global.fetch = vi.fn(() => Promise.resolve(jsonResponse(200, session)))
+      const { refresh, token, user } = await loadAuth()
+
+      expect(await refresh()).toBe(true)
+
+      expect(global.fetch).toHaveBeenCalledWith(
+        expect.stringContaining('/api/v1/auth/refresh'),
+        expect.objectContaining({ method: 'POST', body: JSON.stringify({ refreshToken: 'refresh-0' }) })
+      )
+      expect(token.value).toBe('access-1')
+      expect(user.value?.id).toBe('user-1')
+      expect(localStorage.getItem('refreshToken')).toBe('refresh-1')
+    })
+
+    it('should return false when the refresh token is rejected', async () => {
+      localStorage.setItem('refreshToken', 'revoked')
+      global.fetch = vi.fn(() => Promise.resolve(jsonResponse(401, {})))
+      const { refresh, token } = await loadAuth()
+
+      expect(await refresh()).toBe(false)
+      expect(token.value).toBeNull()
+    })
+  })
+
+  describe('verify', () => {
+    it('should refresh and retry once after a 401', async () => {
+      localStorage.setItem('accessToken', 'expired')
+      localStorage.setItem('refreshToken', 'refresh-0')
+      global.fetch = vi
+        .fn()
+        .mockResolvedValueOnce(jsonResponse(401, {}))
+        .mockResolvedValueOnce(jsonResponse(200, session))
+        .mockResolvedValueOnce(jsonResponse(200, { valid: true, user: session.user, permissions: [] }))
+      const { verify, token } = await loadAuth()
+
+      expect(await verify()).toBe(true)
+
+      expect(global.fetch).toHaveBeenCalledTimes(3)
+      expect(global.fetch).toHaveBeenLastCalledWith(expect.stringContaining('/api/v1/auth/verify'), {
+        headers: { Authorization: 'Bearer access-1' },
+      })
+      expect(token.value).toBe('access-1')
+    })
+
+    it('should clear the session when the refresh fails', async () => {
+      storeSession()
+      global.fetch = vi.fn(() => Promise.resolve(jsonResponse(401, {})))
+      const { verify, isAuthenticated } = await loadAuth()
+
+      expect(await verify()).toBe(false)
+
+      expect(global.fetch).toHaveBeenCalledTimes(2)
+      expect(isAuthenticated()).toBe(false)
+      expect(localStorage.getItem('refreshToken')).toBeNull()
+    })
+  })
+
+  describe('logout', () => {
+    it('should clear the session and revoke the token', async () => {
+      storeSession()
+      global.fetch = vi.fn(() => Promise.resolve(jsonResponse(204, null)))
+      const { logout, token, user, isAuthenticated } = await loadAuth()
+      expect(isAuthenticated()).toBe(true)
+
+      await logout()
+
+      expect(token.value).toBeNull()
+      expect(user.value).toBeNull()
+      expect(localStorage.getItem('accessToken')).toBeNull()
+      expect(localStorage.getItem('refreshToken')).toBeNull()
+      expect(global.fetch).toHaveBeenCalledWith(expect.stringContaining('/api/v1/auth/logout'), {
+        method: 'POST',
+        headers: { Authorization: 'Bearer access-1' },
+      })
+    })
+
+    it('should clear the session even when the API is unreachable', async () => {
+      storeSession()
+      global.fetch = vi.fn(() => Promise.reject(new Error('Network error')))
+      const { logout, isAuthenticated } = await loadAuth()
+
+      await expect(logout()).resolves.toBeUndefined()
+      expect(isAuthenticated()).toBe(false)
+    })
+
+    it('should not call the API without a session', async () => {
+      const { logout } = await loadAuth()
+
+      await logout()
+
+      expect(global.fetch).not.toHaveBeenCalled()
+    })
+  })
+
+  describe('can', () => {
+    it('should grant only the permissions returned by verify', async () => {
+      storeSession()
+      global.fetch = vi.fn(() =>
+        Promise.resolve(
+          jsonResponse(200, { valid: true, user: session.user, permissions: ['festivals:write', 'lineups:write'] })
+        )
+      )
+      const { verify, can } = await loadAuth()
+      expect(can('festivals:write')).toBe(false)
+
+      await verify()
+
+      expect(can('festivals:write')).toBe(true)
+      expect(can('lineups:write')).toBe(true)
+      expect(can('festivals:delete')).toBe(false)
+      expect(can('festivals:manage_any')).toBe(false)
+      expect(can('owners:write')).toBe(false)
+    })
+
+    it('should drop permissions on logout', async () => {
+      storeSession()
+      global.fetch = vi.fn(() =>
+        Promise.resolve(jsonResponse(200, { valid: true, user: session.user, permissions: ['festivals:write'] }))
+      )
+      const { verify, logout, can } = await loadAuth()
+      await verify()
+
+      await logout()
+
+      expect(can('festivals:write')).toBe(false)
+    })
+  })
+})
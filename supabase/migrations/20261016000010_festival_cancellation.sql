@@ -0,0 +1,2 @@
+alter table festivals
+  add column if not exists cancelled boolean not null default false;
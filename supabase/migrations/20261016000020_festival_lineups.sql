@@ -0,0 +1,11 @@
+create or replace function replace_festival_breweries(p_festival_id bigint, p_brewery_ids bigint[])
+returns void language plpgsql as $$
+begin
+  if not exists (select 1 from festivals where id = p_festival_id) then
+    raise foreign_key_violation using message = 'festival not found';
+  end if;
+  delete from festivals_breweries where festival_id = p_festival_id;
+  insert into festivals_breweries (festival_id, brewery_id)
+  select p_festival_id, unnest(p_brewery_ids);
+end;
+$$;
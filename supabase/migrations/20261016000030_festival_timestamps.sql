@@ -0,0 +1,3 @@
+alter table festivals
+  add column if not exists created_at timestamptz not null default now(),
+  add column if not exists updated_at timestamptz not null default now();
@@ -0,0 +1,20 @@
+alter table festivals
+  add column if not exists created_by uuid references auth.users(id) on delete set null;
+
+create table if not exists festival_owners (
+  festival_id bigint not null references festivals(id) on delete cascade,
+  user_id uuid not null references auth.users(id) on delete cascade,
+  primary key (festival_id, user_id)
+);
+
+create or replace function replace_festival_owners(p_festival_id bigint, p_user_ids uuid[])
+returns void language plpgsql as $$
+begin
+  if not exists (select 1 from festivals where id = p_festival_id) then
+    raise foreign_key_violation using message = 'festival not found';
+  end if;
+  delete from festival_owners where festival_id = p_festival_id;
+  insert into festival_owners (festival_id, user_id)
+  select p_festival_id, unnest(p_user_ids);
+end;
+$$;
@@ -0,0 +1,16 @@
+create table if not exists user_roles (
+  user_id uuid not null references auth.users(id) on delete cascade,
+  role text not null check (role in ('admin', 'organizer', 'contributor', 'viewer')),
+  primary key (user_id, role)
+);
+
+alter table user_roles enable row level security;
+
+revoke all on user_roles from anon, authenticated;
+grant select on user_roles to authenticated;
+grant all on user_roles to service_role;
+
+drop policy if exists "Users read their own roles" on user_roles;
+create policy "Users read their own roles" on user_roles
+  for select to authenticated
+  using (user_id = auth.uid());